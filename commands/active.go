@@ -3,6 +3,7 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"os"
 
 	"time"
 
@@ -31,14 +32,28 @@ func cmdActive(c CommandLine, api libmachine.API) error {
 	}
 
 	timeout := time.Duration(c.Int("timeout")) * time.Second
-	items := getHostListItems(hosts, hostsInError, timeout)
+	items := getHostListItems(hosts, hostsInError, timeout, false)
+
+	current, err := readCurrentMachine(api.GetMachinesDir())
+	if err != nil {
+		return err
+	}
 
 	active, err := activeHost(items)
+	if err == errNoActiveHost && current != "" {
+		fmt.Println(current)
+		return nil
+	}
 
 	if err != nil {
 		return err
 	}
 
+	// The note goes to stderr, the output is meant to be used by scripts
+	if current != "" && current != active.Name {
+		fmt.Fprintf(os.Stderr, "%s is selected by the environment, the current machine selected with use is %s\n", active.Name, current)
+	}
+
 	fmt.Println(active.Name)
 	return nil
 }
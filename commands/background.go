@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/classmarkets/docker-machine/libmachine/log"
+)
+
+// backgroundStartTimeout is how long a command started in the background
+// has to fail before it's considered running
+const backgroundStartTimeout = 2 * time.Second
+
+var errFileLocked = errors.New("The file is locked by another process")
+
+// startInBackground runs docker-machine with args in the background, with
+// its output going to the log file logPath. It fails with the output of the
+// command if it exits within backgroundStartTimeout. env is added to the
+// environment of the command.
+func startInBackground(c CommandLine, logPath string, args []string, env ...string) (*os.Process, error) {
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	defer logFile.Close()
+
+	logStart, err := logFile.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = os.Environ()
+	if storagePath := c.GlobalString("storage-path"); storagePath != "" {
+		cmd.Env = append(cmd.Env, "MACHINE_STORAGE_PATH="+storagePath)
+	}
+	cmd.Env = append(cmd.Env, env...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	detachCommand(cmd)
+
+	log.Debug(*cmd)
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-exited:
+		return nil, fmt.Errorf("%s%s", exitReason(err), logOutput(logPath, logStart))
+	case <-time.After(backgroundStartTimeout):
+	}
+
+	return cmd.Process, nil
+}
+
+func exitReason(err error) string {
+	if err == nil {
+		return "exited"
+	}
+
+	return err.Error()
+}
+
+// logOutput returns what was written to the log file from offset on.
+func logOutput(logPath string, offset int64) string {
+	content, err := ioutil.ReadFile(logPath)
+	if err != nil || int64(len(content)) <= offset {
+		return ""
+	}
+
+	return "\n" + strings.TrimSpace(string(content[offset:]))
+}
@@ -0,0 +1,41 @@
+// +build !windows
+
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// detachCommand runs the command in its own session, so that it keeps
+// running after the terminal is closed.
+func detachCommand(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setsid: true,
+	}
+}
+
+// lockFile opens the file and locks it for as long as it's open, or fails
+// with errFileLocked if another process holds the lock.
+func lockFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, errFileLocked
+		}
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// stopProcess interrupts the process, letting it clean up like on Ctrl-C.
+func stopProcess(process *os.Process) error {
+	return process.Signal(os.Interrupt)
+}
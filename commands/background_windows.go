@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// detachCommand runs the command in its own process group, so that it
+// doesn't receive the Ctrl-C of the console.
+func detachCommand(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
+	}
+}
+
+// errorSharingViolation is returned when opening a file another process
+// opened without sharing it
+const errorSharingViolation syscall.Errno = 32
+
+// lockFile opens the file without sharing it, so that it's locked for as
+// long as it's open, or fails with errFileLocked if another process has it
+// open.
+func lockFile(path string) (*os.File, error) {
+	pathp, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+
+	handle, err := syscall.CreateFile(pathp, syscall.GENERIC_READ|syscall.GENERIC_WRITE, 0, nil, syscall.OPEN_ALWAYS, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err == errorSharingViolation {
+		return nil, errFileLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return os.NewFile(uintptr(handle), path), nil
+}
+
+// stopProcess kills the process, Windows can't interrupt it.
+func stopProcess(process *os.Process) error {
+	return process.Kill()
+}
@@ -10,12 +10,14 @@ import (
 	"github.com/classmarkets/docker-machine/commands/mcndirs"
 	"github.com/classmarkets/docker-machine/libmachine"
 	"github.com/classmarkets/docker-machine/libmachine/crashreport"
+	"github.com/classmarkets/docker-machine/libmachine/filter"
 	"github.com/classmarkets/docker-machine/libmachine/host"
 	"github.com/classmarkets/docker-machine/libmachine/log"
 	"github.com/classmarkets/docker-machine/libmachine/mcnerror"
 	"github.com/classmarkets/docker-machine/libmachine/mcnutils"
 	"github.com/classmarkets/docker-machine/libmachine/persist"
 	"github.com/classmarkets/docker-machine/libmachine/ssh"
+	"github.com/classmarkets/docker-machine/libmachine/state"
 )
 
 const (
@@ -28,6 +30,10 @@ var (
 	ErrNoMachineSpecified = errors.New("Error: Expected to get one or more machine names as arguments")
 	ErrExpectedOneMachine = errors.New("Error: Expected one machine name as an argument")
 	ErrTooManyArguments   = errors.New("Error: Too many arguments given")
+	errNoSnapshotName     = errors.New("Error: Expected a machine name and a snapshot name as arguments")
+	errAllWithNames       = errors.New("Error: Machine names can't be given with --all")
+	errNoMachineMatched   = errors.New("Error: No machine matches the filters")
+	errSelectionDeclined  = errors.New("Error: The selected machines weren't confirmed")
 
 	osExit = func(code int) { os.Exit(code) }
 )
@@ -76,9 +82,34 @@ func (c *contextCommandLine) Application() *cli.App {
 }
 
 // targetHost returns a specific host name if one is indicated by the first CLI
-// arg, or the default host name if no host is specified.
+// arg, or else the current or default host name.
 func targetHost(c CommandLine, api libmachine.API) (string, error) {
-	if len(c.Args()) == 0 {
+	return targetHostFromArgs(c.Args(), api)
+}
+
+// targetHostFromArgs returns the machine named by the first argument, or
+// the machine selected with use, or the default machine if there are no
+// arguments.
+func targetHostFromArgs(args []string, api libmachine.API) (string, error) {
+	if len(args) == 0 {
+		current, err := readCurrentMachine(api.GetMachinesDir())
+		if err != nil {
+			return "", err
+		}
+
+		if current != "" {
+			currentExists, err := api.Exists(current)
+			if err != nil {
+				return "", fmt.Errorf("Error checking if host %q exists: %s", current, err)
+			}
+
+			if currentExists {
+				return current, nil
+			}
+
+			log.Warnf("The current machine %q doesn't exist anymore", current)
+		}
+
 		defaultExists, err := api.Exists(defaultMachineName)
 		if err != nil {
 			return "", fmt.Errorf("Error checking if host %q exists: %s", defaultMachineName, err)
@@ -91,53 +122,121 @@ func targetHost(c CommandLine, api libmachine.API) (string, error) {
 		return "", ErrNoDefault
 	}
 
-	return c.Args()[0], nil
+	return args[0], nil
 }
 
 func runAction(actionName string, c CommandLine, api libmachine.API) error {
+	hosts, err := selectHosts(c, api)
+	if err == errSelectionDeclined {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return runActionOnHosts(actionName, hosts, api)
+}
+
+func runActionOnHosts(actionName string, hosts []*host.Host, api libmachine.API) error {
+	var errs []error
+	if reverse, ordered := orderedActions[actionName]; ordered {
+		errs = runOrderedAction(actionName, hosts, reverse)
+	} else {
+		errs = runActionForeachMachine(actionName, hosts)
+	}
+	if len(errs) > 0 {
+		return consolidateErrs(errs)
+	}
+
+	for _, h := range hosts {
+		if err := api.Save(h); err != nil {
+			return fmt.Errorf("Error saving host to store: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// selectHosts loads the machines a command acts on: those given as
+// arguments, those matching --filter, all of them with --all, or the
+// target machine. The user confirms the machines selected with --filter or
+// --all unless -y or --force is set.
+func selectHosts(c CommandLine, api libmachine.API) ([]*host.Host, error) {
 	var (
 		hostsToLoad []string
 	)
 
+	filters, err := filter.Parse(c.StringSlice("filter"))
+	if err != nil {
+		return nil, err
+	}
+
+	selected := c.Bool("all") || !filters.IsEmpty()
+	if c.Bool("all") && len(c.Args()) > 0 {
+		return nil, errAllWithNames
+	}
+
 	// If user did not specify a machine name explicitly, use the 'default'
 	// machine if it exists.  This allows short form commands such as
 	// 'docker-machine stop' for convenience.
-	if len(c.Args()) == 0 {
+	if len(c.Args()) > 0 {
+		hostsToLoad = c.Args()
+	} else if selected {
+		hostsToLoad, err = api.List()
+		if err != nil {
+			return nil, err
+		}
+	} else {
 		target, err := targetHost(c, api)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		hostsToLoad = []string{target}
-	} else {
-		hostsToLoad = c.Args()
 	}
 
 	hosts, hostsInError := persist.LoadHosts(api, hostsToLoad)
 
 	if len(hostsInError) > 0 {
-		errs := []error{}
-		for _, err := range hostsInError {
-			errs = append(errs, err)
+		// The machines which can't be loaded can't be matched either
+		if selected && len(c.Args()) == 0 {
+			for name, err := range hostsInError {
+				log.Warnf("Skipping %s: %s", name, err)
+			}
+		} else {
+			errs := []error{}
+			for _, err := range hostsInError {
+				errs = append(errs, err)
+			}
+			return nil, consolidateErrs(errs)
 		}
-		return consolidateErrs(errs)
 	}
 
+	hosts = filter.Hosts(hosts, filters)
+
 	if len(hosts) == 0 {
-		return ErrHostLoad
+		if selected {
+			return nil, errNoMachineMatched
+		}
+		return nil, ErrHostLoad
 	}
 
-	if errs := runActionForeachMachine(actionName, hosts); len(errs) > 0 {
-		return consolidateErrs(errs)
-	}
+	if selected && len(c.Args()) == 0 {
+		names := []string{}
+		for _, h := range hosts {
+			names = append(names, h.Name)
+		}
+		log.Infof("Selected %d machines: %s", len(names), strings.Join(names, ", "))
 
-	for _, h := range hosts {
-		if err := api.Save(h); err != nil {
-			return fmt.Errorf("Error saving host to store: %s", err)
+		if !c.Bool("y") && !c.Bool("force") {
+			ok, err := confirmInput("Continue?")
+			if err != nil || !ok {
+				return nil, errSelectionDeclined
+			}
 		}
 	}
 
-	return nil
+	return hosts, nil
 }
 
 func runCommand(command func(commandLine CommandLine, api libmachine.API) error) func(context *cli.Context) {
@@ -192,6 +291,92 @@ func confirmInput(msg string) (bool, error) {
 	return confirmed, nil
 }
 
+// selectionFlags select the machines of the commands acting on several of
+// them, see selectHosts. withYes adds -y to skip the confirmation.
+func selectionFlags(withYes bool) []cli.Flag {
+	flags := []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "filter",
+			Usage: "Act on the machines matching the conditions provided, like ls, key!=value excludes the machines matching the condition",
+			Value: &cli.StringSlice{},
+		},
+		cli.BoolFlag{
+			Name:  "all",
+			Usage: "Act on all the machines",
+		},
+	}
+
+	if withYes {
+		flags = append(flags, cli.BoolFlag{
+			Name:  "y",
+			Usage: "Don't ask for confirmation of the machines selected with --filter or --all",
+		})
+	}
+
+	return flags
+}
+
+// machineArgs is how a command takes machine names as arguments.
+type machineArgs int
+
+const (
+	noMachineArgs machineArgs = iota
+	oneMachineArg
+	multipleMachineArgs
+	// pathArgs are [machine:]path arguments, completed as files
+	pathArgs
+)
+
+// commandArgs describes the arguments of a command for the completion.
+// states are those of the machines suggested to it, all the machines are
+// suggested when it's empty.
+type commandArgs struct {
+	machines machineArgs
+	states   []state.State
+}
+
+// commandsArgs are the arguments of the commands of Commands, by their path
+// such as "snapshot create".
+var commandsArgs = map[string]commandArgs{
+	"active":           {machines: noMachineArgs},
+	"completion":       {machines: noMachineArgs},
+	"config":           {machines: oneMachineArg, states: []state.State{state.Running}},
+	"context sync":     {machines: noMachineArgs},
+	"create":           {machines: noMachineArgs},
+	"env":              {machines: oneMachineArg, states: []state.State{state.Running}},
+	"events":           {machines: noMachineArgs},
+	"inspect":          {machines: oneMachineArg},
+	"ip":               {machines: multipleMachineArgs},
+	"kill":             {machines: multipleMachineArgs, states: []state.State{state.Running, state.Starting, state.Paused}},
+	"ls":               {machines: noMachineArgs},
+	"mount":            {machines: pathArgs},
+	"pause":            {machines: multipleMachineArgs, states: []state.State{state.Running}},
+	"port-forward":     {machines: oneMachineArg, states: []state.State{state.Running}},
+	"provision":        {machines: multipleMachineArgs},
+	"regenerate-certs": {machines: multipleMachineArgs},
+	"resize":           {machines: oneMachineArg},
+	"restart":          {machines: multipleMachineArgs},
+	"resume":           {machines: multipleMachineArgs, states: []state.State{state.Paused, state.Saved}},
+	"rm":               {machines: multipleMachineArgs},
+	"scp":              {machines: pathArgs},
+	"snapshot create":  {machines: oneMachineArg},
+	"snapshot ls":      {machines: oneMachineArg},
+	"snapshot restore": {machines: oneMachineArg},
+	"snapshot rm":      {machines: oneMachineArg},
+	"ssh":              {machines: oneMachineArg, states: []state.State{state.Running}},
+	"ssh-config":       {machines: multipleMachineArgs},
+	"ssh-keyscan":      {machines: oneMachineArg},
+	"start":            {machines: multipleMachineArgs, states: []state.State{state.Stopped, state.Saved, state.Error}},
+	"status":           {machines: oneMachineArg},
+	"stop":             {machines: multipleMachineArgs, states: []state.State{state.Running, state.Paused}},
+	"suspend":          {machines: multipleMachineArgs, states: []state.State{state.Running}},
+	"sync":             {machines: pathArgs},
+	"upgrade":          {machines: multipleMachineArgs},
+	"url":              {machines: oneMachineArg, states: []state.State{state.Running}},
+	"use":              {machines: oneMachineArg},
+	"version":          {machines: oneMachineArg},
+}
+
 var Commands = []cli.Command{
 	{
 		Name:   "active",
@@ -205,6 +390,13 @@ var Commands = []cli.Command{
 			},
 		},
 	},
+	{
+		Name:            "completion",
+		Usage:           "Print the completion script of a shell",
+		Description:     "Argument is a shell: bash, zsh, fish or powershell. e.g. source <(docker-machine completion bash)",
+		Action:          runCommand(cmdCompletion),
+		SkipFlagParsing: true,
+	},
 	{
 		Name:        "config",
 		Usage:       "Print the connection config for machine",
@@ -215,6 +407,22 @@ var Commands = []cli.Command{
 				Name:  "swarm",
 				Usage: "Display the Swarm config instead of the Docker daemon",
 			},
+			cli.StringFlag{
+				Name:  "transport",
+				Usage: "Reach the engine over tcp (TLS on port 2376) or ssh, default to the transport the machine was created with",
+			},
+		},
+	},
+	{
+		Name:        "context",
+		Usage:       "Manage the Docker CLI contexts of the machines",
+		Subcommands: []cli.Command{
+			{
+				Name:        "sync",
+				Usage:       "Write a Docker CLI context for every machine",
+				Description: "The contexts of the machines removed from the store are removed, switch to a machine with docker context use.",
+				Action:      runCommand(cmdContextSync),
+			},
 		},
 	},
 	{
@@ -237,7 +445,7 @@ var Commands = []cli.Command{
 			},
 			cli.StringFlag{
 				Name:  "shell",
-				Usage: "Force environment to be configured for a specified shell: [fish, cmd, powershell, tcsh, emacs], default is auto-detect",
+				Usage: "Force environment to be configured for a specified shell: [fish, cmd, powershell, tcsh, emacs, nushell, xonsh, elvish], or printed as [dotenv, json], default is auto-detect",
 			},
 			cli.BoolFlag{
 				Name:  "unset, u",
@@ -247,6 +455,37 @@ var Commands = []cli.Command{
 				Name:  "no-proxy",
 				Usage: "Add machine IP to NO_PROXY environment variable",
 			},
+			cli.StringFlag{
+				Name:  "transport",
+				Usage: "Reach the engine over tcp (TLS on port 2376) or ssh, default to the transport the machine was created with",
+			},
+		},
+	},
+	{
+		Name:        "events",
+		Usage:       "Stream the state changes of the machines",
+		Description: "Polls the machines and prints a line for each change, such as a machine stopping or its Docker daemon becoming unreachable.",
+		Action:      runCommand(cmdEvents),
+		Flags: []cli.Flag{
+			cli.StringSliceFlag{
+				Name:  "filter",
+				Usage: "Only print the changes of the machines matching the conditions provided before or after the change",
+				Value: &cli.StringSlice{},
+			},
+			cli.IntFlag{
+				Name:  "interval",
+				Usage: fmt.Sprintf("Seconds between the polls, default to %ds", eventsDefaultInterval),
+				Value: eventsDefaultInterval,
+			},
+			cli.IntFlag{
+				Name:  "timeout, t",
+				Usage: fmt.Sprintf("Timeout in seconds of the state queries, default to %ds", lsDefaultTimeout),
+				Value: lsDefaultTimeout,
+			},
+			cli.StringFlag{
+				Name:  "format, f",
+				Usage: "Format the events using a Go template, such as '{{ json . }}' for a JSON object per line",
+			},
 		},
 	},
 	{
@@ -267,12 +506,14 @@ var Commands = []cli.Command{
 		Usage:       "Get the IP address of a machine",
 		Description: "Argument(s) are one or more machine names.",
 		Action:      runCommand(cmdIP),
+		Flags:       selectionFlags(true),
 	},
 	{
 		Name:        "kill",
 		Usage:       "Kill a machine",
 		Description: "Argument(s) are one or more machine names.",
 		Action:      runCommand(cmdKill),
+		Flags:       selectionFlags(true),
 	},
 	{
 		Name:   "ls",
@@ -295,21 +536,43 @@ var Commands = []cli.Command{
 			},
 			cli.StringFlag{
 				Name:  "format, f",
-				Usage: "Pretty-print machines using a Go template",
+				Usage: "Pretty-print machines using a Go template, with the functions json, prettyjson, upper, lower, humanDuration and humanSize",
+			},
+			cli.StringFlag{
+				Name:  "sort, s",
+				Usage: "Sort the machines by name, active, driver, state, url, ip, created, cpus, memory, disk, os, cert-expiry, docker or response, -key reverses the order",
+				Value: "name",
+			},
+			cli.BoolFlag{
+				Name:  "watch, w",
+				Usage: "Refresh the list in place until interrupted",
+			},
+			cli.IntFlag{
+				Name:  "interval",
+				Usage: fmt.Sprintf("Seconds between the refreshes of --watch, default to %ds", lsDefaultInterval),
+				Value: lsDefaultInterval,
 			},
 		},
 	},
+	{
+		Name:        "pause",
+		Usage:       "Pause a machine, keeping it in memory",
+		Description: "Argument(s) are one or more machine names.",
+		Action:      runCommand(cmdPause),
+		Flags:       selectionFlags(true),
+	},
 	{
 		Name:   "provision",
 		Usage:  "Re-provision existing machines",
 		Action: runCommand(cmdProvision),
+		Flags:  selectionFlags(true),
 	},
 	{
 		Name:        "regenerate-certs",
 		Usage:       "Regenerate TLS Certificates for a machine",
 		Description: "Argument(s) are one or more machine names.",
 		Action:      runCommand(cmdRegenerateCerts),
-		Flags: []cli.Flag{
+		Flags: append([]cli.Flag{
 			cli.BoolFlag{
 				Name:  "force, f",
 				Usage: "Force rebuild and do not prompt",
@@ -318,16 +581,48 @@ var Commands = []cli.Command{
 				Name:  "client-certs",
 				Usage: "Also regenerate client certificates and CA.",
 			},
+		}, selectionFlags(false)...),
+	},
+	{
+		Name:        "resize",
+		Usage:       "Change the CPU, memory or disk size of a machine",
+		Description: "Argument is a machine name. A running machine is stopped and started again.",
+		Action:      runCommand(cmdResize),
+		Flags: []cli.Flag{
+			cli.IntFlag{
+				Name:  "cpus",
+				Usage: "Number of CPUs",
+			},
+			cli.IntFlag{
+				Name:  "memory",
+				Usage: "Size of memory in MB",
+			},
+			cli.IntFlag{
+				Name:  "disk-size",
+				Usage: "Size of disk in MB, disks can only grow",
+			},
+			cli.StringFlag{
+				Name:  "instance-type",
+				Usage: "Instance type for cloud drivers",
+			},
 		},
 	},
 	{
 		Name:        "restart",
 		Usage:       "Restart a machine",
-		Description: "Argument(s) are one or more machine names.",
+		Description: "Argument(s) are one or more machine names. The machines are restarted in the order of their dependencies.",
 		Action:      runCommand(cmdRestart),
+		Flags:       selectionFlags(true),
 	},
 	{
-		Flags: []cli.Flag{
+		Name:        "resume",
+		Usage:       "Resume a paused or suspended machine",
+		Description: "Argument(s) are one or more machine names.",
+		Action:      runCommand(cmdResume),
+		Flags:       selectionFlags(true),
+	},
+	{
+		Flags: append([]cli.Flag{
 			cli.BoolFlag{
 				Name:  "force, f",
 				Usage: "Remove local configuration even if machine cannot be removed, also implies an automatic yes (`-y`)",
@@ -336,7 +631,7 @@ var Commands = []cli.Command{
 				Name:  "y",
 				Usage: "Assumes automatic yes to proceed with remove, without prompting further user confirmation",
 			},
-		},
+		}, selectionFlags(false)...),
 		Name:        "rm",
 		Usage:       "Remove a machine",
 		Description: "Argument(s) are one or more machine names.",
@@ -345,14 +640,46 @@ var Commands = []cli.Command{
 	{
 		Name:            "ssh",
 		Usage:           "Log into or run a command on a machine with SSH.",
-		Description:     "Arguments are [machine-name] [command]",
+		Description:     "Arguments are [-A] [machine-name] [command]. -A forwards the local ssh-agent to the machine.",
 		Action:          runCommand(cmdSSH),
 		SkipFlagParsing: true,
 	},
+	{
+		Name:        "ssh-config",
+		Usage:       "Print the ssh_config of machines, for ssh, rsync and other SSH clients",
+		Description: "Arguments are machine names, all the machines by default. A machine is reached with ssh docker-machine-<name>.",
+		Action:      runCommand(cmdSSHConfig),
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "write",
+				Usage: "Write the configuration into a file, e.g. ~/.ssh/config.d/docker-machine, replacing the one written before",
+			},
+			cli.BoolFlag{
+				Name:  "include-stopped",
+				Usage: "Include the machines which aren't running",
+			},
+			cli.BoolFlag{
+				Name:  "include",
+				Usage: "Include the configuration of the machines in ~/.ssh/config, once, so ssh docker-machine-<name> and the ssh:// URLs of the ssh transport use their key and pinned host key",
+			},
+		},
+	},
+	{
+		Name:        "ssh-keyscan",
+		Usage:       "Print the host key of a machine and check it against the pinned key",
+		Description: "Argument is a machine name.",
+		Action:      runCommand(cmdSSHKeyscan),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "reset",
+				Usage: "Replace the pinned host keys with the current key of the machine",
+			},
+		},
+	},
 	{
 		Name:        "scp",
 		Usage:       "Copy files between machines",
-		Description: "Arguments are [[user@]machine:][path] [[user@]machine:][path].",
+		Description: "Arguments are [[user@]machine:][path] [[user@]machine:][path]. Files are copied over SFTP, falling back to the scp binary if a machine doesn't support it.",
 		Action:      runCommand(cmdScp),
 		Flags: []cli.Flag{
 			cli.BoolFlag{
@@ -367,25 +694,139 @@ var Commands = []cli.Command{
 				Name:  "quiet, q",
 				Usage: "Disables the progress meter as well as warning and diagnostic messages from ssh",
 			},
+			cli.BoolFlag{
+				Name:  "compress, C",
+				Usage: "Compress the files while they are transferred, on the machines when copying between two of them",
+			},
+			cli.BoolFlag{
+				Name:  "resume",
+				Usage: "Skip the files which were already copied and continue the partially copied ones, also after losing the connection",
+			},
 		},
 	},
 	{
 		Name:        "mount",
 		Usage:       "Mount or unmount a directory from a machine with SSHFS.",
-		Description: "Arguments are [machine:][path] [mountpoint]",
+		Description: "Arguments are [machine:][path] [mountpoint], or [path] [machine:][mountpoint] with --reverse. A reverse mount serves the local directory to sshfs on the machine until it's unmounted with --reverse -u [machine:]mountpoint, the mount point defaults to the local path.",
 		Action:      runCommand(cmdMount),
 		Flags: []cli.Flag{
 			cli.BoolFlag{
 				Name:  "unmount, u",
 				Usage: "Unmount instead of mount",
 			},
+			cli.BoolFlag{
+				Name:  "reverse, R",
+				Usage: "Mount a local directory inside the machine",
+			},
+			cli.BoolFlag{
+				Name:  "foreground",
+				Usage: "Serve a reverse mount in the foreground instead of in the background",
+			},
+			cli.BoolFlag{
+				Name:  "read-only",
+				Usage: "Don't let the machine change the files of a reverse mount",
+			},
+		},
+	},
+	{
+		Name:        "port-forward",
+		Usage:       "Forward ports to or from a machine over SSH",
+		Description: "Arguments are [machine-name] [[bind_address:]port:[host:]hostport...]. Like ssh -L and -R, unix socket paths can be given in place of ports, e.g. 2375:/var/run/docker.sock.",
+		Action:      runCommand(cmdPortForward),
+		Flags: []cli.Flag{
+			cli.StringSliceFlag{
+				Name:  "local, L",
+				Usage: "Forward a local port to the machine",
+				Value: &cli.StringSlice{},
+			},
+			cli.StringSliceFlag{
+				Name:  "remote, R",
+				Usage: "Forward a port of the machine to the local host",
+				Value: &cli.StringSlice{},
+			},
+			cli.BoolFlag{
+				Name:  "background",
+				Usage: "Forward in the background, until stopped with --stop",
+			},
+			cli.BoolFlag{
+				Name:  "stop",
+				Usage: "Stop forwarding in the background",
+			},
+		},
+	},
+	{
+		Name:        "sync",
+		Usage:       "Sync a local directory into a machine",
+		Description: "Arguments are [path] [[user@]machine:][path]. Only the files whose size or modification time changed are copied over SFTP, the patterns of a .dockerignore file in the directory are excluded.",
+		Action:      runCommand(cmdSync),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "watch, w",
+				Usage: "Keep watching the directory and sync its changes",
+			},
+			cli.BoolFlag{
+				Name:  "delete",
+				Usage: "Delete the files which aren't in the local directory from the machine, refused for its home or root directory",
+			},
+			cli.StringSliceFlag{
+				Name:  "exclude, e",
+				Usage: "Exclude the paths matching a pattern, like in .dockerignore",
+				Value: &cli.StringSlice{},
+			},
+			cli.IntFlag{
+				Name:  "delay",
+				Usage: fmt.Sprintf("Milliseconds to batch changes for when watching, default to %dms", syncDefaultDelay),
+				Value: syncDefaultDelay,
+			},
+			cli.BoolFlag{
+				Name:  "quiet, q",
+				Usage: "Disables the progress meter",
+			},
+		},
+	},
+	{
+		Name:        "snapshot",
+		Usage:       "Manage snapshots of a machine",
+		Description: "Arguments are [machine-name] [snapshot-name]",
+		Subcommands: []cli.Command{
+			{
+				Name:        "create",
+				Usage:       "Take a snapshot of a machine",
+				Description: "Arguments are [machine-name] [snapshot-name]. The snapshot name defaults to the machine name and the current time.",
+				Action:      runCommand(cmdSnapshotCreate),
+			},
+			{
+				Name:        "ls",
+				Usage:       "List the snapshots of a machine",
+				Description: "Argument is a machine name.",
+				Action:      runCommand(cmdSnapshotLs),
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "quiet, q",
+						Usage: "Only display snapshot names",
+					},
+				},
+			},
+			{
+				Name:        "restore",
+				Usage:       "Restore a machine to a snapshot",
+				Description: "Arguments are machine-name snapshot-name. A running machine is stopped before and started after the restore.",
+				Action:      runCommand(cmdSnapshotRestore),
+			},
+			{
+				Name:        "rm",
+				Usage:       "Remove a snapshot of a machine",
+				Description: "Arguments are machine-name snapshot-name.",
+				Action:      runCommand(cmdSnapshotRm),
+			},
 		},
 	},
 	{
 		Name:        "start",
 		Usage:       "Start a machine",
-		Description: "Argument(s) are one or more machine names.",
+		Description: "Argument(s) are one or more machine names. The machines are started in the order of their dependencies.",
 		Action:      runCommand(cmdStart),
+		Flags:       selectionFlags(true),
 	},
 	{
 		Name:        "status",
@@ -396,20 +837,51 @@ var Commands = []cli.Command{
 	{
 		Name:        "stop",
 		Usage:       "Stop a machine",
-		Description: "Argument(s) are one or more machine names.",
+		Description: "Argument(s) are one or more machine names. The machines are stopped in the order of their dependencies.",
 		Action:      runCommand(cmdStop),
+		Flags:       selectionFlags(true),
+	},
+	{
+		Name:        "suspend",
+		Usage:       "Save the state of a machine to disk and stop it",
+		Description: "Argument(s) are one or more machine names.",
+		Action:      runCommand(cmdSuspend),
+		Flags:       selectionFlags(true),
 	},
 	{
 		Name:        "upgrade",
 		Usage:       "Upgrade a machine to the latest version of Docker",
 		Description: "Argument(s) are one or more machine names.",
 		Action:      runCommand(cmdUpgrade),
+		Flags:       selectionFlags(true),
 	},
 	{
 		Name:        "url",
 		Usage:       "Get the URL of a machine",
 		Description: "Argument is a machine name.",
 		Action:      runCommand(cmdURL),
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "transport",
+				Usage: "Reach the engine over tcp (TLS on port 2376) or ssh, default to the transport the machine was created with",
+			},
+		},
+	},
+	{
+		Name:        "use",
+		Usage:       "Select the machine the commands apply to when no machine name is given",
+		Description: "Argument is a machine name, the current machine is printed without it.",
+		Action:      runCommand(cmdUse),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "unset, u",
+				Usage: "Forget the current machine, the commands apply to the default machine again",
+			},
+			cli.BoolFlag{
+				Name:  "docker-context",
+				Usage: "Also make the Docker CLI use the Docker context of the machine",
+			},
+		},
 	},
 	{
 		Name:   "version",
@@ -442,6 +914,9 @@ func machineCommand(actionName string, host *host.Host, errorChan chan<- error)
 		"stop":             host.Stop,
 		"restart":          host.Restart,
 		"kill":             host.Kill,
+		"pause":            host.Pause,
+		"resume":           host.Resume,
+		"suspend":          host.Suspend,
 		"upgrade":          host.Upgrade,
 		"ip":               printIP(host),
 		"provision":        host.Provision,
@@ -241,3 +241,27 @@ func checkErrorCodeForCommand(command func(commandLine CommandLine, api libmachi
 
 	return setExitCode
 }
+
+func TestCommandsArgs(t *testing.T) {
+	paths := map[string]bool{}
+
+	var walk func(prefix string, commands []cli.Command)
+	walk = func(prefix string, commands []cli.Command) {
+		for _, command := range commands {
+			path := prefix + command.Name
+			if len(command.Subcommands) > 0 {
+				walk(path+" ", command.Subcommands)
+				continue
+			}
+
+			paths[path] = true
+			_, ok := commandsArgs[path]
+			assert.True(t, ok, "%q has no commandsArgs entry", path)
+		}
+	}
+	walk("", Commands)
+
+	for path := range commandsArgs {
+		assert.True(t, paths[path], "commandsArgs has an entry for the unknown command %q", path)
+	}
+}
@@ -0,0 +1,401 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/classmarkets/docker-machine/libmachine"
+	"github.com/classmarkets/docker-machine/libmachine/drivers"
+	"github.com/classmarkets/docker-machine/libmachine/drivers/plugin/localbinary"
+	"github.com/classmarkets/docker-machine/libmachine/engine"
+	"github.com/classmarkets/docker-machine/libmachine/log"
+	"github.com/classmarkets/docker-machine/libmachine/state"
+	"github.com/codegangsta/cli"
+)
+
+// completeArg is the argument the completion scripts run the CLI with to
+// get the candidates for the words of the command line.
+const completeArg = "__complete"
+
+var errCompletionShell = errors.New("Expected a shell to generate the completion for: bash, zsh, fish or powershell")
+
+var (
+	// completionFlagValues are the values suggested to flags.
+	completionFlagValues = map[string]func() []string{
+		"driver":           completionDriverNames,
+		"engine-transport": func() []string { return []string{engine.TransportTCP, engine.TransportSSH} },
+		"transport":        func() []string { return []string{engine.TransportTCP, engine.TransportSSH} },
+		"shell":            completionShellNames,
+	}
+)
+
+// completionCandidate is a word suggested to complete the command line.
+type completionCandidate struct {
+	Value       string
+	Description string
+}
+
+func cmdCompletion(c CommandLine, api libmachine.API) error {
+	args := c.Args()
+	if len(args) == 0 {
+		c.ShowHelp()
+		return errCompletionShell
+	}
+
+	if args[0] == completeArg {
+		if len(args) == 1 {
+			args = append(args, "")
+		}
+
+		for _, candidate := range completeWords(c.Application(), api, args[1:]) {
+			fmt.Printf("%s\t%s\n", candidate.Value, candidate.Description)
+		}
+
+		return nil
+	}
+
+	if len(args) > 1 {
+		return ErrTooManyArguments
+	}
+
+	script, ok := completionScripts[args[0]]
+	if !ok {
+		return errCompletionShell
+	}
+
+	fmt.Print(strings.Replace(script, "{{.Name}}", c.Application().Name, -1))
+
+	return nil
+}
+
+// completionLevel is a command, or the application itself, whose flags and
+// subcommands are completed.
+type completionLevel struct {
+	path     string
+	flags    []cli.Flag
+	commands []cli.Command
+}
+
+// completeWords returns the candidates for the last of the words, which
+// follow the name of the CLI on the command line.
+func completeWords(app *cli.App, api libmachine.API, words []string) []completionCandidate {
+	current := words[len(words)-1]
+
+	level := completionLevel{
+		flags:    app.Flags,
+		commands: app.Commands,
+	}
+	args := []string{}
+	valueFlag := ""
+	driverName := ""
+
+	for _, word := range words[:len(words)-1] {
+		if valueFlag != "" {
+			if valueFlag == "driver" {
+				driverName = word
+			}
+			valueFlag = ""
+			continue
+		}
+
+		if strings.HasPrefix(word, "-") && word != "-" {
+			name := strings.TrimLeft(word, "-")
+			if i := strings.Index(name, "="); i >= 0 {
+				if completionFlagName(level.flags, name[:i]) == "driver" {
+					driverName = name[i+1:]
+				}
+			} else if completionFlagTakesValue(level.flags, name) {
+				valueFlag = completionFlagName(level.flags, name)
+			}
+			continue
+		}
+
+		if len(level.commands) > 0 && len(args) == 0 {
+			command := completionCommand(level.commands, word)
+			if command == nil {
+				return nil
+			}
+
+			level = completionLevel{
+				path:     strings.TrimSpace(level.path + " " + command.Name),
+				flags:    command.Flags,
+				commands: command.Subcommands,
+			}
+			continue
+		}
+
+		args = append(args, word)
+	}
+
+	var candidates []completionCandidate
+
+	switch {
+	case valueFlag != "":
+		if values, ok := completionFlagValues[valueFlag]; ok {
+			for _, value := range values() {
+				candidates = append(candidates, completionCandidate{Value: value})
+			}
+		}
+	case strings.HasPrefix(current, "-"):
+		flags := append([]cli.Flag{}, level.flags...)
+		if level.path == "create" {
+			flags = append(flags, completionDriverFlags(api, driverName)...)
+		}
+		candidates = completionFlags(flags)
+	case len(level.commands) > 0:
+		for _, command := range level.commands {
+			candidates = append(candidates, completionCandidate{Value: command.Name, Description: command.Usage})
+		}
+	case level.path != "":
+		candidates = completionMachines(api, level.path, args)
+	}
+
+	return filterCompletionCandidates(candidates, current)
+}
+
+func filterCompletionCandidates(candidates []completionCandidate, prefix string) []completionCandidate {
+	filtered := []completionCandidate{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate.Value, prefix) {
+			filtered = append(filtered, candidate)
+		}
+	}
+
+	return filtered
+}
+
+func completionCommand(commands []cli.Command, name string) *cli.Command {
+	for i := range commands {
+		if commands[i].HasName(name) {
+			return &commands[i]
+		}
+	}
+
+	return nil
+}
+
+// completionFlagNames returns the names of a flag, e.g. driver and d for
+// "driver, d", the usage and whether it takes a value.
+func completionFlagNames(flag cli.Flag) ([]string, string, bool) {
+	var (
+		name       string
+		usage      string
+		takesValue = true
+	)
+
+	switch f := flag.(type) {
+	case cli.BoolFlag:
+		name, usage, takesValue = f.Name, f.Usage, false
+	case cli.BoolTFlag:
+		name, usage, takesValue = f.Name, f.Usage, false
+	case cli.StringFlag:
+		name, usage = f.Name, f.Usage
+	case cli.StringSliceFlag:
+		name, usage = f.Name, f.Usage
+	case cli.IntFlag:
+		name, usage = f.Name, f.Usage
+	case cli.IntSliceFlag:
+		name, usage = f.Name, f.Usage
+	case cli.Float64Flag:
+		name, usage = f.Name, f.Usage
+	case cli.DurationFlag:
+		name, usage = f.Name, f.Usage
+	case cli.GenericFlag:
+		name, usage = f.Name, f.Usage
+	default:
+		return nil, "", false
+	}
+
+	names := []string{}
+	for _, part := range strings.Split(name, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			names = append(names, part)
+		}
+	}
+
+	return names, usage, takesValue
+}
+
+// completionFlagName returns the first name of the flag named name, which
+// the values are looked up with.
+func completionFlagName(flags []cli.Flag, name string) string {
+	for _, flag := range flags {
+		names, _, _ := completionFlagNames(flag)
+		for _, n := range names {
+			if n == name {
+				return names[0]
+			}
+		}
+	}
+
+	return ""
+}
+
+func completionFlagTakesValue(flags []cli.Flag, name string) bool {
+	for _, flag := range flags {
+		names, _, takesValue := completionFlagNames(flag)
+		for _, n := range names {
+			if n == name {
+				return takesValue
+			}
+		}
+	}
+
+	// The flags of the drivers aren't loaded here, they're taken for
+	// boolean flags
+	return false
+}
+
+func completionFlags(flags []cli.Flag) []completionCandidate {
+	candidates := []completionCandidate{}
+
+	for _, flag := range flags {
+		names, usage, _ := completionFlagNames(flag)
+		for _, name := range names {
+			prefix := "--"
+			if len(name) == 1 {
+				prefix = "-"
+			}
+			candidates = append(candidates, completionCandidate{Value: prefix + name, Description: usage})
+		}
+	}
+
+	return candidates
+}
+
+// completionDriverFlags returns the create flags of the driver, read from
+// its plugin like create does.
+func completionDriverFlags(api libmachine.API, driverName string) []cli.Flag {
+	if driverName == "" {
+		driverName = os.Getenv("MACHINE_DRIVER")
+		if driverName == "" {
+			driverName = "virtualbox"
+		}
+	}
+
+	rawDriver, err := json.Marshal(&drivers.BaseDriver{
+		MachineName: "flag-lookup",
+	})
+	if err != nil {
+		return nil
+	}
+
+	h, err := api.NewHost(driverName, rawDriver)
+	if err != nil || h == nil {
+		log.Debugf("Error loading the driver %q: %s", driverName, err)
+		return nil
+	}
+
+	cliFlags, err := convertMcnFlagsToCliFlags(h.Driver.GetCreateFlags())
+	if err != nil {
+		return nil
+	}
+
+	return cliFlags
+}
+
+// completionMachines returns the names of the machines which can be given
+// to the command, skipping those already given to it.
+func completionMachines(api libmachine.API, commandPath string, args []string) []completionCandidate {
+	commandArgs, ok := commandsArgs[commandPath]
+	if !ok || commandArgs.machines == noMachineArgs || commandArgs.machines == pathArgs {
+		return nil
+	}
+
+	if len(args) > 0 && commandArgs.machines != multipleMachineArgs {
+		return nil
+	}
+
+	names, err := api.List()
+	if err != nil {
+		return nil
+	}
+
+	given := map[string]bool{}
+	for _, arg := range args {
+		given[arg] = true
+	}
+
+	states := commandArgs.states
+	filtered := len(states) > 0
+
+	candidates := []completionCandidate{}
+	for _, name := range names {
+		if given[name] {
+			continue
+		}
+
+		description := ""
+		if filtered {
+			h, err := api.Load(name)
+			if err != nil {
+				continue
+			}
+
+			currentState, err := h.Driver.GetState()
+			if err != nil || !completionStateIn(currentState, states) {
+				continue
+			}
+
+			description = currentState.String()
+		}
+
+		candidates = append(candidates, completionCandidate{Value: name, Description: description})
+	}
+
+	return candidates
+}
+
+func completionStateIn(s state.State, states []state.State) bool {
+	for _, candidate := range states {
+		if s == candidate {
+			return true
+		}
+	}
+
+	return false
+}
+
+// completionDriverNames returns the core drivers and those installed as
+// docker-machine-driver-<name> in the PATH.
+func completionDriverNames() []string {
+	names := map[string]bool{}
+	for _, name := range localbinary.CoreDrivers {
+		names[name] = true
+	}
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		matches, _ := filepath.Glob(filepath.Join(dir, "docker-machine-driver-*"))
+		for _, match := range matches {
+			name := strings.TrimPrefix(filepath.Base(match), "docker-machine-driver-")
+			names[strings.TrimSuffix(name, ".exe")] = true
+		}
+	}
+
+	return sortedKeys(names)
+}
+
+// completionShellNames returns the shells env knows, with the POSIX ones.
+func completionShellNames() []string {
+	names := map[string]bool{"bash": true, "sh": true, "zsh": true}
+	for name := range shellFormats {
+		names[name] = true
+	}
+
+	return sortedKeys(names)
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
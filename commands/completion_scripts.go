@@ -0,0 +1,65 @@
+package commands
+
+// completionScripts are the completion scripts of the shells, {{.Name}} is
+// the name of the CLI. They run it with completion __complete and the words
+// of the command line, it prints the candidates for the last one as
+// value<TAB>description lines.
+var completionScripts = map[string]string{
+	"bash": `# bash completion for {{.Name}}, generated by {{.Name}} completion bash
+
+_{{.Name}}_complete() {
+	local IFS=$'\n'
+	local candidates
+	candidates=$("${COMP_WORDS[0]}" completion __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null | cut -f1)
+	COMPREPLY=($(compgen -W "$candidates" -- "${COMP_WORDS[COMP_CWORD]}"))
+}
+
+complete -o default -F _{{.Name}}_complete {{.Name}}
+`,
+
+	"zsh": `#compdef {{.Name}}
+# zsh completion for {{.Name}}, generated by {{.Name}} completion zsh
+
+_{{.Name}}() {
+	local -a candidates described
+	local line
+	candidates=("${(@f)$(${words[1]} completion __complete "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+	for line in $candidates; do
+		[[ -n $line ]] || continue
+		described+=("${${line%%$'\t'*}//:/\\:}:${line#*$'\t'}")
+	done
+	_describe '{{.Name}}' described || _files
+}
+
+compdef _{{.Name}} {{.Name}}
+`,
+
+	"fish": `# fish completion for {{.Name}}, generated by {{.Name}} completion fish
+
+function __{{.Name}}_complete
+	set -l words (commandline -opc) (commandline -ct)
+	set -l program $words[1]
+	set -e words[1]
+	$program completion __complete $words 2>/dev/null
+end
+
+complete -c {{.Name}} -f -a '(__{{.Name}}_complete)'
+`,
+
+	"powershell": `# PowerShell completion for {{.Name}}, generated by {{.Name}} completion powershell
+
+Register-ArgumentCompleter -Native -CommandName '{{.Name}}' -ScriptBlock {
+	param($wordToComplete, $commandAst, $cursorPosition)
+
+	$elements = @($commandAst.CommandElements | Where-Object { $_.Extent.EndOffset -lt $cursorPosition } | ForEach-Object { $_.ToString() })
+	$program = $elements[0]
+	$words = @($elements | Select-Object -Skip 1) + @($wordToComplete)
+
+	& $program completion __complete @words 2>$null | ForEach-Object {
+		$value, $description = $_ -split "` + "`" + `t", 2
+		if (-not $description) { $description = $value }
+		[System.Management.Automation.CompletionResult]::new($value, $value, 'ParameterValue', $description)
+	}
+}
+`,
+}
@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/classmarkets/docker-machine/drivers/fakedriver"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/libmachinetest"
+	"github.com/classmarkets/docker-machine/libmachine/state"
+	"github.com/codegangsta/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+func completionValues(candidates []completionCandidate) []string {
+	values := []string{}
+	for _, candidate := range candidates {
+		values = append(values, candidate.Value)
+	}
+
+	return values
+}
+
+func TestCompleteWords(t *testing.T) {
+	app := &cli.App{
+		Flags: []cli.Flag{
+			cli.BoolFlag{Name: "debug, D"},
+		},
+		Commands: []cli.Command{
+			{
+				Name: "context",
+				Subcommands: []cli.Command{
+					{Name: "sync"},
+				},
+			},
+			{
+				Name: "env",
+				Flags: []cli.Flag{
+					cli.StringFlag{Name: "shell"},
+					cli.BoolFlag{Name: "unset, u"},
+				},
+			},
+			{Name: "events"},
+			{Name: "ls"},
+			{Name: "resume"},
+			{Name: "rm"},
+			{Name: "start"},
+			{Name: "stop"},
+		},
+	}
+
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name:   "running",
+				Driver: &fakedriver.Driver{MockState: state.Running},
+			},
+			{
+				Name:   "stopped",
+				Driver: &fakedriver.Driver{MockState: state.Stopped},
+			},
+			{
+				Name:   "saved",
+				Driver: &fakedriver.Driver{MockState: state.Saved},
+			},
+		},
+	}
+
+	var tests = []struct {
+		words    []string
+		expected []string
+	}{
+		{[]string{""}, []string{"context", "env", "events", "ls", "resume", "rm", "start", "stop"}},
+		{[]string{"st"}, []string{"start", "stop"}},
+		{[]string{"-"}, []string{"--debug", "-D"}},
+		{[]string{"context", ""}, []string{"sync"}},
+		{[]string{"context", "sync", ""}, []string{}},
+		{[]string{"start", ""}, []string{"stopped", "saved"}},
+		{[]string{"resume", ""}, []string{"saved"}},
+		{[]string{"stop", ""}, []string{"running"}},
+		{[]string{"rm", ""}, []string{"running", "stopped", "saved"}},
+		{[]string{"rm", "running", ""}, []string{"stopped", "saved"}},
+		{[]string{"env", ""}, []string{"running"}},
+		{[]string{"env", "running", ""}, []string{}},
+		{[]string{"env", "--"}, []string{"--shell", "--unset"}},
+		{[]string{"env", "--shell", "fi"}, []string{"fish"}},
+		{[]string{"env", "-u", "--shell=fish", ""}, []string{"running"}},
+		{[]string{"events", ""}, []string{}},
+		{[]string{"ls", ""}, []string{}},
+		{[]string{"unknown", ""}, []string{}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, completionValues(completeWords(app, api, test.words)), "%v", test.words)
+	}
+}
+
+// driverRecordingAPI records the drivers whose create flags are loaded.
+type driverRecordingAPI struct {
+	*libmachinetest.FakeAPI
+	driverNames []string
+}
+
+func (api *driverRecordingAPI) NewHost(driverName string, rawDriver []byte) (*host.Host, error) {
+	api.driverNames = append(api.driverNames, driverName)
+	return nil, nil
+}
+
+func TestCompleteWordsDriverFlags(t *testing.T) {
+	app := &cli.App{
+		Commands: []cli.Command{
+			{
+				Name: "create",
+				Flags: []cli.Flag{
+					cli.StringFlag{Name: "driver, d"},
+				},
+			},
+		},
+	}
+
+	for _, words := range [][]string{
+		{"create", "--driver", "amazonec2", "--"},
+		{"create", "-d", "amazonec2", "--"},
+		{"create", "--driver=amazonec2", "--"},
+		{"create", "-d=amazonec2", "--"},
+	} {
+		api := &driverRecordingAPI{FakeAPI: &libmachinetest.FakeAPI{}}
+
+		completeWords(app, api, words)
+
+		assert.Equal(t, []string{"amazonec2"}, api.driverNames, "%v", words)
+	}
+}
+
+func TestCompletionFlagNames(t *testing.T) {
+	names, usage, takesValue := completionFlagNames(cli.StringFlag{Name: "driver, d", Usage: "Driver to create machine with."})
+	assert.Equal(t, []string{"driver", "d"}, names)
+	assert.Equal(t, "Driver to create machine with.", usage)
+	assert.True(t, takesValue)
+
+	names, _, takesValue = completionFlagNames(cli.BoolFlag{Name: "force, f"})
+	assert.Equal(t, []string{"force", "f"}, names)
+	assert.False(t, takesValue)
+}
+
+func TestCompletionScripts(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		assert.Contains(t, completionScripts[shell], "completion "+completeArg, shell)
+	}
+}
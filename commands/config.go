@@ -8,6 +8,7 @@ import (
 	"github.com/classmarkets/docker-machine/commands/mcndirs"
 	"github.com/classmarkets/docker-machine/libmachine"
 	"github.com/classmarkets/docker-machine/libmachine/check"
+	"github.com/classmarkets/docker-machine/libmachine/engine"
 	"github.com/classmarkets/docker-machine/libmachine/log"
 )
 
@@ -26,6 +27,25 @@ func cmdConfig(c CommandLine, api libmachine.API) error {
 		return err
 	}
 
+	transport, err := dockerTransport(c, host)
+	if err != nil {
+		return err
+	}
+
+	if transport == engine.TransportSSH {
+		if c.Bool("swarm") {
+			return errSwarmOverSSH
+		}
+
+		dockerHost, err := sshDockerHost(mcndirs.GetMachineDir(), host)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("-H=%s\n", dockerHost)
+		return nil
+	}
+
 	dockerHost, _, err := check.DefaultConnChecker.Check(host, c.Bool("swarm"))
 	if err != nil {
 		return fmt.Errorf("Error running connection boilerplate: %s", err)
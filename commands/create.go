@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"bytes"
 	"encoding/json"
 	"flag"
 	"fmt"
@@ -9,6 +10,8 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
+	"text/template"
 
 	"errors"
 
@@ -18,6 +21,7 @@ import (
 	"github.com/classmarkets/docker-machine/commands/mcndirs"
 	"github.com/classmarkets/docker-machine/libmachine"
 	"github.com/classmarkets/docker-machine/libmachine/auth"
+	"github.com/classmarkets/docker-machine/libmachine/cert"
 	"github.com/classmarkets/docker-machine/libmachine/crashreport"
 	"github.com/classmarkets/docker-machine/libmachine/drivers"
 	"github.com/classmarkets/docker-machine/libmachine/drivers/rpc"
@@ -26,15 +30,28 @@ import (
 	"github.com/classmarkets/docker-machine/libmachine/log"
 	"github.com/classmarkets/docker-machine/libmachine/mcnerror"
 	"github.com/classmarkets/docker-machine/libmachine/mcnflag"
+	"github.com/classmarkets/docker-machine/libmachine/ssh"
 	"github.com/classmarkets/docker-machine/libmachine/swarm"
 )
 
+const (
+	// createBatchConcurrency bounds how many machines of a batch are
+	// created at the same time, so that cloud providers don't rate limit
+	// us.
+	createBatchConcurrency = 5
+	defaultNameTemplate    = "{{ .Name }}-{{ .Index }}"
+)
+
 var (
 	errNoMachineName = errors.New("Error: No machine name specified")
 )
 
 var (
 	SharedCreateFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "interactive, i",
+			Usage: "Ask for the driver and its flags, and confirm the command line before creating the machine",
+		},
 		cli.StringFlag{
 			Name:   "driver, d",
 			Usage:  "Driver to create machine with.",
@@ -77,6 +94,16 @@ var (
 			Usage: "Specify environment variables to set in the engine",
 			Value: &cli.StringSlice{},
 		},
+		cli.StringFlag{
+			Name:   "engine-transport",
+			Usage:  "How the Docker client reaches the engine: tcp (TLS on port 2376) or ssh (the port isn't exposed)",
+			Value:  engine.TransportTCP,
+			EnvVar: "MACHINE_ENGINE_TRANSPORT",
+		},
+		cli.BoolFlag{
+			Name:  "docker-context",
+			Usage: "Write a Docker CLI context for the machine, to switch to it with docker context use",
+		},
 		cli.BoolFlag{
 			Name:  "swarm",
 			Usage: "Configure Machine to join a Swarm cluster",
@@ -130,6 +157,42 @@ var (
 			Usage: "Support extra SANs for TLS certs",
 			Value: &cli.StringSlice{},
 		},
+		cli.IntFlag{
+			Name:  "count",
+			Usage: "Number of identical machines to create, named <name>-1 to <name>-N unless --name-template is set",
+			Value: 1,
+		},
+		cli.StringFlag{
+			Name:  "name-template",
+			Usage: "Go template used to name the machines of a batch, e.g. '{{ .Name }}-{{ .Index }}'",
+			Value: "",
+		},
+		cli.BoolFlag{
+			Name:  "rollback-on-failure",
+			Usage: "Stop creating the machines of a batch once one of them fails to be created, and remove those created",
+		},
+		cli.StringSliceFlag{
+			Name:  "depends-on",
+			Usage: "Machine to start before this one and to stop after it when they're started or stopped together",
+			Value: &cli.StringSlice{},
+		},
+		cli.IntFlag{
+			Name:  "start-priority",
+			Usage: "Order of the machines started together which don't depend on each other, the higher priorities start first and stop last",
+			Value: 0,
+		},
+		cli.StringFlag{
+			Name:   "ssh-proxy-jump",
+			Usage:  "Jump host to reach the machine through, in the form [user@]host[:port]",
+			Value:  "",
+			EnvVar: "MACHINE_SSH_PROXY_JUMP",
+		},
+		cli.StringFlag{
+			Name:   "ssh-proxy-jump-key",
+			Usage:  "Private key to authenticate with on the jump host, the key of the machine is used if not set",
+			Value:  "",
+			EnvVar: "MACHINE_SSH_PROXY_JUMP_KEY",
+		},
 	}
 )
 
@@ -138,34 +201,144 @@ func cmdCreateInner(c CommandLine, api libmachine.API) error {
 		return fmt.Errorf("Invalid command line. Found extra arguments %v", c.Args()[1:])
 	}
 
-	name := c.Args().First()
-	if name == "" {
+	count := 1
+	if c.IsSet("count") {
+		count = c.Int("count")
+		if count < 1 {
+			return fmt.Errorf("Invalid count %d: at least one machine must be created", count)
+		}
+	}
+
+	names, err := createMachineNames(c.Args().First(), count, c.String("name-template"))
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
 		c.ShowHelp()
 		return errNoMachineName
 	}
 
-	validName := host.ValidateHostName(name)
-	if !validName {
-		return fmt.Errorf("Error creating machine: %s", mcnerror.ErrInvalidHostname)
+	for _, name := range names {
+		if !host.ValidateHostName(name) {
+			return fmt.Errorf("Error creating machine %q: %s", name, mcnerror.ErrInvalidHostname)
+		}
 	}
 
 	if err := validateSwarmDiscovery(c.String("swarm-discovery")); err != nil {
 		return fmt.Errorf("Error parsing swarm discovery: %s", err)
 	}
 
+	if err := validateSSHProxyJump(c.String("ssh-proxy-jump"), c.String("ssh-proxy-jump-key")); err != nil {
+		return err
+	}
+
+	if err := validateEngineTransport(c.String("engine-transport"), c.Bool("swarm") || c.Bool("swarm-master")); err != nil {
+		return err
+	}
+
+	if len(names) == 1 {
+		h, err := newCreateHost(c, api, names[0])
+		if err != nil {
+			return err
+		}
+
+		if err := createHost(api, h); err != nil {
+			return err
+		}
+
+		if c.Bool("docker-context") {
+			if err := writeDockerContext(dockerConfigDir(), api.GetMachinesDir(), h); err != nil {
+				log.Warnf("Error writing the Docker context: %s", err)
+			} else {
+				log.Infof("To connect your Docker Client to the Docker Engine running on this virtual machine, run: docker context use %s", dockerContextName(h.Name))
+				return nil
+			}
+		}
+
+		log.Infof("To see how to connect your Docker Client to the Docker Engine running on this virtual machine, run: %s env %s", os.Args[0], h.Name)
+
+		return nil
+	}
+
+	return createHosts(c, api, names)
+}
+
+// createMachineNames returns the names of the machines to create. A single
+// machine is named after the argument unless a name template is given; a
+// batch of machines is numbered starting at 1 using the template, which
+// defaults to "<name>-<index>".
+func createMachineNames(name string, count int, nameTemplate string) ([]string, error) {
+	if count <= 1 && nameTemplate == "" {
+		if name == "" {
+			return nil, nil
+		}
+		return []string{name}, nil
+	}
+
+	if nameTemplate == "" {
+		if name == "" {
+			return nil, nil
+		}
+		nameTemplate = defaultNameTemplate
+	}
+
+	tmpl, err := template.New("name").Parse(nameTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing name template: %s", err)
+	}
+
+	names := []string{}
+	seen := map[string]bool{}
+	for i := 1; i <= count; i++ {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, struct {
+			Name  string
+			Index int
+		}{name, i}); err != nil {
+			return nil, fmt.Errorf("Error executing name template: %s", err)
+		}
+
+		generated := strings.TrimSpace(buf.String())
+		if seen[generated] {
+			return nil, fmt.Errorf("Name template %q generates duplicate machine name %q", nameTemplate, generated)
+		}
+		seen[generated] = true
+
+		names = append(names, generated)
+	}
+
+	return names, nil
+}
+
+// newCreateHost builds a host named name from the create flags and
+// configures its driver, without creating anything yet.
+func newCreateHost(c CommandLine, api libmachine.API, name string) (*host.Host, error) {
+	proxyJumpKeyPath := c.String("ssh-proxy-jump-key")
+	if proxyJumpKeyPath != "" {
+		// The machine may be used from another working directory
+		absPath, err := filepath.Abs(proxyJumpKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("Error resolving jump host key path: %s", err)
+		}
+		proxyJumpKeyPath = absPath
+	}
+
 	// TODO: Fix hacky JSON solution
 	rawDriver, err := json.Marshal(&drivers.BaseDriver{
-		MachineName: name,
-		StorePath:   c.GlobalString("storage-path"),
+		MachineName:         name,
+		StorePath:           c.GlobalString("storage-path"),
+		SSHProxyJump:        c.String("ssh-proxy-jump"),
+		SSHProxyJumpKeyPath: proxyJumpKeyPath,
 	})
 	if err != nil {
-		return fmt.Errorf("Error attempting to marshal bare driver data: %s", err)
+		return nil, fmt.Errorf("Error attempting to marshal bare driver data: %s", err)
 	}
 
 	driverName := c.String("driver")
 	h, err := api.NewHost(driverName, rawDriver)
 	if err != nil {
-		return fmt.Errorf("Error getting new host: %s", err)
+		return nil, fmt.Errorf("Error getting new host: %s", err)
 	}
 
 	h.HostOptions = &host.Options{
@@ -189,6 +362,7 @@ func cmdCreateInner(c CommandLine, api libmachine.API) error {
 			StorageDriver:    c.String("engine-storage-driver"),
 			TLSVerify:        true,
 			InstallURL:       c.String("engine-install-url"),
+			Transport:        c.String("engine-transport"),
 		},
 		SwarmOptions: &swarm.Options{
 			IsSwarm:            c.Bool("swarm") || c.Bool("swarm-master"),
@@ -203,14 +377,16 @@ func cmdCreateInner(c CommandLine, api libmachine.API) error {
 			ArbitraryJoinFlags: c.StringSlice("swarm-join-opt"),
 			IsExperimental:     c.Bool("swarm-experimental"),
 		},
+		DependsOn:     c.StringSlice("depends-on"),
+		StartPriority: c.Int("start-priority"),
 	}
 
 	exists, err := api.Exists(h.Name)
 	if err != nil {
-		return fmt.Errorf("Error checking if host exists: %s", err)
+		return nil, fmt.Errorf("Error checking if host exists: %s", err)
 	}
 	if exists {
-		return mcnerror.ErrHostAlreadyExists{
+		return nil, mcnerror.ErrHostAlreadyExists{
 			Name: h.Name,
 		}
 	}
@@ -222,9 +398,13 @@ func cmdCreateInner(c CommandLine, api libmachine.API) error {
 	driverOpts := getDriverOpts(c, mcnFlags)
 
 	if err := h.Driver.SetConfigFromFlags(driverOpts); err != nil {
-		return fmt.Errorf("Error setting machine configuration from flags provided: %s", err)
+		return nil, fmt.Errorf("Error setting machine configuration from flags provided: %s", err)
 	}
 
+	return h, nil
+}
+
+func createHost(api libmachine.API, h *host.Host) error {
 	if err := api.Create(h); err != nil {
 		// Wait for all the logs to reach the client
 		time.Sleep(2 * time.Second)
@@ -247,7 +427,151 @@ func cmdCreateInner(c CommandLine, api libmachine.API) error {
 		return fmt.Errorf("Error attempting to save store: %s", err)
 	}
 
-	log.Infof("To see how to connect your Docker Client to the Docker Engine running on this virtual machine, run: %s env %s", os.Args[0], name)
+	return nil
+}
+
+// errCreateSkipped is the error of the machines of a batch which weren't
+// created, because another one failed and the batch is rolled back.
+var errCreateSkipped = errors.New("Skipped, another machine of the batch failed")
+
+// createHosts creates a batch of machines, running at most
+// createBatchConcurrency creations at the same time. Every machine is
+// created, even if some of them fail, unless --rollback-on-failure is set.
+// Then the machines not being created yet are skipped once one fails, and
+// those created are removed again when the others are done.
+func createHosts(c CommandLine, api libmachine.API, names []string) error {
+	hosts := []*host.Host{}
+	for _, name := range names {
+		h, err := newCreateHost(c, api, name)
+		if err != nil {
+			return err
+		}
+		hosts = append(hosts, h)
+	}
+
+	// The machines share the certificates, which would be generated by
+	// each of the creations at the same time otherwise
+	if err := cert.BootstrapCertificates(hosts[0].AuthOptions()); err != nil {
+		return fmt.Errorf("Error generating certificates: %s", err)
+	}
+
+	log.Infof("Creating %d machines: %s", len(hosts), strings.Join(names, ", "))
+
+	rollback := c.Bool("rollback-on-failure")
+	errs := createBatch(hosts, rollback, func(h *host.Host) error {
+		if err := createHost(api, h); err != nil {
+			return err
+		}
+
+		if c.Bool("docker-context") {
+			if err := writeDockerContext(dockerConfigDir(), api.GetMachinesDir(), h); err != nil {
+				log.Warnf("%s: Error writing the Docker context: %s", h.Name, err)
+			}
+		}
+
+		return nil
+	})
+
+	failed := []error{}
+	for i, h := range hosts {
+		switch errs[i] {
+		case nil:
+			log.Infof("%s: created", h.Name)
+		case errCreateSkipped:
+			log.Infof("%s: skipped", h.Name)
+		default:
+			log.Errorf("%s: %s", h.Name, errs[i])
+			failed = append(failed, fmt.Errorf("Error creating machine %q: %s", h.Name, errs[i]))
+		}
+	}
+
+	if len(failed) == 0 {
+		log.Infof("To see how to connect your Docker Client to the Docker Engine running on these virtual machines, run: %s env <name>", os.Args[0])
+		return nil
+	}
+
+	if rollback {
+		log.Warnf("%d of %d machines failed to be created, removing the whole batch...", len(failed), len(hosts))
+		for i, h := range hosts {
+			if errs[i] == errCreateSkipped {
+				continue
+			}
+
+			if err := rollbackHost(api, h); err != nil {
+				failed = append(failed, fmt.Errorf("Error rolling back machine %q: %s", h.Name, err))
+			}
+		}
+	}
+
+	return consolidateErrs(failed)
+}
+
+// createBatch runs create on the hosts, at most createBatchConcurrency at
+// the same time, and returns their errors. With stopOnFailure, the hosts
+// whose creation didn't start yet when one fails are skipped with
+// errCreateSkipped, those being created are finished.
+func createBatch(hosts []*host.Host, stopOnFailure bool, create func(h *host.Host) error) []error {
+	errs := make([]error, len(hosts))
+	sem := make(chan struct{}, createBatchConcurrency)
+	wg := sync.WaitGroup{}
+
+	failed := false
+	failedLock := sync.Mutex{}
+
+	for i, h := range hosts {
+		wg.Add(1)
+		go func(i int, h *host.Host) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			failedLock.Lock()
+			skip := stopOnFailure && failed
+			failedLock.Unlock()
+
+			if skip {
+				errs[i] = errCreateSkipped
+				return
+			}
+
+			if errs[i] = create(h); errs[i] != nil {
+				failedLock.Lock()
+				failed = true
+				failedLock.Unlock()
+			}
+		}(i, h)
+	}
+
+	wg.Wait()
+
+	return errs
+}
+
+// rollbackHost removes a machine from a failed batch, both the remote
+// instance and its local store entry.
+func rollbackHost(api libmachine.API, h *host.Host) error {
+	if err := h.Driver.Remove(); err != nil {
+		log.Warnf("Error removing remote machine %q: %s", h.Name, err)
+	}
+
+	exists, err := api.Exists(h.Name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return nil
+	}
+
+	if err := api.Remove(h.Name); err != nil {
+		return err
+	}
+
+	if err := removeDockerContext(dockerConfigDir(), api.GetMachinesDir(), h.Name); err != nil {
+		log.Warnf("Error removing the Docker context of %q: %s", h.Name, err)
+	}
+
+	log.Infof("Successfully removed %s", h.Name)
 
 	return nil
 }
@@ -293,6 +617,23 @@ func cmdCreateOuter(c CommandLine, api libmachine.API) error {
 		flagLookupMachineName = "flag-lookup"
 	)
 
+	if prefix, args := splitCreateArgs(os.Args); isCreateInteractive(args) {
+		wizard := newCreateWizard(api, os.Stdin, os.Stdout)
+
+		completed, err := wizard.run(args)
+		if err != nil {
+			return err
+		}
+
+		commandLine := append(append([]string{}, prefix...), completed...)
+		if err := wizard.confirm(commandLine); err != nil {
+			return err
+		}
+
+		// The rest of create reads its flags from the command line.
+		os.Args = commandLine
+	}
+
 	// We didn't recognize the driver name.
 	driverName := flagHackLookup("--driver")
 	if driverName == "" {
@@ -460,3 +801,38 @@ func tlsPath(c CommandLine, flag string, defaultName string) string {
 
 	return filepath.Join(mcndirs.GetMachineCertDir(), defaultName)
 }
+
+func validateSSHProxyJump(address, keyPath string) error {
+	if address == "" {
+		if keyPath != "" {
+			return errors.New("--ssh-proxy-jump-key requires --ssh-proxy-jump")
+		}
+		return nil
+	}
+
+	if _, _, _, err := ssh.ParseProxyJump(address); err != nil {
+		return err
+	}
+
+	if keyPath != "" {
+		if _, err := os.Stat(keyPath); err != nil {
+			return fmt.Errorf("Error reading jump host key: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func validateEngineTransport(transport string, isSwarm bool) error {
+	switch transport {
+	case "", engine.TransportTCP:
+		return nil
+	case engine.TransportSSH:
+		if isSwarm {
+			return errSwarmOverSSH
+		}
+		return nil
+	}
+
+	return fmt.Errorf("Unknown engine transport %q, expected %s or %s", transport, engine.TransportTCP, engine.TransportSSH)
+}
@@ -1,10 +1,14 @@
 package commands
 
 import (
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
 
 	"flag"
 	"github.com/classmarkets/docker-machine/commands/commandstest"
+	"github.com/classmarkets/docker-machine/libmachine/host"
 	"github.com/classmarkets/docker-machine/libmachine/mcnflag"
 	"github.com/stretchr/testify/assert"
 )
@@ -24,6 +28,14 @@ func TestValidateSwarmDiscoveryAcceptsValidFormat(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestValidateSSHProxyJump(t *testing.T) {
+	assert.NoError(t, validateSSHProxyJump("", ""))
+	assert.NoError(t, validateSSHProxyJump("jump@bastion:2222", ""))
+	assert.Error(t, validateSSHProxyJump("", "/fake/bastion/id_rsa"))
+	assert.Error(t, validateSSHProxyJump("jump@bastion:port", ""))
+	assert.Error(t, validateSSHProxyJump("bastion", "/fake/bastion/id_rsa"))
+}
+
 type fakeFlagGetter struct {
 	flag.Value
 	value interface{}
@@ -117,3 +129,90 @@ func TestGetDriverOpts(t *testing.T) {
 		assert.Equal(t, tt.expected["stringslice_defaulted"], driverOpts.StringSlice("stringslice_defaulted"))
 	}
 }
+
+func TestCreateMachineNamesSingle(t *testing.T) {
+	names, err := createMachineNames("dev", 1, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"dev"}, names)
+}
+
+func TestCreateMachineNamesNoName(t *testing.T) {
+	names, err := createMachineNames("", 3, "")
+
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestCreateMachineNamesCount(t *testing.T) {
+	names, err := createMachineNames("worker", 3, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"worker-1", "worker-2", "worker-3"}, names)
+}
+
+func TestCreateMachineNamesTemplate(t *testing.T) {
+	names, err := createMachineNames("", 2, "node{{ .Index }}.swarm")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"node1.swarm", "node2.swarm"}, names)
+}
+
+func TestCreateMachineNamesTemplateDuplicates(t *testing.T) {
+	_, err := createMachineNames("worker", 2, "{{ .Name }}")
+
+	assert.EqualError(t, err, `Name template "{{ .Name }}" generates duplicate machine name "worker"`)
+}
+
+func TestCreateMachineNamesInvalidTemplate(t *testing.T) {
+	_, err := createMachineNames("worker", 2, "{{ .Name ")
+
+	assert.Error(t, err)
+}
+
+func TestValidateEngineTransport(t *testing.T) {
+	assert.NoError(t, validateEngineTransport("", false))
+	assert.NoError(t, validateEngineTransport("tcp", true))
+	assert.NoError(t, validateEngineTransport("ssh", false))
+	assert.Equal(t, errSwarmOverSSH, validateEngineTransport("ssh", true))
+	assert.Error(t, validateEngineTransport("udp", false))
+}
+
+func TestCreateBatch(t *testing.T) {
+	hosts := []*host.Host{}
+	for i := 0; i < 3*createBatchConcurrency; i++ {
+		hosts = append(hosts, &host.Host{Name: fmt.Sprintf("worker-%d", i)})
+	}
+
+	errCreate := errors.New("quota exceeded")
+	for _, stopOnFailure := range []bool{false, true} {
+		created := 0
+		createdLock := sync.Mutex{}
+
+		errs := createBatch(hosts, stopOnFailure, func(h *host.Host) error {
+			createdLock.Lock()
+			defer createdLock.Unlock()
+
+			created++
+			return errCreate
+		})
+
+		skipped := 0
+		for _, err := range errs {
+			if err == errCreateSkipped {
+				skipped++
+			} else {
+				assert.Equal(t, errCreate, err)
+			}
+		}
+
+		if stopOnFailure {
+			// Only the creations started before the first failure run
+			assert.True(t, created <= createBatchConcurrency, "%d machines created", created)
+			assert.Equal(t, len(hosts)-created, skipped)
+		} else {
+			assert.Equal(t, len(hosts), created)
+			assert.Equal(t, 0, skipped)
+		}
+	}
+}
@@ -0,0 +1,564 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/classmarkets/docker-machine/libmachine"
+	"github.com/classmarkets/docker-machine/libmachine/drivers"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/mcnerror"
+	"github.com/classmarkets/docker-machine/libmachine/mcnflag"
+	"github.com/codegangsta/cli"
+	"github.com/docker/docker/pkg/term"
+)
+
+var (
+	errCreateWizardAborted = errors.New("Aborted, no machine was created")
+
+	// safeShellWord matches the words which don't need quoting when the
+	// command line is printed.
+	safeShellWord = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+	// secretFlagName matches the driver flags taking credentials, which
+	// are neither echoed nor written to the command line.
+	secretFlagName = regexp.MustCompile(`secret|token|password|api-key`)
+)
+
+// createWizard asks for the name, the driver and the driver flags of a
+// machine, and returns the equivalent create command line.
+// The values of the secret flags are passed in their environment variable
+// instead.
+type createWizard struct {
+	api   libmachine.API
+	rawIn io.Reader
+	in    *bufio.Reader
+	out   io.Writer
+
+	secrets []secretFlag
+}
+
+// secretFlag is a secret flag set through its environment variable.
+type secretFlag struct {
+	name   string
+	envVar string
+}
+
+func newCreateWizard(api libmachine.API, in io.Reader, out io.Writer) *createWizard {
+	return &createWizard{
+		api:   api,
+		rawIn: in,
+		in:    bufio.NewReader(in),
+		out:   out,
+	}
+}
+
+// isCreateInteractive returns whether create was run with -i or
+// --interactive, args being the arguments following it.
+func isCreateInteractive(args []string) bool {
+	for _, arg := range args {
+		if arg == "-i" || arg == "--interactive" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitCreateArgs splits the command line around the create command.
+func splitCreateArgs(osArgs []string) ([]string, []string) {
+	for i, arg := range osArgs {
+		if i > 0 && arg == "create" {
+			return osArgs[:i+1], osArgs[i+1:]
+		}
+	}
+
+	return osArgs, nil
+}
+
+// run asks for what the arguments of create don't give and returns them
+// completed, without the interactive flag.
+func (w *createWizard) run(args []string) ([]string, error) {
+	given := []string{}
+	for _, arg := range args {
+		if arg != "-i" && arg != "--interactive" {
+			given = append(given, arg)
+		}
+	}
+
+	sharedFlags, _ := parseCreateArgs(given, SharedCreateFlags)
+
+	completed := []string{}
+
+	driverName, ok := sharedFlags["driver"]
+	if !ok {
+		var err error
+		if driverName, err = w.askDriver(); err != nil {
+			return nil, err
+		}
+		completed = append(completed, "--driver", driverName)
+	}
+
+	mcnFlags, err := w.driverFlags(driverName)
+	if err != nil {
+		return nil, err
+	}
+
+	// The values of the driver flags given are only told apart from the
+	// machine name once the driver flags are known.
+	cliFlags, err := convertMcnFlagsToCliFlags(mcnFlags)
+	if err != nil {
+		return nil, err
+	}
+	given = w.passSecretsInEnv(given, mcnFlags)
+	givenFlags, name := parseCreateArgs(given, append(cliFlags, SharedCreateFlags...))
+
+	nameGiven := name != ""
+	if !nameGiven {
+		if name, err = w.askMachineName(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(mcnFlags) > 0 {
+		fmt.Fprintf(w.out, "\nThe %s driver has %d flags, press enter to keep the default.\n", driverName, len(mcnFlags))
+	}
+
+	for _, f := range mcnFlags {
+		if _, ok := givenFlags[f.String()]; ok {
+			continue
+		}
+
+		answer, err := w.askFlag(f)
+		if err != nil {
+			return nil, err
+		}
+		completed = append(completed, answer...)
+	}
+
+	completed = append(completed, given...)
+	if !nameGiven {
+		completed = append(completed, name)
+	}
+
+	return completed, nil
+}
+
+// passSecretsInEnv moves the secret flags given on the command line into
+// their environment variable, and returns the other arguments.
+func (w *createWizard) passSecretsInEnv(args []string, mcnFlags []mcnflag.Flag) []string {
+	envVars := map[string]string{}
+	for _, f := range mcnFlags {
+		if envVar := secretFlagEnvVar(f); envVar != "" {
+			envVars[f.String()] = envVar
+		}
+	}
+
+	kept := []string{}
+	for i := 0; i < len(args); i++ {
+		flagName, value := strings.TrimLeft(args[i], "-"), ""
+		hasValue := false
+		if parts := strings.SplitN(flagName, "=", 2); len(parts) == 2 {
+			flagName, value, hasValue = parts[0], parts[1], true
+		}
+
+		envVar, ok := envVars[flagName]
+		if !ok || !strings.HasPrefix(args[i], "--") {
+			kept = append(kept, args[i])
+			continue
+		}
+
+		if !hasValue && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		w.setSecret(flagName, envVar, value)
+	}
+
+	return kept
+}
+
+func (w *createWizard) setSecret(flagName, envVar, value string) {
+	os.Setenv(envVar, value)
+	w.secrets = append(w.secrets, secretFlag{flagName, envVar})
+}
+
+// secretFlagEnvVar returns the environment variable of a secret flag, or
+// an empty string if the flag isn't secret or can't be given that way.
+func secretFlagEnvVar(f mcnflag.Flag) string {
+	if p, ok := f.(*mcnflag.StringFlag); ok {
+		f = *p
+	}
+
+	if f, ok := f.(mcnflag.StringFlag); ok && secretFlagName.MatchString(f.Name) {
+		return f.EnvVar
+	}
+
+	return ""
+}
+
+// confirm prints the command line and asks whether to create the machine
+// with it, and whether to save it to a file to run it again later.
+func (w *createWizard) confirm(commandLine []string) error {
+	quoted := make([]string, len(commandLine))
+	for i, word := range commandLine {
+		quoted[i] = quoteShellWord(word)
+	}
+	line := strings.Join(quoted, " ")
+
+	fmt.Fprintf(w.out, "\nThe machine will be created with:\n\n    %s\n\n", line)
+
+	checks := ""
+	for _, secret := range w.secrets {
+		fmt.Fprintf(w.out, "--%s is passed in %s, which has to be set to run the command line again.\n", secret.name, secret.envVar)
+		checks += fmt.Sprintf(": \"${%s:?has to be set for --%s}\"\n", secret.envVar, secret.name)
+	}
+	if len(w.secrets) > 0 {
+		fmt.Fprintln(w.out)
+	}
+
+	ok, err := w.askBool("Create the machine?", true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errCreateWizardAborted
+	}
+
+	path, err := w.ask("Save the command line to a file to run it again (leave empty to skip)", "")
+	if err != nil {
+		return err
+	}
+	if path != "" {
+		if err := writeCreateScript(path, "#!/bin/sh\n"+checks+line+" \"$@\"\n"); err != nil {
+			return fmt.Errorf("Error saving the command line: %s", err)
+		}
+		fmt.Fprintf(w.out, "Saved to %s\n", path)
+	}
+
+	return nil
+}
+
+// writeCreateScript writes the script running the command line, only
+// readable by the user even if the file already exists.
+func writeCreateScript(path, script string) error {
+	if err := ioutil.WriteFile(path, []byte(script), 0700); err != nil {
+		return err
+	}
+
+	return os.Chmod(path, 0700)
+}
+
+func (w *createWizard) askMachineName() (string, error) {
+	for {
+		name, err := w.ask("Machine name", defaultMachineName)
+		if err != nil {
+			return "", err
+		}
+
+		if !host.ValidateHostName(name) {
+			fmt.Fprintf(w.out, "  %s\n", mcnerror.ErrInvalidHostname)
+			continue
+		}
+
+		exists, err := w.api.Exists(name)
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			fmt.Fprintf(w.out, "  %s\n", mcnerror.ErrHostAlreadyExists{Name: name})
+			continue
+		}
+
+		return name, nil
+	}
+}
+
+func (w *createWizard) askDriver() (string, error) {
+	names := completionDriverNames()
+
+	defaultName := os.Getenv("MACHINE_DRIVER")
+	if defaultName == "" {
+		defaultName = "virtualbox"
+	}
+
+	fmt.Fprintln(w.out, "\nInstalled drivers:")
+	for i, name := range names {
+		fmt.Fprintf(w.out, "  %2d) %s\n", i+1, name)
+	}
+
+	for {
+		answer, err := w.ask("Driver", defaultName)
+		if err != nil {
+			return "", err
+		}
+
+		if i, err := strconv.Atoi(answer); err == nil && i >= 1 && i <= len(names) {
+			return names[i-1], nil
+		}
+
+		for _, name := range names {
+			if name == answer {
+				return name, nil
+			}
+		}
+
+		fmt.Fprintf(w.out, "  Unknown driver %q, enter its name or number\n", answer)
+	}
+}
+
+// driverFlags returns the create flags of the driver, read from its plugin
+// like create does.
+func (w *createWizard) driverFlags(driverName string) ([]mcnflag.Flag, error) {
+	rawDriver, err := json.Marshal(&drivers.BaseDriver{
+		MachineName: "flag-lookup",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error attempting to marshal bare driver data: %s", err)
+	}
+
+	h, err := w.api.NewHost(driverName, rawDriver)
+	if err != nil {
+		return nil, err
+	}
+	if h == nil {
+		return nil, nil
+	}
+
+	return h.Driver.GetCreateFlags(), nil
+}
+
+// askFlag asks for the value of a driver flag and returns the arguments to
+// give it with, none when the default is kept.
+func (w *createWizard) askFlag(f mcnflag.Flag) ([]string, error) {
+	// The flags of the plugins come as pointers over RPC
+	switch p := f.(type) {
+	case *mcnflag.StringFlag:
+		f = *p
+	case *mcnflag.StringSliceFlag:
+		f = *p
+	case *mcnflag.IntFlag:
+		f = *p
+	case *mcnflag.BoolFlag:
+		f = *p
+	}
+
+	var usage, envVar string
+	switch f := f.(type) {
+	case mcnflag.StringFlag:
+		usage, envVar = f.Usage, f.EnvVar
+	case mcnflag.StringSliceFlag:
+		usage, envVar = f.Usage, f.EnvVar
+	case mcnflag.IntFlag:
+		usage, envVar = f.Usage, f.EnvVar
+	case mcnflag.BoolFlag:
+		usage, envVar = f.Usage, f.EnvVar
+	default:
+		return nil, nil
+	}
+
+	fmt.Fprintf(w.out, "\n--%s\n  %s\n", f.String(), usage)
+	if envVar != "" {
+		fmt.Fprintf(w.out, "  Environment variable: %s\n", envVar)
+	}
+
+	defaultValue := createWizardDefault(f)
+	if envVar != "" && os.Getenv(envVar) != "" {
+		defaultValue = os.Getenv(envVar)
+	}
+
+	flagName := "--" + f.String()
+
+	if envVar := secretFlagEnvVar(f); envVar != "" {
+		label := "  Value (hidden)"
+		if os.Getenv(envVar) != "" {
+			label = fmt.Sprintf("  Value (hidden, press enter to keep %s)", envVar)
+		}
+
+		answer, err := w.askSecret(label)
+		if err != nil {
+			return nil, err
+		}
+
+		if answer != "" {
+			w.setSecret(f.String(), envVar, answer)
+		} else if os.Getenv(envVar) != "" {
+			w.secrets = append(w.secrets, secretFlag{f.String(), envVar})
+		}
+
+		return nil, nil
+	}
+
+	switch f.(type) {
+	case mcnflag.BoolFlag:
+		value, err := w.askBool("  Enable?", defaultValue == "true")
+		if err != nil || !value {
+			return nil, err
+		}
+		return []string{flagName}, nil
+	case mcnflag.IntFlag:
+		for {
+			answer, err := w.ask("  Value", defaultValue)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := strconv.Atoi(answer); err != nil {
+				fmt.Fprintf(w.out, "  Expected a number, got %q\n", answer)
+				continue
+			}
+			if answer == defaultValue {
+				return nil, nil
+			}
+			return []string{flagName, answer}, nil
+		}
+	case mcnflag.StringSliceFlag:
+		answer, err := w.ask("  Comma separated", defaultValue)
+		if err != nil || answer == defaultValue {
+			return nil, err
+		}
+		args := []string{}
+		for _, value := range strings.Split(answer, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				args = append(args, flagName, value)
+			}
+		}
+		return args, nil
+	}
+
+	answer, err := w.ask("  Value", defaultValue)
+	if err != nil || answer == defaultValue {
+		return nil, err
+	}
+
+	return []string{flagName, answer}, nil
+}
+
+// ask prints the label and returns the line the user answered, or the
+// default if it's empty.
+func (w *createWizard) ask(label, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		fmt.Fprintf(w.out, "%s [%s]: ", label, defaultValue)
+	} else {
+		fmt.Fprintf(w.out, "%s: ", label)
+	}
+
+	line, err := w.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return "", errCreateWizardAborted
+		}
+		return "", err
+	}
+
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+
+	return defaultValue, nil
+}
+
+// askSecret asks like ask, without echoing the answer on a terminal.
+func (w *createWizard) askSecret(label string) (string, error) {
+	if fd, isTerminal := term.GetFdInfo(w.rawIn); isTerminal {
+		state, err := term.SaveState(fd)
+		if err != nil {
+			return "", err
+		}
+
+		if err := term.DisableEcho(fd, state); err != nil {
+			return "", err
+		}
+		defer term.RestoreTerminal(fd, state)
+
+		// The newline isn't echoed either
+		defer fmt.Fprintln(w.out)
+	}
+
+	return w.ask(label, "")
+}
+
+func (w *createWizard) askBool(label string, defaultValue bool) (bool, error) {
+	choices := "y/N"
+	if defaultValue {
+		choices = "Y/n"
+	}
+
+	for {
+		answer, err := w.ask(fmt.Sprintf("%s (%s)", label, choices), "")
+		if err != nil {
+			return false, err
+		}
+
+		switch strings.ToLower(answer) {
+		case "":
+			return defaultValue, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// createWizardDefault returns the default of a driver flag as it's typed.
+func createWizardDefault(f mcnflag.Flag) string {
+	switch value := f.Default().(type) {
+	case string:
+		return value
+	case int:
+		return strconv.Itoa(value)
+	case []string:
+		return strings.Join(value, ",")
+	}
+
+	return ""
+}
+
+// parseCreateArgs returns the flags given to create, by their long name,
+// and the machine name if it's given.
+func parseCreateArgs(args []string, cliFlags []cli.Flag) (map[string]string, string) {
+	flags := map[string]string{}
+	name := ""
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			name = arg
+			continue
+		}
+
+		flagName := strings.TrimLeft(arg, "-")
+		value := ""
+		if parts := strings.SplitN(flagName, "=", 2); len(parts) == 2 {
+			flagName, value = parts[0], parts[1]
+		} else if completionFlagTakesValue(cliFlags, flagName) && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+
+		if longName := completionFlagName(cliFlags, flagName); longName != "" {
+			flagName = longName
+		}
+		flags[flagName] = value
+	}
+
+	return flags, name
+}
+
+// quoteShellWord quotes a word of a command line printed for the shell, if
+// it needs to be.
+func quoteShellWord(s string) string {
+	if safeShellWord.MatchString(s) {
+		return s
+	}
+
+	return shellQuote(s)
+}
@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/classmarkets/docker-machine/drivers/fakedriver"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/libmachinetest"
+	"github.com/classmarkets/docker-machine/libmachine/mcnflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateWizardRun(t *testing.T) {
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name:   defaultMachineName,
+				Driver: &fakedriver.Driver{},
+			},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	wizard := newCreateWizard(api, strings.NewReader("\nin valid\ndev\n"), out)
+
+	args, err := wizard.run([]string{"-i", "--driver", "none"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"--driver", "none", "dev"}, args)
+	assert.Contains(t, out.String(), `machine "default" already exists`)
+}
+
+func TestCreateWizardRunNameGiven(t *testing.T) {
+	wizard := newCreateWizard(&libmachinetest.FakeAPI{}, strings.NewReader(""), &bytes.Buffer{})
+
+	args, err := wizard.run([]string{"--driver=none", "--interactive", "dev"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"--driver=none", "dev"}, args)
+}
+
+func TestCreateWizardAskFlag(t *testing.T) {
+	var tests = []struct {
+		flag     mcnflag.Flag
+		input    string
+		expected []string
+	}{
+		{mcnflag.StringFlag{Name: "region", Value: "us-east-1"}, "\n", nil},
+		{mcnflag.StringFlag{Name: "region", Value: "us-east-1"}, "eu-west-1\n", []string{"--region", "eu-west-1"}},
+		{mcnflag.IntFlag{Name: "disk-size", Value: 20}, "big\n40\n", []string{"--disk-size", "40"}},
+		{mcnflag.IntFlag{Name: "disk-size", Value: 20}, "20\n", nil},
+		{mcnflag.BoolFlag{Name: "private"}, "\n", nil},
+		{mcnflag.BoolFlag{Name: "private"}, "y\n", []string{"--private"}},
+		{&mcnflag.StringFlag{Name: "url"}, "tcp://1.2.3.4:2376\n", []string{"--url", "tcp://1.2.3.4:2376"}},
+		{mcnflag.StringSliceFlag{Name: "tag"}, "a, b\n", []string{"--tag", "a", "--tag", "b"}},
+	}
+
+	for _, test := range tests {
+		wizard := newCreateWizard(&libmachinetest.FakeAPI{}, strings.NewReader(test.input), &bytes.Buffer{})
+
+		args, err := wizard.askFlag(test.flag)
+
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, args, "%s: %q", test.flag, test.input)
+	}
+}
+
+func TestCreateWizardAskFlagEnvVar(t *testing.T) {
+	defer os.Unsetenv("WIZARD_TEST_REGION")
+	os.Setenv("WIZARD_TEST_REGION", "eu-west-1")
+
+	out := &bytes.Buffer{}
+	wizard := newCreateWizard(&libmachinetest.FakeAPI{}, strings.NewReader("\n"), out)
+
+	args, err := wizard.askFlag(mcnflag.StringFlag{Name: "region", Usage: "Region", EnvVar: "WIZARD_TEST_REGION", Value: "us-east-1"})
+
+	assert.NoError(t, err)
+	assert.Nil(t, args)
+	assert.Contains(t, out.String(), "Environment variable: WIZARD_TEST_REGION")
+	assert.Contains(t, out.String(), "[eu-west-1]")
+}
+
+func TestCreateWizardAskFlagSecret(t *testing.T) {
+	defer os.Unsetenv("WIZARD_TEST_SECRET")
+	os.Unsetenv("WIZARD_TEST_SECRET")
+
+	out := &bytes.Buffer{}
+	wizard := newCreateWizard(&libmachinetest.FakeAPI{}, strings.NewReader("s3cr3t\n"), out)
+
+	args, err := wizard.askFlag(&mcnflag.StringFlag{Name: "amazonec2-secret-key", EnvVar: "WIZARD_TEST_SECRET"})
+
+	assert.NoError(t, err)
+	assert.Nil(t, args)
+	assert.Equal(t, "s3cr3t", os.Getenv("WIZARD_TEST_SECRET"))
+	assert.Equal(t, []secretFlag{{"amazonec2-secret-key", "WIZARD_TEST_SECRET"}}, wizard.secrets)
+	assert.NotContains(t, out.String(), "s3cr3t")
+}
+
+func TestCreateWizardPassSecretsInEnv(t *testing.T) {
+	defer os.Unsetenv("WIZARD_TEST_TOKEN")
+
+	wizard := newCreateWizard(&libmachinetest.FakeAPI{}, strings.NewReader(""), &bytes.Buffer{})
+	mcnFlags := []mcnflag.Flag{
+		mcnflag.StringFlag{Name: "digitalocean-access-token", EnvVar: "WIZARD_TEST_TOKEN"},
+		mcnflag.StringFlag{Name: "digitalocean-region", EnvVar: "WIZARD_TEST_REGION"},
+	}
+
+	args := wizard.passSecretsInEnv([]string{"--driver", "digitalocean", "--digitalocean-access-token", "s3cr3t", "--digitalocean-region=nyc3", "dev"}, mcnFlags)
+
+	assert.Equal(t, []string{"--driver", "digitalocean", "--digitalocean-region=nyc3", "dev"}, args)
+	assert.Equal(t, "s3cr3t", os.Getenv("WIZARD_TEST_TOKEN"))
+
+	args = wizard.passSecretsInEnv([]string{"--digitalocean-access-token=0th3r", "dev"}, mcnFlags)
+
+	assert.Equal(t, []string{"dev"}, args)
+	assert.Equal(t, "0th3r", os.Getenv("WIZARD_TEST_TOKEN"))
+}
+
+func TestCreateWizardAborted(t *testing.T) {
+	wizard := newCreateWizard(&libmachinetest.FakeAPI{}, strings.NewReader(""), &bytes.Buffer{})
+
+	_, err := wizard.run([]string{"--driver", "none"})
+
+	assert.Equal(t, errCreateWizardAborted, err)
+
+	wizard = newCreateWizard(&libmachinetest.FakeAPI{}, strings.NewReader("n\n"), &bytes.Buffer{})
+
+	assert.Equal(t, errCreateWizardAborted, wizard.confirm([]string{"docker-machine", "create", "dev"}))
+}
+
+func TestCreateWizardConfirmSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "create-dev")
+
+	out := &bytes.Buffer{}
+	wizard := newCreateWizard(&libmachinetest.FakeAPI{}, strings.NewReader("\n"+path+"\n"), out)
+
+	assert.NoError(t, wizard.confirm([]string{"docker-machine", "create", "--engine-label", "team=a b", "dev"}))
+	assert.Contains(t, out.String(), "docker-machine create --engine-label 'team=a b' dev")
+
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\ndocker-machine create --engine-label 'team=a b' dev \"$@\"\n", string(content))
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+}
+
+func TestCreateWizardConfirmSaveSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "create-dev")
+	assert.NoError(t, ioutil.WriteFile(path, []byte{}, 0755))
+
+	out := &bytes.Buffer{}
+	wizard := newCreateWizard(&libmachinetest.FakeAPI{}, strings.NewReader("\n"+path+"\n"), out)
+	wizard.secrets = []secretFlag{{"amazonec2-secret-key", "AWS_SECRET_ACCESS_KEY"}}
+
+	assert.NoError(t, wizard.confirm([]string{"docker-machine", "create", "--driver", "amazonec2", "dev"}))
+	assert.Contains(t, out.String(), "--amazonec2-secret-key is passed in AWS_SECRET_ACCESS_KEY")
+
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\n"+
+		": \"${AWS_SECRET_ACCESS_KEY:?has to be set for --amazonec2-secret-key}\"\n"+
+		"docker-machine create --driver amazonec2 dev \"$@\"\n", string(content))
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+}
+
+func TestParseCreateArgs(t *testing.T) {
+	flags, name := parseCreateArgs([]string{"-d", "none", "--swarm", "--engine-label=a=b", "dev"}, SharedCreateFlags)
+
+	assert.Equal(t, map[string]string{"driver": "none", "swarm": "", "engine-label": "a=b"}, flags)
+	assert.Equal(t, "dev", name)
+}
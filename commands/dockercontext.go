@@ -0,0 +1,293 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/classmarkets/docker-machine/libmachine"
+	"github.com/classmarkets/docker-machine/libmachine/auth"
+	"github.com/classmarkets/docker-machine/libmachine/check"
+	"github.com/classmarkets/docker-machine/libmachine/engine"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/log"
+	"github.com/classmarkets/docker-machine/libmachine/mcnutils"
+	"github.com/classmarkets/docker-machine/libmachine/persist"
+)
+
+const (
+	dockerContextMetaFile  = "meta.json"
+	dockerContextEndpoint  = "docker"
+	dockerContextReserved  = "default"
+	dockerContextDefaultAs = "docker-machine-default"
+)
+
+// dockerConfigDir is the configuration directory of the Docker CLI, where it
+// keeps the contexts.
+var dockerConfigDir = func() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+
+	return filepath.Join(mcnutils.GetHomeDir(), ".docker")
+}
+
+// dockerContextMeta is the meta.json of a Docker CLI context. The machine it
+// was written for and the machines directory of its store are recorded in
+// the metadata, only those contexts are updated or removed.
+type dockerContextMeta struct {
+	Name      string
+	Metadata  dockerContextMetadata
+	Endpoints map[string]dockerContextEndpointMeta
+}
+
+type dockerContextMetadata struct {
+	Description        string `json:",omitempty"`
+	DockerMachine      string `json:",omitempty"`
+	DockerMachineStore string `json:",omitempty"`
+}
+
+// writtenFor tells if the context was written for the machine of the store.
+// Those written before the store was recorded are taken as the machine's.
+func (m dockerContextMetadata) writtenFor(machinesDir, machineName string) bool {
+	return m.DockerMachine == machineName && (m.DockerMachineStore == "" || m.DockerMachineStore == dockerContextStore(machinesDir))
+}
+
+// dockerContextStore is how the machines directory is recorded in the
+// contexts, absolute so that it doesn't depend on the working directory.
+func dockerContextStore(machinesDir string) string {
+	if abs, err := filepath.Abs(machinesDir); err == nil {
+		return abs
+	}
+
+	return machinesDir
+}
+
+type dockerContextEndpointMeta struct {
+	Host          string
+	SkipTLSVerify bool
+}
+
+// dockerContextName is the name of the context of a machine. Docker reserves
+// the name default, the machine of that name gets a prefixed one.
+func dockerContextName(machineName string) string {
+	if machineName == dockerContextReserved {
+		return dockerContextDefaultAs
+	}
+
+	return machineName
+}
+
+// dockerContextDir returns the directory of a context in the meta or tls
+// store, which is named after the digest of the context name.
+func dockerContextDir(configDir, store, contextName string) string {
+	digest := sha256.Sum256([]byte(contextName))
+	return filepath.Join(configDir, "contexts", store, hex.EncodeToString(digest[:]))
+}
+
+func readDockerContextMeta(path string) (*dockerContextMeta, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &dockerContextMeta{}
+	if err := json.Unmarshal(content, meta); err != nil {
+		return nil, fmt.Errorf("Error reading the Docker context %s: %s", path, err)
+	}
+
+	return meta, nil
+}
+
+// writeDockerContext writes the context of the machine, with its TLS client
+// certificates unless the engine is reached over ssh.
+func writeDockerContext(configDir, machinesDir string, h *host.Host) error {
+	contextName := dockerContextName(h.Name)
+	metaDir := dockerContextDir(configDir, "meta", contextName)
+	tlsDir := dockerContextDir(configDir, "tls", contextName)
+
+	meta, err := readDockerContextMeta(filepath.Join(metaDir, dockerContextMetaFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil && !meta.Metadata.writtenFor(machinesDir, h.Name) {
+		return fmt.Errorf("The Docker context %q already exists and wasn't written for the machine %q", contextName, h.Name)
+	}
+
+	transport := engine.TransportTCP
+	if h.HostOptions != nil && h.HostOptions.EngineOptions != nil && h.HostOptions.EngineOptions.Transport != "" {
+		transport = h.HostOptions.EngineOptions.Transport
+	}
+
+	var dockerHost string
+	certs := map[string]string{}
+
+	if transport == engine.TransportSSH {
+		dockerHost, err = sshDockerHost(machinesDir, h)
+		if err != nil {
+			return err
+		}
+	} else {
+		var authOptions *auth.Options
+		dockerHost, authOptions, err = check.DefaultConnChecker.Check(h, false)
+		if err != nil {
+			return fmt.Errorf("Error checking TLS connection: %s", err)
+		}
+
+		certs["ca.pem"] = authOptions.CaCertPath
+		certs["cert.pem"] = authOptions.ClientCertPath
+		certs["key.pem"] = authOptions.ClientKeyPath
+	}
+
+	if err := os.RemoveAll(tlsDir); err != nil {
+		return err
+	}
+
+	if len(certs) > 0 {
+		endpointDir := filepath.Join(tlsDir, dockerContextEndpoint)
+		if err := os.MkdirAll(endpointDir, 0700); err != nil {
+			return err
+		}
+
+		for name, src := range certs {
+			if err := mcnutils.CopyFile(src, filepath.Join(endpointDir, name)); err != nil {
+				return fmt.Errorf("Error copying %s into the Docker context: %s", src, err)
+			}
+		}
+	}
+
+	content, err := json.Marshal(dockerContextMeta{
+		Name: contextName,
+		Metadata: dockerContextMetadata{
+			Description:        fmt.Sprintf("Docker Machine %s", h.Name),
+			DockerMachine:      h.Name,
+			DockerMachineStore: dockerContextStore(machinesDir),
+		},
+		Endpoints: map[string]dockerContextEndpointMeta{
+			dockerContextEndpoint: {
+				Host: dockerHost,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(metaDir, 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(metaDir, dockerContextMetaFile), content, 0644)
+}
+
+// removeDockerContext removes the context of the machine, if there's one
+// written for it.
+func removeDockerContext(configDir, machinesDir, machineName string) error {
+	contextName := dockerContextName(machineName)
+	metaDir := dockerContextDir(configDir, "meta", contextName)
+
+	meta, err := readDockerContextMeta(filepath.Join(metaDir, dockerContextMetaFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !meta.Metadata.writtenFor(machinesDir, machineName) {
+		return nil
+	}
+
+	if err := os.RemoveAll(dockerContextDir(configDir, "tls", contextName)); err != nil {
+		return err
+	}
+
+	return os.RemoveAll(metaDir)
+}
+
+// hasDockerContext tells if there's a context written for the machine.
+func hasDockerContext(configDir, machinesDir, machineName string) bool {
+	metaPath := filepath.Join(dockerContextDir(configDir, "meta", dockerContextName(machineName)), dockerContextMetaFile)
+
+	meta, err := readDockerContextMeta(metaPath)
+	return err == nil && meta.Metadata.writtenFor(machinesDir, machineName)
+}
+
+// dockerContextMachines returns the machines of the store which have a
+// context written. Those written before the store was recorded aren't
+// returned, they may belong to another store.
+func dockerContextMachines(configDir, machinesDir string) ([]string, error) {
+	metaRoot := filepath.Join(configDir, "contexts", "meta")
+
+	entries, err := ioutil.ReadDir(metaRoot)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	machines := []string{}
+	for _, entry := range entries {
+		meta, err := readDockerContextMeta(filepath.Join(metaRoot, entry.Name(), dockerContextMetaFile))
+		if err != nil || meta.Metadata.DockerMachine == "" || meta.Metadata.DockerMachineStore != dockerContextStore(machinesDir) {
+			continue
+		}
+		machines = append(machines, meta.Metadata.DockerMachine)
+	}
+
+	return machines, nil
+}
+
+// cmdContextSync writes the context of every machine in the store, and
+// removes those of the machines which no longer exist in it.
+func cmdContextSync(c CommandLine, api libmachine.API) error {
+	configDir := dockerConfigDir()
+
+	hosts, hostsInError, err := persist.LoadAllHosts(api)
+	if err != nil {
+		return err
+	}
+
+	for name, err := range hostsInError {
+		log.Warnf("Error loading machine %q: %s", name, err)
+	}
+
+	exists := map[string]bool{}
+	for name := range hostsInError {
+		exists[name] = true
+	}
+
+	for _, h := range hosts {
+		exists[h.Name] = true
+
+		if err := writeDockerContext(configDir, api.GetMachinesDir(), h); err != nil {
+			log.Warnf("Error writing the Docker context of %q: %s", h.Name, err)
+			continue
+		}
+
+		log.Infof("%s: Docker context %q written", h.Name, dockerContextName(h.Name))
+	}
+
+	machines, err := dockerContextMachines(configDir, api.GetMachinesDir())
+	if err != nil {
+		return err
+	}
+
+	for _, name := range machines {
+		if exists[name] {
+			continue
+		}
+
+		if err := removeDockerContext(configDir, api.GetMachinesDir(), name); err != nil {
+			return err
+		}
+
+		log.Infof("%s: Docker context %q removed", name, dockerContextName(name))
+	}
+
+	return nil
+}
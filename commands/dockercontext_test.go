@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/classmarkets/docker-machine/commands/commandstest"
+	"github.com/classmarkets/docker-machine/drivers/fakedriver"
+	"github.com/classmarkets/docker-machine/libmachine/auth"
+	"github.com/classmarkets/docker-machine/libmachine/check"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/libmachinetest"
+	"github.com/classmarkets/docker-machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDockerContextName(t *testing.T) {
+	assert.Equal(t, "dev", dockerContextName("dev"))
+	assert.Equal(t, "docker-machine-default", dockerContextName("default"))
+}
+
+func TestDockerContextDir(t *testing.T) {
+	// The digest of the context name, as the Docker CLI names it
+	assert.Equal(t,
+		filepath.Join("/config", "contexts", "meta", "ef260e9aa3c673af240d17a2660480361a8e081d1ffeca2a5ed0e3219fc18567"),
+		dockerContextDir("/config", "meta", "dev"))
+}
+
+func writeFakeCerts(t *testing.T, dir string) *auth.Options {
+	options := &auth.Options{
+		CaCertPath:     filepath.Join(dir, "ca.pem"),
+		ClientCertPath: filepath.Join(dir, "cert.pem"),
+		ClientKeyPath:  filepath.Join(dir, "key.pem"),
+	}
+
+	for _, path := range []string{options.CaCertPath, options.ClientCertPath, options.ClientKeyPath} {
+		assert.NoError(t, ioutil.WriteFile(path, []byte(filepath.Base(path)), 0600))
+	}
+
+	return options
+}
+
+func TestWriteAndRemoveDockerContext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	defer func(checker check.ConnChecker) { check.DefaultConnChecker = checker }(check.DefaultConnChecker)
+	check.DefaultConnChecker = &FakeConnChecker{
+		DockerHost:  "tcp://1.2.3.4:2376",
+		AuthOptions: writeFakeCerts(t, dir),
+	}
+
+	configDir := filepath.Join(dir, "docker")
+	h := &host.Host{Name: "dev"}
+
+	assert.NoError(t, writeDockerContext(configDir, dir, h))
+	assert.True(t, hasDockerContext(configDir, dir, "dev"))
+	assert.False(t, hasDockerContext(configDir, filepath.Join(dir, "other"), "dev"))
+
+	content, err := ioutil.ReadFile(filepath.Join(dockerContextDir(configDir, "meta", "dev"), "meta.json"))
+	assert.NoError(t, err)
+
+	meta := dockerContextMeta{}
+	assert.NoError(t, json.Unmarshal(content, &meta))
+	assert.Equal(t, "dev", meta.Name)
+	assert.Equal(t, "dev", meta.Metadata.DockerMachine)
+	assert.Equal(t, dir, meta.Metadata.DockerMachineStore)
+	assert.Equal(t, "tcp://1.2.3.4:2376", meta.Endpoints["docker"].Host)
+
+	cert, err := ioutil.ReadFile(filepath.Join(dockerContextDir(configDir, "tls", "dev"), "docker", "cert.pem"))
+	assert.NoError(t, err)
+	assert.Equal(t, "cert.pem", string(cert))
+
+	machines, err := dockerContextMachines(configDir, dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"dev"}, machines)
+
+	// Another store's machine of the same name doesn't own it
+	assert.NoError(t, removeDockerContext(configDir, filepath.Join(dir, "other"), "dev"))
+	assert.True(t, hasDockerContext(configDir, dir, "dev"))
+
+	assert.NoError(t, removeDockerContext(configDir, dir, "dev"))
+	assert.False(t, hasDockerContext(configDir, dir, "dev"))
+
+	_, err = os.Stat(dockerContextDir(configDir, "tls", "dev"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWriteDockerContextKeepsForeignContext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	metaDir := dockerContextDir(dir, "meta", "dev")
+	assert.NoError(t, os.MkdirAll(metaDir, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(metaDir, "meta.json"), []byte(`{"Name":"dev","Metadata":{},"Endpoints":{}}`), 0644))
+
+	err = writeDockerContext(dir, dir, &host.Host{Name: "dev"})
+	assert.EqualError(t, err, `The Docker context "dev" already exists and wasn't written for the machine "dev"`)
+
+	assert.NoError(t, removeDockerContext(dir, dir, "dev"))
+	_, err = os.Stat(filepath.Join(metaDir, "meta.json"))
+	assert.NoError(t, err)
+}
+
+func TestCmdContextSync(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	defer func(checker check.ConnChecker, configDir func() string) {
+		check.DefaultConnChecker = checker
+		dockerConfigDir = configDir
+	}(check.DefaultConnChecker, dockerConfigDir)
+	check.DefaultConnChecker = &FakeConnChecker{
+		DockerHost:  "tcp://1.2.3.4:2376",
+		AuthOptions: writeFakeCerts(t, dir),
+	}
+	configDir := filepath.Join(dir, "docker")
+	dockerConfigDir = func() string { return configDir }
+
+	assert.NoError(t, writeDockerContext(configDir, dir, &host.Host{Name: "removed"}))
+
+	// The contexts of another store, and those written before the store
+	// was recorded, are kept
+	otherDir := filepath.Join(dir, "other")
+	assert.NoError(t, writeDockerContext(configDir, otherDir, &host.Host{Name: "elsewhere"}))
+
+	legacyDir := dockerContextDir(configDir, "meta", "legacy")
+	assert.NoError(t, os.MkdirAll(legacyDir, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(legacyDir, "meta.json"), []byte(`{"Name":"legacy","Metadata":{"DockerMachine":"legacy"},"Endpoints":{}}`), 0644))
+
+	api := &libmachinetest.FakeAPI{
+		MachinesDir: dir,
+		Hosts: []*host.Host{
+			{
+				Name:   "dev",
+				Driver: &fakedriver.Driver{MockState: state.Running},
+			},
+			{
+				Name:   "default",
+				Driver: &fakedriver.Driver{MockState: state.Running},
+			},
+		},
+	}
+
+	err = cmdContextSync(&commandstest.FakeCommandLine{}, api)
+	assert.NoError(t, err)
+
+	assert.True(t, hasDockerContext(configDir, dir, "dev"))
+	assert.True(t, hasDockerContext(configDir, dir, "default"))
+	assert.False(t, hasDockerContext(configDir, dir, "removed"))
+	assert.True(t, hasDockerContext(configDir, otherDir, "elsewhere"))
+	assert.True(t, hasDockerContext(configDir, dir, "legacy"))
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -12,6 +13,7 @@ import (
 	"github.com/classmarkets/docker-machine/commands/mcndirs"
 	"github.com/classmarkets/docker-machine/libmachine"
 	"github.com/classmarkets/docker-machine/libmachine/check"
+	"github.com/classmarkets/docker-machine/libmachine/engine"
 	"github.com/classmarkets/docker-machine/libmachine/log"
 	"github.com/classmarkets/docker-machine/libmachine/shell"
 )
@@ -42,6 +44,9 @@ type ShellConfig struct {
 	NoProxyVar      string
 	NoProxyValue    string
 	ComposePathsVar bool
+
+	// render prints the configuration in place of envTmpl
+	render func(w io.Writer, shellCfg *ShellConfig) error
 }
 
 func cmdEnv(c CommandLine, api libmachine.API) error {
@@ -84,9 +89,9 @@ func shellCfgSet(c CommandLine, api libmachine.API) (*ShellConfig, error) {
 		return nil, err
 	}
 
-	dockerHost, _, err := check.DefaultConnChecker.Check(host, c.Bool("swarm"))
+	transport, err := dockerTransport(c, host)
 	if err != nil {
-		return nil, fmt.Errorf("Error checking TLS connection: %s", err)
+		return nil, err
 	}
 
 	userShell, err := getShell(c.String("shell"))
@@ -95,11 +100,29 @@ func shellCfgSet(c CommandLine, api libmachine.API) (*ShellConfig, error) {
 	}
 
 	shellCfg := &ShellConfig{
-		DockerCertPath:  filepath.Join(mcndirs.GetMachineDir(), host.Name),
-		DockerHost:      dockerHost,
-		DockerTLSVerify: "1",
-		UsageHint:       defaultUsageHinter.GenerateUsageHint(userShell, os.Args),
-		MachineName:     host.Name,
+		UsageHint:   defaultUsageHinter.GenerateUsageHint(userShell, os.Args),
+		MachineName: host.Name,
+	}
+
+	if transport == engine.TransportSSH {
+		if c.Bool("swarm") {
+			return nil, errSwarmOverSSH
+		}
+
+		// The TLS variables are emptied, the Docker client would otherwise
+		// use those of another machine
+		shellCfg.DockerHost, err = sshDockerHost(mcndirs.GetMachineDir(), host)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		shellCfg.DockerHost, _, err = check.DefaultConnChecker.Check(host, c.Bool("swarm"))
+		if err != nil {
+			return nil, fmt.Errorf("Error checking TLS connection: %s", err)
+		}
+
+		shellCfg.DockerCertPath = filepath.Join(mcndirs.GetMachineDir(), host.Name)
+		shellCfg.DockerTLSVerify = "1"
 	}
 
 	if c.Bool("no-proxy") {
@@ -128,32 +151,11 @@ func shellCfgSet(c CommandLine, api libmachine.API) (*ShellConfig, error) {
 		shellCfg.ComposePathsVar = true
 	}
 
-	switch userShell {
-	case "fish":
-		shellCfg.Prefix = "set -gx "
-		shellCfg.Suffix = "\";\n"
-		shellCfg.Delimiter = " \""
-	case "powershell":
-		shellCfg.Prefix = "$Env:"
-		shellCfg.Suffix = "\"\n"
-		shellCfg.Delimiter = " = \""
-	case "cmd":
-		shellCfg.Prefix = "SET "
-		shellCfg.Suffix = "\n"
-		shellCfg.Delimiter = "="
-	case "tcsh":
-		shellCfg.Prefix = "setenv "
-		shellCfg.Suffix = "\";\n"
-		shellCfg.Delimiter = " \""
-	case "emacs":
-		shellCfg.Prefix = "(setenv \""
-		shellCfg.Suffix = "\")\n"
-		shellCfg.Delimiter = "\" \""
-	default:
-		shellCfg.Prefix = "export "
-		shellCfg.Suffix = "\"\n"
-		shellCfg.Delimiter = "=\""
-	}
+	format := lookupShellFormat(userShell)
+	shellCfg.Prefix = format.set.Prefix
+	shellCfg.Delimiter = format.set.Delimiter
+	shellCfg.Suffix = format.set.Suffix
+	shellCfg.render = format.render
 
 	return shellCfg, nil
 }
@@ -168,6 +170,11 @@ func shellCfgUnset(c CommandLine, api libmachine.API) (*ShellConfig, error) {
 		return nil, err
 	}
 
+	format := lookupShellFormat(userShell)
+	if format.unsetErr != nil {
+		return nil, format.unsetErr
+	}
+
 	shellCfg := &ShellConfig{
 		UsageHint: defaultUsageHinter.GenerateUsageHint(userShell, os.Args),
 	}
@@ -176,37 +183,21 @@ func shellCfgUnset(c CommandLine, api libmachine.API) (*ShellConfig, error) {
 		shellCfg.NoProxyVar, shellCfg.NoProxyValue = findNoProxyFromEnv()
 	}
 
-	switch userShell {
-	case "fish":
-		shellCfg.Prefix = "set -e "
-		shellCfg.Suffix = ";\n"
-		shellCfg.Delimiter = ""
-	case "powershell":
-		shellCfg.Prefix = `Remove-Item Env:\\`
-		shellCfg.Suffix = "\n"
-		shellCfg.Delimiter = ""
-	case "cmd":
-		shellCfg.Prefix = "SET "
-		shellCfg.Suffix = "\n"
-		shellCfg.Delimiter = "="
-	case "emacs":
-		shellCfg.Prefix = "(setenv \""
-		shellCfg.Suffix = ")\n"
-		shellCfg.Delimiter = "\" nil"
-	case "tcsh":
-		shellCfg.Prefix = "unsetenv "
-		shellCfg.Suffix = ";\n"
-		shellCfg.Delimiter = ""
-	default:
-		shellCfg.Prefix = "unset "
-		shellCfg.Suffix = "\n"
-		shellCfg.Delimiter = ""
-	}
+	shellCfg.Prefix = format.unset.Prefix
+	shellCfg.Delimiter = format.unset.Delimiter
+	shellCfg.Suffix = format.unset.Suffix
+	shellCfg.render = format.renderUnset
 
 	return shellCfg, nil
 }
 
 func executeTemplateStdout(shellCfg *ShellConfig) error {
+	if shellCfg.render != nil {
+		// The output can't hold comments
+		fmt.Fprint(os.Stderr, shellCfg.UsageHint)
+		return shellCfg.render(os.Stdout, shellCfg)
+	}
+
 	t := template.New("envConfig")
 	tmpl, err := t.Parse(envTmpl)
 	if err != nil {
@@ -243,8 +234,10 @@ type UsageHintGenerator interface {
 type EnvUsageHintGenerator struct{}
 
 func (g *EnvUsageHintGenerator) GenerateUsageHint(userShell string, args []string) string {
-	cmd := ""
-	comment := "#"
+	format := lookupShellFormat(userShell)
+	if format.evalCommand == nil {
+		return ""
+	}
 
 	dockerMachinePath := args[0]
 	if strings.Contains(dockerMachinePath, " ") || strings.Contains(dockerMachinePath, `\`) {
@@ -252,24 +245,8 @@ func (g *EnvUsageHintGenerator) GenerateUsageHint(userShell string, args []strin
 	}
 
 	commandLine := strings.Join(args, " ")
-
-	switch userShell {
-	case "fish":
-		cmd = fmt.Sprintf("eval (%s)", commandLine)
-	case "powershell":
-		cmd = fmt.Sprintf("& %s | Invoke-Expression", commandLine)
-	case "cmd":
-		cmd = fmt.Sprintf("\t@FOR /f \"tokens=*\" %%i IN ('%s') DO @%%i", commandLine)
-		comment = "REM"
-	case "emacs":
-		cmd = fmt.Sprintf("(with-temp-buffer (shell-command \"%s\" (current-buffer)) (eval-buffer))", commandLine)
-		comment = ";;"
-	case "tcsh":
-		cmd = fmt.Sprintf("eval `%s`", commandLine)
-		comment = ":"
-	default:
-		cmd = fmt.Sprintf("eval $(%s)", commandLine)
-	}
+	cmd := format.evalCommand(commandLine)
+	comment := format.comment
 
 	return fmt.Sprintf("%s Run this command to configure your shell: \n%s %s\n", comment, comment, cmd)
 }
@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// shellAffixes print a variable as Prefix NAME Delimiter value Suffix.
+type shellAffixes struct {
+	Prefix    string
+	Delimiter string
+	Suffix    string
+}
+
+// shellFormat is how env prints the variables for a shell.
+type shellFormat struct {
+	set   shellAffixes
+	unset shellAffixes
+
+	// render prints the variables to set when the shell can't be given
+	// one per line with the affixes, the usage hint then goes to stderr
+	render func(w io.Writer, shellCfg *ShellConfig) error
+
+	// renderUnset is render for the variables to unset, if unsetting isn't
+	// supported the error is given instead
+	renderUnset func(w io.Writer, shellCfg *ShellConfig) error
+	unsetErr    error
+
+	// comment starts a comment line, evalCommand returns the command
+	// loading the output of the command line. There's no usage hint if
+	// it's nil.
+	comment     string
+	evalCommand func(commandLine string) string
+}
+
+// posixShellFormat is for the shells which aren't in shellFormats, like
+// bash, zsh and sh.
+var posixShellFormat = shellFormat{
+	set:         shellAffixes{Prefix: "export ", Delimiter: "=\"", Suffix: "\"\n"},
+	unset:       shellAffixes{Prefix: "unset ", Suffix: "\n"},
+	comment:     "#",
+	evalCommand: func(commandLine string) string { return fmt.Sprintf("eval $(%s)", commandLine) },
+}
+
+var shellFormats = map[string]shellFormat{
+	"fish": {
+		set:         shellAffixes{Prefix: "set -gx ", Delimiter: " \"", Suffix: "\";\n"},
+		unset:       shellAffixes{Prefix: "set -e ", Suffix: ";\n"},
+		comment:     "#",
+		evalCommand: func(commandLine string) string { return fmt.Sprintf("eval (%s)", commandLine) },
+	},
+	"powershell": {
+		set:         shellAffixes{Prefix: "$Env:", Delimiter: " = \"", Suffix: "\"\n"},
+		unset:       shellAffixes{Prefix: `Remove-Item Env:\\`, Suffix: "\n"},
+		comment:     "#",
+		evalCommand: func(commandLine string) string { return fmt.Sprintf("& %s | Invoke-Expression", commandLine) },
+	},
+	"cmd": {
+		set:     shellAffixes{Prefix: "SET ", Delimiter: "=", Suffix: "\n"},
+		unset:   shellAffixes{Prefix: "SET ", Delimiter: "=", Suffix: "\n"},
+		comment: "REM",
+		evalCommand: func(commandLine string) string {
+			return fmt.Sprintf("\t@FOR /f \"tokens=*\" %%i IN ('%s') DO @%%i", commandLine)
+		},
+	},
+	"tcsh": {
+		set:         shellAffixes{Prefix: "setenv ", Delimiter: " \"", Suffix: "\";\n"},
+		unset:       shellAffixes{Prefix: "unsetenv ", Suffix: ";\n"},
+		comment:     ":",
+		evalCommand: func(commandLine string) string { return fmt.Sprintf("eval `%s`", commandLine) },
+	},
+	"emacs": {
+		set:     shellAffixes{Prefix: "(setenv \"", Delimiter: "\" \"", Suffix: "\")\n"},
+		unset:   shellAffixes{Prefix: "(setenv \"", Delimiter: "\" nil", Suffix: ")\n"},
+		comment: ";;",
+		evalCommand: func(commandLine string) string {
+			return fmt.Sprintf("(with-temp-buffer (shell-command \"%s\" (current-buffer)) (eval-buffer))", commandLine)
+		},
+	},
+	// The values are raw strings, which keep the backslashes of Windows paths
+	"xonsh": {
+		set:         shellAffixes{Prefix: "$", Delimiter: " = r'", Suffix: "'\n"},
+		unset:       shellAffixes{Prefix: "del $", Suffix: "\n"},
+		comment:     "#",
+		evalCommand: func(commandLine string) string { return fmt.Sprintf("execx($(%s))", commandLine) },
+	},
+	"elvish": {
+		set:         shellAffixes{Prefix: "set-env ", Delimiter: " '", Suffix: "'\n"},
+		unset:       shellAffixes{Prefix: "unset-env ", Suffix: "\n"},
+		comment:     "#",
+		evalCommand: func(commandLine string) string { return fmt.Sprintf("eval (%s | slurp)", commandLine) },
+	},
+	// nushell can't evaluate a script, it loads a record of the variables
+	"nushell": {
+		render:      renderEnvJSON,
+		unsetErr:    errNushellUnset,
+		comment:     "#",
+		evalCommand: func(commandLine string) string { return fmt.Sprintf("%s | from json | load-env", commandLine) },
+	},
+	// dotenv is a file of KEY=value lines, e.g. for docker compose --env-file
+	"dotenv": {
+		set:   shellAffixes{Delimiter: "=", Suffix: "\n"},
+		unset: shellAffixes{Delimiter: "=", Suffix: "\n"},
+	},
+	"json": {
+		render:      renderEnvJSON,
+		renderUnset: renderEnvJSONUnset,
+	},
+}
+
+var errNushellUnset = fmt.Errorf("The variables can't be unset by nushell from the output of a command, run: hide-env %s", strings.Join(envVariableNames, " "))
+
+// envVariableNames are the variables always set by env.
+var envVariableNames = []string{"DOCKER_TLS_VERIFY", "DOCKER_HOST", "DOCKER_CERT_PATH", "DOCKER_MACHINE_NAME"}
+
+func lookupShellFormat(userShell string) shellFormat {
+	if format, ok := shellFormats[userShell]; ok {
+		return format
+	}
+
+	return posixShellFormat
+}
+
+// envVariable is a variable printed by env, Value is nil to unset it.
+type envVariable struct {
+	Name  string
+	Value *string
+}
+
+// variables returns the variables of the configuration, in the order of
+// envTmpl. They have no value when unsetting.
+func (shellCfg *ShellConfig) variables(unset bool) []envVariable {
+	names := append([]string{}, envVariableNames...)
+	values := []string{shellCfg.DockerTLSVerify, shellCfg.DockerHost, shellCfg.DockerCertPath, shellCfg.MachineName}
+
+	if shellCfg.ComposePathsVar {
+		names = append(names, "COMPOSE_CONVERT_WINDOWS_PATHS")
+		values = append(values, "true")
+	}
+
+	if shellCfg.NoProxyVar != "" {
+		names = append(names, shellCfg.NoProxyVar)
+		values = append(values, shellCfg.NoProxyValue)
+	}
+
+	variables := []envVariable{}
+	for i, name := range names {
+		variable := envVariable{Name: name}
+		if !unset {
+			variable.Value = &values[i]
+		}
+		variables = append(variables, variable)
+	}
+
+	return variables
+}
+
+func renderEnvJSON(w io.Writer, shellCfg *ShellConfig) error {
+	return writeEnvJSON(w, shellCfg.variables(false))
+}
+
+func renderEnvJSONUnset(w io.Writer, shellCfg *ShellConfig) error {
+	return writeEnvJSON(w, shellCfg.variables(true))
+}
+
+// writeEnvJSON prints the variables as a JSON object, those to unset are
+// null.
+func writeEnvJSON(w io.Writer, variables []envVariable) error {
+	fields := []string{}
+	for _, variable := range variables {
+		name, err := json.Marshal(variable.Name)
+		if err != nil {
+			return err
+		}
+
+		value, err := json.Marshal(variable.Value)
+		if err != nil {
+			return err
+		}
+
+		fields = append(fields, fmt.Sprintf("  %s: %s", name, value))
+	}
+
+	_, err := fmt.Fprintf(w, "{\n%s\n}\n", strings.Join(fields, ",\n"))
+	return err
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"testing"
@@ -83,6 +84,12 @@ func TestHints(t *testing.T) {
 		{"tcsh", []string{"./machine", "env", "--shell=tcsh", "--swarm", "default"}, ": Run this command to configure your shell: \n: eval `./machine env --shell=tcsh --swarm default`\n"},
 		{"tcsh", []string{"./machine", "env", "--shell=tcsh", "--no-proxy", "--swarm", "default"}, ": Run this command to configure your shell: \n: eval `./machine env --shell=tcsh --no-proxy --swarm default`\n"},
 		{"tcsh", []string{"./machine", "env", "--shell=tcsh", "--unset"}, ": Run this command to configure your shell: \n: eval `./machine env --shell=tcsh --unset`\n"},
+
+		{"xonsh", []string{"./machine", "env", "--shell=xonsh", "default"}, "# Run this command to configure your shell: \n# execx($(./machine env --shell=xonsh default))\n"},
+		{"elvish", []string{"./machine", "env", "--shell=elvish", "default"}, "# Run this command to configure your shell: \n# eval (./machine env --shell=elvish default | slurp)\n"},
+		{"nushell", []string{"./machine", "env", "--shell=nushell", "default"}, "# Run this command to configure your shell: \n# ./machine env --shell=nushell default | from json | load-env\n"},
+		{"dotenv", []string{"./machine", "env", "--shell=dotenv", "default"}, ""},
+		{"json", []string{"./machine", "env", "--shell=json", "default"}, ""},
 	}
 
 	for _, test := range tests {
@@ -686,3 +693,97 @@ func TestShellCfgUnset(t *testing.T) {
 		os.Setenv(test.noProxyVar, "")
 	}
 }
+
+func TestExecuteTemplateStdoutDotenv(t *testing.T) {
+	shellCfg := &ShellConfig{
+		Delimiter:       "=",
+		Suffix:          "\n",
+		DockerCertPath:  "/machines/quux",
+		DockerHost:      "tcp://1.2.3.4:2376",
+		DockerTLSVerify: "1",
+		MachineName:     "quux",
+	}
+
+	stdoutGetter := commandstest.NewStdoutGetter()
+	defer stdoutGetter.Stop()
+
+	assert.NoError(t, executeTemplateStdout(shellCfg))
+	assert.Equal(t, "DOCKER_TLS_VERIFY=1\nDOCKER_HOST=tcp://1.2.3.4:2376\nDOCKER_CERT_PATH=/machines/quux\nDOCKER_MACHINE_NAME=quux\n", stdoutGetter.Output())
+}
+
+func TestExecuteTemplateStdoutWindowsPath(t *testing.T) {
+	var tests = []struct {
+		shell    string
+		expected string
+	}{
+		{"xonsh", "$DOCKER_TLS_VERIFY = r'1'\n$DOCKER_HOST = r'tcp://1.2.3.4:2376'\n$DOCKER_CERT_PATH = r'C:\\Users\\me\\.docker\\machine\\machines\\quux'\n$DOCKER_MACHINE_NAME = r'quux'\n"},
+		{"elvish", "set-env DOCKER_TLS_VERIFY '1'\nset-env DOCKER_HOST 'tcp://1.2.3.4:2376'\nset-env DOCKER_CERT_PATH 'C:\\Users\\me\\.docker\\machine\\machines\\quux'\nset-env DOCKER_MACHINE_NAME 'quux'\n"},
+	}
+
+	for _, test := range tests {
+		format := lookupShellFormat(test.shell)
+		shellCfg := &ShellConfig{
+			Prefix:          format.set.Prefix,
+			Delimiter:       format.set.Delimiter,
+			Suffix:          format.set.Suffix,
+			DockerCertPath:  `C:\Users\me\.docker\machine\machines\quux`,
+			DockerHost:      "tcp://1.2.3.4:2376",
+			DockerTLSVerify: "1",
+			MachineName:     "quux",
+		}
+
+		stdoutGetter := commandstest.NewStdoutGetter()
+
+		assert.NoError(t, executeTemplateStdout(shellCfg))
+		assert.Equal(t, test.expected, stdoutGetter.Output(), test.shell)
+
+		stdoutGetter.Stop()
+	}
+}
+
+func TestRenderEnvJSON(t *testing.T) {
+	shellCfg := &ShellConfig{
+		DockerCertPath:  `C:\machines\quux`,
+		DockerHost:      "tcp://1.2.3.4:2376",
+		DockerTLSVerify: "1",
+		MachineName:     "quux",
+		NoProxyVar:      "NO_PROXY",
+		NoProxyValue:    "1.2.3.4",
+	}
+
+	output := &bytes.Buffer{}
+	assert.NoError(t, renderEnvJSON(output, shellCfg))
+	assert.Equal(t, `{
+  "DOCKER_TLS_VERIFY": "1",
+  "DOCKER_HOST": "tcp://1.2.3.4:2376",
+  "DOCKER_CERT_PATH": "C:\\machines\\quux",
+  "DOCKER_MACHINE_NAME": "quux",
+  "NO_PROXY": "1.2.3.4"
+}
+`, output.String())
+
+	output.Reset()
+	assert.NoError(t, renderEnvJSONUnset(output, &ShellConfig{}))
+	assert.Equal(t, `{
+  "DOCKER_TLS_VERIFY": null,
+  "DOCKER_HOST": null,
+  "DOCKER_CERT_PATH": null,
+  "DOCKER_MACHINE_NAME": null
+}
+`, output.String())
+}
+
+func TestShellCfgUnsetNushell(t *testing.T) {
+	commandLine := &commandstest.FakeCommandLine{
+		LocalFlags: &commandstest.FakeFlagger{
+			Data: map[string]interface{}{
+				"shell": "nushell",
+			},
+		},
+	}
+
+	shellCfg, err := shellCfgUnset(commandLine, &libmachinetest.FakeAPI{})
+
+	assert.Nil(t, shellCfg)
+	assert.Equal(t, errNushellUnset, err)
+}
@@ -0,0 +1,250 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/classmarkets/docker-machine/libmachine"
+	"github.com/classmarkets/docker-machine/libmachine/check"
+	"github.com/classmarkets/docker-machine/libmachine/filter"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/persist"
+	"github.com/classmarkets/docker-machine/libmachine/state"
+)
+
+const eventsDefaultInterval = 5
+
+// machineEvent is a change of a machine seen between two polls. The first
+// poll reports the state of each machine, without From.
+type machineEvent struct {
+	Time    time.Time `json:"time"`
+	Machine string    `json:"machine"`
+	// Type is state, docker, created or removed
+	Type  string `json:"type"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+func (e machineEvent) String() string {
+	switch e.Type {
+	case "docker":
+		return fmt.Sprintf("%s: docker %s", e.Machine, e.To)
+	case "created":
+		return fmt.Sprintf("%s: created (%s)", e.Machine, e.To)
+	case "removed":
+		return fmt.Sprintf("%s: removed", e.Machine)
+	}
+
+	if e.From == "" {
+		return fmt.Sprintf("%s: %s", e.Machine, e.To)
+	}
+
+	return fmt.Sprintf("%s: %s -> %s", e.Machine, e.From, e.To)
+}
+
+// machineStatus is what events compares between two polls of a machine.
+// Whether Docker is reachable is only checked on the running machines
+// matching the filters.
+type machineStatus struct {
+	State           state.State
+	Error           string
+	DockerReachable bool
+	DockerError     string
+
+	// Matches tells if the machine matches the filters
+	Matches bool
+}
+
+func cmdEvents(c CommandLine, api libmachine.API) error {
+	if len(c.Args()) > 0 {
+		c.ShowHelp()
+		return ErrTooManyArguments
+	}
+
+	filters, err := filter.Parse(c.StringSlice("filter"))
+	if err != nil {
+		return err
+	}
+
+	var tmpl *template.Template
+	if format := c.String("format"); format != "" {
+		if tmpl, err = template.New("").Funcs(lsFuncMap).Parse(format); err != nil {
+			return fmt.Errorf("Template parsing error: %v", err)
+		}
+	}
+
+	interval := eventsDefaultInterval
+	if c.IsSet("interval") {
+		interval = c.Int("interval")
+	}
+	if interval < 1 {
+		return fmt.Errorf("Invalid interval %d, it must be at least 1 second", interval)
+	}
+
+	timeout := time.Duration(c.Int("timeout")) * time.Second
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	defer signal.Stop(stop)
+
+	previous := map[string]machineStatus(nil)
+	for {
+		current, err := pollMachineStatuses(api, filters, timeout)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range diffMachineStatuses(previous, current, time.Now()) {
+			if err := writeMachineEvent(os.Stdout, tmpl, event); err != nil {
+				return err
+			}
+		}
+		previous = current
+
+		select {
+		case <-time.After(time.Duration(interval) * time.Second):
+		case <-stop:
+			return nil
+		}
+	}
+}
+
+// writeMachineEvent writes the event on a line, formatted with tmpl if set.
+func writeMachineEvent(out io.Writer, tmpl *template.Template, event machineEvent) error {
+	if tmpl == nil {
+		_, err := fmt.Fprintln(out, event)
+		return err
+	}
+
+	if err := tmpl.Execute(out, event); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintln(out)
+	return err
+}
+
+// pollMachineStatuses queries the state of all the machines the way ls
+// does, so that a machine which stops matching the filters isn't taken as
+// removed, and tells which ones match the filters. Then it checks the
+// Docker connection of those matching and running. The machines which
+// can't be loaded always match.
+func pollMachineStatuses(api libmachine.API, filters filter.Options, timeout time.Duration) (map[string]machineStatus, error) {
+	hostList, hostInError, err := persist.LoadAllHosts(api)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := map[string]*host.Host{}
+	for _, h := range filter.Hosts(hostList, filters) {
+		hosts[h.Name] = h
+	}
+
+	statuses := map[string]machineStatus{}
+	checks := map[string]chan error{}
+
+	for _, item := range getHostListItems(hostList, hostInError, timeout, false) {
+		h, matches := hosts[item.Name]
+		if _, inError := hostInError[item.Name]; inError {
+			matches = true
+		}
+
+		statuses[item.Name] = machineStatus{
+			State:   item.State,
+			Error:   item.Error,
+			Matches: matches,
+		}
+
+		if h != nil && item.State == state.Running {
+			checks[item.Name] = make(chan error, 1)
+			go func(h *host.Host, errs chan<- error) {
+				_, _, err := check.DefaultConnChecker.Check(h, false)
+				errs <- err
+			}(h, checks[item.Name])
+		}
+	}
+
+	for name, errs := range checks {
+		status := statuses[name]
+		if err := <-errs; err != nil {
+			status.DockerError = strings.TrimSpace(err.Error())
+		} else {
+			status.DockerReachable = true
+		}
+		statuses[name] = status
+	}
+
+	return statuses, nil
+}
+
+// diffMachineStatuses returns the events leading from the previous statuses
+// to the current ones, by machine name. Only the events of the machines
+// matching the filters in either poll are returned. Without previous
+// statuses, the current state of each machine is reported. Docker becoming
+// reachable or unreachable is reported while the machine stays running and
+// matching, and Docker being unreachable when the machine is first seen
+// running.
+func diffMachineStatuses(previous, current map[string]machineStatus, now time.Time) []machineEvent {
+	names := []string{}
+	for name := range current {
+		names = append(names, name)
+	}
+	for name := range previous {
+		if _, ok := current[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	events := []machineEvent{}
+	for _, name := range names {
+		before, existed := previous[name]
+		after, exists := current[name]
+
+		if !before.Matches && !after.Matches {
+			continue
+		}
+
+		switch {
+		case !exists:
+			events = append(events, machineEvent{Time: now, Machine: name, Type: "removed"})
+			continue
+		case !existed && previous != nil:
+			events = append(events, machineEvent{Time: now, Machine: name, Type: "created", To: after.State.String(), Error: after.Error})
+		case !existed:
+			events = append(events, machineEvent{Time: now, Machine: name, Type: "state", To: after.State.String(), Error: after.Error})
+		case before.State != after.State:
+			events = append(events, machineEvent{Time: now, Machine: name, Type: "state", From: before.State.String(), To: after.State.String(), Error: after.Error})
+		}
+
+		// Docker isn't checked on the machines which don't match anymore
+		if after.State != state.Running || !after.Matches {
+			continue
+		}
+
+		if existed && before.State == state.Running && before.Matches {
+			if before.DockerReachable == after.DockerReachable {
+				continue
+			}
+		} else if after.DockerReachable {
+			// A machine seen running is expected to be reachable
+			continue
+		}
+
+		event := machineEvent{Time: now, Machine: name, Type: "docker", To: "reachable"}
+		if !after.DockerReachable {
+			event.To = "unreachable"
+			event.Error = after.DockerError
+		}
+		events = append(events, event)
+	}
+
+	return events
+}
@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/classmarkets/docker-machine/drivers/fakedriver"
+	"github.com/classmarkets/docker-machine/libmachine/check"
+	"github.com/classmarkets/docker-machine/libmachine/filter"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/libmachinetest"
+	"github.com/classmarkets/docker-machine/libmachine/mcndockerclient"
+	"github.com/classmarkets/docker-machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func eventLines(events []machineEvent) []string {
+	lines := []string{}
+	for _, event := range events {
+		lines = append(lines, event.String())
+	}
+	return lines
+}
+
+func TestDiffMachineStatuses(t *testing.T) {
+	now := time.Now()
+
+	initial := map[string]machineStatus{
+		"dev":      {State: state.Stopped, Matches: true},
+		"worker-3": {State: state.Running, DockerReachable: true, Matches: true},
+		"worker-4": {State: state.Running, DockerError: "connection refused", Matches: true},
+		"old":      {State: state.Running, DockerReachable: true, Matches: true},
+	}
+
+	assert.Equal(t, []string{
+		"dev: Stopped",
+		"old: Running",
+		"worker-3: Running",
+		"worker-4: Running",
+		"worker-4: docker unreachable",
+	}, eventLines(diffMachineStatuses(nil, initial, now)))
+
+	current := map[string]machineStatus{
+		"dev":      {State: state.Running, DockerReachable: true, Matches: true},
+		"worker-3": {State: state.Running, DockerError: "connection refused", Matches: true},
+		"worker-4": {State: state.Running, DockerReachable: true, Matches: true},
+		"new":      {State: state.Stopped, Matches: true},
+	}
+
+	events := diffMachineStatuses(initial, current, now)
+
+	assert.Equal(t, []string{
+		"dev: Stopped -> Running",
+		"new: created (Stopped)",
+		"old: removed",
+		"worker-3: docker unreachable",
+		"worker-4: docker reachable",
+	}, eventLines(events))
+	assert.Equal(t, "connection refused", events[3].Error)
+	assert.Equal(t, now, events[3].Time)
+
+	assert.Empty(t, diffMachineStatuses(current, current, now))
+}
+
+func TestDiffMachineStatusesFiltered(t *testing.T) {
+	now := time.Now()
+
+	// Filtered with state=running
+	initial := map[string]machineStatus{
+		"dev":    {State: state.Running, DockerReachable: true, Matches: true},
+		"worker": {State: state.Stopped},
+		"idle":   {State: state.Stopped},
+	}
+
+	assert.Equal(t, []string{
+		"dev: Running",
+	}, eventLines(diffMachineStatuses(nil, initial, now)))
+
+	current := map[string]machineStatus{
+		"dev":    {State: state.Stopped},
+		"worker": {State: state.Running, DockerError: "connection refused", Matches: true},
+		"new":    {State: state.Stopped},
+	}
+
+	// Only the machines leaving or entering the store are created or
+	// removed
+	assert.Equal(t, []string{
+		"dev: Running -> Stopped",
+		"worker: Stopped -> Running",
+		"worker: docker unreachable",
+	}, eventLines(diffMachineStatuses(initial, current, now)))
+
+	removed := map[string]machineStatus{
+		"new": {State: state.Running, DockerReachable: true, Matches: true},
+	}
+
+	assert.Equal(t, []string{
+		"new: Stopped -> Running",
+		"worker: removed",
+	}, eventLines(diffMachineStatuses(current, removed, now)))
+}
+
+func TestWriteMachineEvent(t *testing.T) {
+	event := machineEvent{
+		Time:    time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC),
+		Machine: "dev",
+		Type:    "state",
+		From:    "Stopped",
+		To:      "Running",
+	}
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, writeMachineEvent(out, nil, event))
+
+	tmpl := template.Must(template.New("").Funcs(lsFuncMap).Parse("{{ json . }}"))
+	assert.NoError(t, writeMachineEvent(out, tmpl, event))
+
+	assert.Equal(t, "dev: Stopped -> Running\n"+
+		`{"time":"2016-01-02T03:04:05Z","machine":"dev","type":"state","from":"Stopped","to":"Running"}`+"\n", out.String())
+}
+
+func TestPollMachineStatuses(t *testing.T) {
+	defer func(versioner mcndockerclient.DockerVersioner) { mcndockerclient.CurrentDockerVersioner = versioner }(mcndockerclient.CurrentDockerVersioner)
+	mcndockerclient.CurrentDockerVersioner = &mcndockerclient.FakeDockerVersioner{Version: "1.9"}
+
+	defer func(checker check.ConnChecker) { check.DefaultConnChecker = checker }(check.DefaultConnChecker)
+	check.DefaultConnChecker = &FakeConnChecker{Err: errors.New("connection refused")}
+
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name:   "dev",
+				Driver: &fakedriver.Driver{MockState: state.Running, MockIP: "192.168.99.100", MockName: "dev"},
+			},
+			{
+				Name:   "test",
+				Driver: &fakedriver.Driver{MockState: state.Stopped, MockName: "test"},
+			},
+			{
+				Name:   "other",
+				Driver: &fakedriver.Driver{MockState: state.Stopped, MockName: "other"},
+			},
+		},
+	}
+
+	filters, err := filter.Parse([]string{"name=^(dev|test)$"})
+	assert.NoError(t, err)
+
+	statuses, err := pollMachineStatuses(api, filters, 10*time.Second)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]machineStatus{
+		"dev":   {State: state.Running, DockerError: "connection refused", Matches: true},
+		"test":  {State: state.Stopped, Matches: true},
+		"other": {State: state.Stopped},
+	}, statuses)
+}
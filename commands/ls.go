@@ -1,11 +1,15 @@
 package commands
 
 import (
-	"errors"
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"net"
 	"os"
+	"os/signal"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 	"text/template"
@@ -14,21 +18,27 @@ import (
 	"io"
 
 	"github.com/classmarkets/docker-machine/libmachine"
+	"github.com/classmarkets/docker-machine/libmachine/cert"
 	"github.com/classmarkets/docker-machine/libmachine/drivers"
 	"github.com/classmarkets/docker-machine/libmachine/engine"
+	"github.com/classmarkets/docker-machine/libmachine/filter"
 	"github.com/classmarkets/docker-machine/libmachine/host"
 	"github.com/classmarkets/docker-machine/libmachine/log"
 	"github.com/classmarkets/docker-machine/libmachine/mcndockerclient"
 	"github.com/classmarkets/docker-machine/libmachine/persist"
+	"github.com/classmarkets/docker-machine/libmachine/provision"
 	"github.com/classmarkets/docker-machine/libmachine/state"
 	"github.com/classmarkets/docker-machine/libmachine/swarm"
+	"github.com/docker/go-units"
 	"github.com/skarademir/naturalsort"
 )
 
 const (
-	lsDefaultTimeout = 10
-	tableFormatKey   = "table"
-	lsDefaultFormat  = "table {{ .Name }}\t{{ .Active }}\t{{ .DriverName}}\t{{ .State }}\t{{ .URL }}\t{{ .Swarm }}\t{{ .DockerVersion }}\t{{ .Error}}"
+	lsDefaultTimeout  = 10
+	lsDefaultInterval = 5
+	lsClearScreen     = "\033[H\033[2J"
+	tableFormatKey    = "table"
+	lsDefaultFormat   = "table {{ .Name }}\t{{ .Active }}\t{{ .DriverName}}\t{{ .State }}\t{{ .URL }}\t{{ .Swarm }}\t{{ .DockerVersion }}\t{{ .Error}}"
 )
 
 var (
@@ -46,6 +56,57 @@ var (
 		"Error":         "ERRORS",
 		"DockerVersion": "DOCKER",
 		"ResponseTime":  "RESPONSE",
+		"IP":            "IP",
+		"SSHAddress":    "SSH",
+		"Created":       "CREATED",
+		"CPUs":          "CPUS",
+		"Memory":        "MEMORY",
+		"DiskSize":      "DISK",
+		"OS":            "OS",
+		"CertExpiry":    "CERT_EXPIRY",
+	}
+
+	// lsFuncMap are the functions of the ls templates. The header row of a
+	// table is rendered with the same template from strings, which the
+	// functions leave as they are.
+	lsFuncMap = template.FuncMap{
+		"json":          funcMap["json"],
+		"prettyjson":    funcMap["prettyjson"],
+		"upper":         func(v interface{}) string { return strings.ToUpper(fmt.Sprint(v)) },
+		"lower":         func(v interface{}) string { return strings.ToLower(fmt.Sprint(v)) },
+		"humanDuration": humanDuration,
+		"humanSize":     humanSize,
+	}
+
+	// lsNeedsOS matches the formats showing the OS of the machines, which
+	// is only read over SSH then.
+	lsNeedsOS = regexp.MustCompile(`\.OS\b|json`)
+
+	// lsSortKeys compare the machines for --sort, they're sorted by name
+	// when they're equal.
+	lsSortKeys = map[string]func(a, b HostListItem) bool{
+		"active":      func(a, b HostListItem) bool { return a.Active > b.Active },
+		"driver":      func(a, b HostListItem) bool { return a.DriverName < b.DriverName },
+		"state":       func(a, b HostListItem) bool { return a.State.String() < b.State.String() },
+		"url":         func(a, b HostListItem) bool { return a.URL < b.URL },
+		"ip":          func(a, b HostListItem) bool { return compareIPs(a.IP, b.IP) < 0 },
+		"created":     func(a, b HostListItem) bool { return a.Created.Before(b.Created) },
+		"cpus":        func(a, b HostListItem) bool { return a.CPUs < b.CPUs },
+		"memory":      func(a, b HostListItem) bool { return a.Memory < b.Memory },
+		"disk":        func(a, b HostListItem) bool { return a.DiskSize < b.DiskSize },
+		"os":          func(a, b HostListItem) bool { return a.OS < b.OS },
+		"cert-expiry": func(a, b HostListItem) bool { return a.CertExpiry.Before(b.CertExpiry) },
+		"docker":      func(a, b HostListItem) bool { return a.DockerVersion < b.DockerVersion },
+		"response":    func(a, b HostListItem) bool { return a.ResponseTime < b.ResponseTime },
+	}
+
+	// diskSizeInGB are the drivers which record the disk size in GB, the
+	// others record it in MB.
+	diskSizeInGB = map[string]bool{
+		"amazonec2": true,
+		"exoscale":  true,
+		"google":    true,
+		"softlayer": true,
 	}
 )
 
@@ -63,46 +124,117 @@ type HostListItem struct {
 	Error         string
 	DockerVersion string
 	ResponseTime  time.Duration
+	IP            string
+	SSHAddress    string
+	Created       time.Time
+	CPUs          int
+	Memory        int
+	DiskSize      int
+	OS            string
+	CertExpiry    time.Time
 }
 
-// FilterOptions -
-type FilterOptions struct {
-	SwarmName  []string
-	DriverName []string
-	State      []string
-	Name       []string
-	Labels     []string
+// lsOptions are what ls renders the machines with, read from the flags.
+type lsOptions struct {
+	filters  filter.Options
+	quiet    bool
+	template *template.Template
+	table    bool
+	timeout  time.Duration
+	fetchOS  bool
+	sortKey  string
 }
 
 func cmdLs(c CommandLine, api libmachine.API) error {
-	filters, err := parseFilters(c.StringSlice("filter"))
+	filters, err := filter.Parse(c.StringSlice("filter"))
 	if err != nil {
 		return err
 	}
 
+	sortKey := c.String("sort")
+	if _, ok := lsSortKeys[strings.TrimPrefix(sortKey, "-")]; !ok && strings.TrimPrefix(sortKey, "-") != "name" && sortKey != "" {
+		return fmt.Errorf("Unsupported sort key %q", sortKey)
+	}
+
+	options := lsOptions{
+		filters: filters,
+		quiet:   c.Bool("quiet"),
+		timeout: time.Duration(c.Int("timeout")) * time.Second,
+		fetchOS: lsNeedsOS.MatchString(c.String("format")) || strings.TrimPrefix(sortKey, "-") == "os",
+		sortKey: sortKey,
+	}
+
+	if !options.quiet {
+		options.template, options.table, err = parseFormat(c.String("format"))
+		if err != nil {
+			return err
+		}
+	}
+
+	if !c.Bool("watch") {
+		return listHosts(os.Stdout, api, options)
+	}
+
+	interval := lsDefaultInterval
+	if c.IsSet("interval") {
+		interval = c.Int("interval")
+	}
+	if interval < 1 {
+		return fmt.Errorf("Invalid interval %d, it must be at least 1 second", interval)
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	defer signal.Stop(stop)
+
+	return watchHosts(os.Stdout, api, options, time.Duration(interval)*time.Second, stop)
+}
+
+// watchHosts lists the machines every interval until stop receives. Each
+// list is rendered before the screen is cleared, so that it's redrawn in
+// place without flickering while the machines are queried.
+func watchHosts(out io.Writer, api libmachine.API, options lsOptions, interval time.Duration, stop <-chan os.Signal) error {
+	for {
+		list := &bytes.Buffer{}
+		if err := listHosts(list, api, options); err != nil {
+			return err
+		}
+
+		fmt.Fprint(out, lsClearScreen)
+		if _, err := list.WriteTo(out); err != nil {
+			return err
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-stop:
+			return nil
+		}
+	}
+}
+
+// listHosts writes the machines matching the filters to out.
+func listHosts(out io.Writer, api libmachine.API, options lsOptions) error {
 	hostList, hostInError, err := persist.LoadAllHosts(api)
 	if err != nil {
 		return err
 	}
 
-	hostList = filterHosts(hostList, filters)
+	hostList = filter.Hosts(hostList, options.filters)
 
 	// Just print out the names if we're being quiet
-	if c.Bool("quiet") {
+	if options.quiet {
 		for _, host := range hostList {
-			fmt.Println(host.Name)
+			fmt.Fprintln(out, host.Name)
 		}
 		return nil
 	}
 
-	template, table, err := parseFormat(c.String("format"))
-	if err != nil {
-		return err
-	}
+	template := options.template
 
 	var w io.Writer
-	if table {
-		tabWriter := tabwriter.NewWriter(os.Stdout, 5, 1, 3, ' ', 0)
+	if options.table {
+		tabWriter := tabwriter.NewWriter(out, 5, 1, 3, ' ', 0)
 		defer tabWriter.Flush()
 
 		w = tabWriter
@@ -111,11 +243,11 @@ func cmdLs(c CommandLine, api libmachine.API) error {
 			return err
 		}
 	} else {
-		w = os.Stdout
+		w = out
 	}
 
-	timeout := time.Duration(c.Int("timeout")) * time.Second
-	items := getHostListItems(hostList, hostInError, timeout)
+	items := getHostListItems(hostList, hostInError, options.timeout, options.fetchOS)
+	sortHostListItems(items, options.sortKey)
 
 	swarmMasters := make(map[string]string)
 	swarmInfo := make(map[string]string)
@@ -168,7 +300,7 @@ func parseFormat(format string) (*template.Template, bool, error) {
 	r := strings.NewReplacer(`\t`, "\t", `\n`, "\n")
 	finalFormat = r.Replace(finalFormat)
 
-	template, err := template.New("").Parse(finalFormat + "\n")
+	template, err := template.New("").Funcs(lsFuncMap).Parse(finalFormat + "\n")
 	if err != nil {
 		return nil, false, err
 	}
@@ -176,162 +308,7 @@ func parseFormat(format string) (*template.Template, bool, error) {
 	return template, table, nil
 }
 
-func parseFilters(filters []string) (FilterOptions, error) {
-	options := FilterOptions{}
-	for _, f := range filters {
-		kv := strings.SplitN(f, "=", 2)
-		if len(kv) != 2 {
-			return options, errors.New("Unsupported filter syntax")
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
-
-		switch key {
-		case "swarm":
-			options.SwarmName = append(options.SwarmName, value)
-		case "driver":
-			options.DriverName = append(options.DriverName, value)
-		case "state":
-			options.State = append(options.State, value)
-		case "name":
-			options.Name = append(options.Name, value)
-		case "label":
-			options.Labels = append(options.Labels, value)
-		default:
-			return options, fmt.Errorf("Unsupported filter key '%s'", key)
-		}
-	}
-	return options, nil
-}
-
-func filterHosts(hosts []*host.Host, filters FilterOptions) []*host.Host {
-	if len(filters.SwarmName) == 0 &&
-		len(filters.DriverName) == 0 &&
-		len(filters.State) == 0 &&
-		len(filters.Name) == 0 &&
-		len(filters.Labels) == 0 {
-		return hosts
-	}
-
-	filteredHosts := []*host.Host{}
-	swarmMasters := getSwarmMasters(hosts)
-
-	for _, h := range hosts {
-		if filterHost(h, filters, swarmMasters) {
-			filteredHosts = append(filteredHosts, h)
-		}
-	}
-	return filteredHosts
-}
-
-func getSwarmMasters(hosts []*host.Host) map[string]string {
-	swarmMasters := make(map[string]string)
-	for _, h := range hosts {
-		if h.HostOptions != nil {
-			swarmOptions := h.HostOptions.SwarmOptions
-			if swarmOptions != nil && swarmOptions.Master {
-				swarmMasters[swarmOptions.Discovery] = h.Name
-			}
-		}
-	}
-	return swarmMasters
-}
-
-func filterHost(host *host.Host, filters FilterOptions, swarmMasters map[string]string) bool {
-	swarmMatches := matchesSwarmName(host, filters.SwarmName, swarmMasters)
-	driverMatches := matchesDriverName(host, filters.DriverName)
-	stateMatches := matchesState(host, filters.State)
-	nameMatches := matchesName(host, filters.Name)
-	labelMatches := matchesLabel(host, filters.Labels)
-
-	return swarmMatches && driverMatches && stateMatches && nameMatches && labelMatches
-}
-
-func matchesSwarmName(host *host.Host, swarmNames []string, swarmMasters map[string]string) bool {
-	if len(swarmNames) == 0 {
-		return true
-	}
-	for _, n := range swarmNames {
-		if host.HostOptions != nil && host.HostOptions.SwarmOptions != nil {
-			if strings.EqualFold(n, swarmMasters[host.HostOptions.SwarmOptions.Discovery]) {
-				return true
-			}
-		}
-	}
-	return false
-}
-
-func matchesDriverName(host *host.Host, driverNames []string) bool {
-	if len(driverNames) == 0 {
-		return true
-	}
-	for _, n := range driverNames {
-		if strings.EqualFold(host.DriverName, n) {
-			return true
-		}
-	}
-	return false
-}
-
-func matchesState(host *host.Host, states []string) bool {
-	if len(states) == 0 {
-		return true
-	}
-	for _, n := range states {
-		s, err := host.Driver.GetState()
-		if err != nil {
-			log.Warn(err)
-		}
-		if strings.EqualFold(n, s.String()) {
-			return true
-		}
-	}
-	return false
-}
-
-func matchesName(host *host.Host, names []string) bool {
-	if len(names) == 0 {
-		return true
-	}
-	for _, n := range names {
-		r, err := regexp.Compile(n)
-		if err != nil {
-			log.Error(err)
-			os.Exit(1) // TODO: Can we get rid of this call, and exit 'properly' ?
-		}
-		if r.MatchString(host.Driver.GetMachineName()) {
-			return true
-		}
-	}
-	return false
-}
-
-func matchesLabel(host *host.Host, labels []string) bool {
-	if len(labels) == 0 {
-		return true
-	}
-
-	var englabels = make(map[string]string, len(host.HostOptions.EngineOptions.Labels))
-
-	if host.HostOptions != nil && host.HostOptions.EngineOptions.Labels != nil {
-		for _, s := range host.HostOptions.EngineOptions.Labels {
-			kv := strings.SplitN(s, "=", 2)
-			englabels[kv[0]] = kv[1]
-		}
-	}
-
-	for _, l := range labels {
-		kv := strings.SplitN(l, "=", 2)
-		if val, exists := englabels[kv[0]]; exists && strings.EqualFold(val, kv[1]) {
-			return true
-		}
-	}
-	return false
-}
-
-// PERFORMANCE: The code of this function is complicated because we try
-// to call the underlying drivers as less as possible to get the information
-// we need.
-func attemptGetHostState(h *host.Host, stateQueryChan chan<- HostListItem) {
+func attemptGetHostState(h *host.Host, stateQueryChan chan<- HostListItem, fetchOS bool) {
 	requestBeginning := time.Now()
 	url := ""
 	currentState := state.None
@@ -352,17 +329,27 @@ func attemptGetHostState(h *host.Host, stateQueryChan chan<- HostListItem) {
 		currentState, _ = h.Driver.GetState()
 	}
 
+	// The details of a running machine are read while its Docker version
+	// is asked for
+	detailsChan := make(chan hostDetails, 1)
+	go func(running bool) {
+		detailsChan <- getHostDetails(h, running, fetchOS)
+	}(currentState == state.Running)
+
 	if err == nil && url != "" {
 		// PERFORMANCE: Reuse the url instead of asking the host again.
 		// This reduces the number of calls to the drivers
-		dockerHost := &mcndockerclient.RemoteDocker{
-			HostURL:    url,
-			AuthOption: h.AuthOptions(),
-		}
-		dockerVersion, err = mcndockerclient.DockerVersion(dockerHost)
+		dockerVersion, err = mcndockerclient.DockerVersion(h.DockerHost(url))
 
 		if err != nil {
 			dockerVersion = "Unknown"
+
+			// The URL of a paused or suspended machine is still known,
+			// so ask the driver whether it's really running.
+			if s, stateErr := h.Driver.GetState(); stateErr == nil && s != state.Running {
+				currentState = s
+				err = nil
+			}
 		} else {
 			dockerVersion = fmt.Sprintf("v%s", dockerVersion)
 		}
@@ -399,7 +386,9 @@ func attemptGetHostState(h *host.Host, stateQueryChan chan<- HostListItem) {
 		active = "* (swarm)"
 	}
 
-	stateQueryChan <- HostListItem{
+	details := <-detailsChan
+
+	item := HostListItem{
 		Name:          h.Name,
 		Active:        active,
 		ActiveHost:    activeHost,
@@ -412,15 +401,21 @@ func attemptGetHostState(h *host.Host, stateQueryChan chan<- HostListItem) {
 		DockerVersion: dockerVersion,
 		Error:         hostError,
 		ResponseTime:  time.Now().Round(time.Millisecond).Sub(requestBeginning.Round(time.Millisecond)),
+		IP:            details.IP,
+		SSHAddress:    details.SSHAddress,
+		OS:            details.OS,
 	}
+	setStoredHostDetails(&item, h)
+
+	stateQueryChan <- item
 }
 
-func getHostState(h *host.Host, hostListItemsChan chan<- HostListItem, timeout time.Duration) {
+func getHostState(h *host.Host, hostListItemsChan chan<- HostListItem, timeout time.Duration, fetchOS bool) {
 	// This channel is used to communicate the properties we are querying
 	// about the host in the case of a successful read.
 	stateQueryChan := make(chan HostListItem)
 
-	go attemptGetHostState(h, stateQueryChan)
+	go attemptGetHostState(h, stateQueryChan, fetchOS)
 
 	select {
 	// If we get back useful information, great.  Forward it straight to
@@ -430,23 +425,26 @@ func getHostState(h *host.Host, hostListItemsChan chan<- HostListItem, timeout t
 
 	// Otherwise, give up after a predetermined duration.
 	case <-time.After(timeout):
-		hostListItemsChan <- HostListItem{
+		item := HostListItem{
 			Name:         h.Name,
 			DriverName:   h.Driver.DriverName(),
 			State:        state.Timeout,
 			ResponseTime: timeout,
 		}
+		setStoredHostDetails(&item, h)
+
+		hostListItemsChan <- item
 	}
 }
 
-func getHostListItems(hostList []*host.Host, hostsInError map[string]error, timeout time.Duration) []HostListItem {
+func getHostListItems(hostList []*host.Host, hostsInError map[string]error, timeout time.Duration, fetchOS bool) []HostListItem {
 	log.Debugf("timeout set to %s", timeout)
 
 	hostListItems := []HostListItem{}
 	hostListItemsChan := make(chan HostListItem)
 
 	for _, h := range hostList {
-		go getHostState(h, hostListItemsChan, timeout)
+		go getHostState(h, hostListItemsChan, timeout, fetchOS)
 	}
 
 	for range hostList {
@@ -486,6 +484,179 @@ func sortHostListItemsByName(items []HostListItem) {
 	}
 }
 
+// sortHostListItems sorts the items sorted by name with the key, or in
+// the reverse order if it starts with -.
+func sortHostListItems(items []HostListItem, key string) {
+	reverse := strings.HasPrefix(key, "-")
+	key = strings.TrimPrefix(key, "-")
+
+	less, ok := lsSortKeys[key]
+	if !ok {
+		if reverse {
+			for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+				items[i], items[j] = items[j], items[i]
+			}
+		}
+		return
+	}
+
+	if reverse {
+		less = func(a, b HostListItem) bool { return lsSortKeys[key](b, a) }
+	}
+
+	sort.Stable(hostListItemsBy{items, less})
+}
+
+// compareIPs compares IP addresses numerically, the invalid ones first.
+func compareIPs(a, b string) int {
+	return bytes.Compare(net.ParseIP(a).To16(), net.ParseIP(b).To16())
+}
+
+type hostListItemsBy struct {
+	items []HostListItem
+	less  func(a, b HostListItem) bool
+}
+
+func (s hostListItemsBy) Len() int           { return len(s.items) }
+func (s hostListItemsBy) Swap(i, j int)      { s.items[i], s.items[j] = s.items[j], s.items[i] }
+func (s hostListItemsBy) Less(i, j int) bool { return s.less(s.items[i], s.items[j]) }
+
+// hostDetails are the details of a machine read from the machine itself,
+// they're only known when it's running.
+type hostDetails struct {
+	IP         string
+	SSHAddress string
+	OS         string
+}
+
+func getHostDetails(h *host.Host, running bool, fetchOS bool) hostDetails {
+	details := hostDetails{}
+	if !running {
+		return details
+	}
+
+	osChan := make(chan string, 1)
+	go func() {
+		if !fetchOS {
+			osChan <- ""
+			return
+		}
+		osChan <- getHostOS(h)
+	}()
+
+	if ip, err := h.Driver.GetIP(); err == nil {
+		details.IP = ip
+	}
+
+	if address, err := h.Driver.GetSSHHostname(); err == nil && address != "" {
+		if port, err := h.Driver.GetSSHPort(); err == nil && port != 0 {
+			address = net.JoinHostPort(address, strconv.Itoa(port))
+		}
+		if username := h.Driver.GetSSHUsername(); username != "" {
+			address = username + "@" + address
+		}
+		details.SSHAddress = address
+	}
+
+	details.OS = <-osChan
+
+	return details
+}
+
+// getHostOS returns the name of the OS of the machine, from its
+// /etc/os-release.
+func getHostOS(h *host.Host) string {
+	output, err := h.RunSSHCommand("cat /etc/os-release")
+	if err != nil {
+		log.Debugf("Error reading the OS of %q: %s", h.Name, err)
+		return ""
+	}
+
+	osRelease, err := provision.NewOsRelease([]byte(output))
+	if err != nil {
+		log.Debugf("Error reading the OS of %q: %s", h.Name, err)
+		return ""
+	}
+
+	if osRelease.PrettyName != "" {
+		return osRelease.PrettyName
+	}
+
+	return strings.TrimSpace(osRelease.Name + " " + osRelease.Version)
+}
+
+// setStoredHostDetails sets the details of the item read from the store,
+// they're known whether the machine answers or not.
+func setStoredHostDetails(item *HostListItem, h *host.Host) {
+	item.Created = h.Created
+	item.CPUs, item.Memory, item.DiskSize = driverResources(h.DriverName, h.RawDriver)
+
+	if h.HostOptions != nil && h.HostOptions.AuthOptions != nil {
+		if expiry, err := cert.CertificateExpiry(h.HostOptions.AuthOptions.ServerCertPath); err == nil {
+			item.CertExpiry = expiry
+		}
+	}
+}
+
+// driverResources returns the CPUs, memory and disk size in MB recorded in
+// the configuration of the driver, under the names the drivers use.
+func driverResources(driverName string, rawDriver []byte) (int, int, int) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(rawDriver, &fields); err != nil {
+		return 0, 0, 0
+	}
+
+	field := func(names ...string) int {
+		for _, name := range names {
+			var value int
+			if raw, ok := fields[name]; ok && json.Unmarshal(raw, &value) == nil && value != 0 {
+				return value
+			}
+		}
+		return 0
+	}
+
+	cpus := field("CPU", "CPUs", "CPUCount")
+	memory := field("Memory", "MemSize", "MemorySize")
+	diskSize := field("DiskSize", "RootSize")
+	if diskSizeInGB[driverName] {
+		diskSize *= 1024
+	}
+
+	return cpus, memory, diskSize
+}
+
+// humanDuration formats a duration, or how long ago or in how long a date
+// is, e.g. "3 days ago".
+func humanDuration(v interface{}) string {
+	switch v := v.(type) {
+	case time.Duration:
+		return units.HumanDuration(v)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		if d := time.Since(v); d >= 0 {
+			return strings.ToLower(units.HumanDuration(d)) + " ago"
+		}
+		return "in " + strings.ToLower(units.HumanDuration(time.Until(v)))
+	}
+
+	return fmt.Sprint(v)
+}
+
+// humanSize formats a size in MB, e.g. "2 GiB".
+func humanSize(v interface{}) string {
+	if size, ok := v.(int); ok {
+		if size == 0 {
+			return ""
+		}
+		return units.BytesSize(float64(size) * 1024 * 1024)
+	}
+
+	return fmt.Sprint(v)
+}
+
 func isActive(currentState state.State, hostURL string) bool {
 	return currentState == state.Running && hostURL == os.Getenv("DOCKER_HOST")
 }
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"bytes"
 	"os"
 	"testing"
 
@@ -9,300 +10,13 @@ import (
 	"errors"
 
 	"github.com/classmarkets/docker-machine/drivers/fakedriver"
-	"github.com/classmarkets/docker-machine/libmachine/engine"
 	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/libmachinetest"
 	"github.com/classmarkets/docker-machine/libmachine/mcndockerclient"
 	"github.com/classmarkets/docker-machine/libmachine/state"
-	"github.com/classmarkets/docker-machine/libmachine/swarm"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestParseFiltersErrorsGivenInvalidFilter(t *testing.T) {
-	_, err := parseFilters([]string{"foo=bar"})
-	assert.EqualError(t, err, "Unsupported filter key 'foo'")
-}
-
-func TestParseFiltersSwarm(t *testing.T) {
-	actual, _ := parseFilters([]string{"swarm=foo"})
-	assert.Equal(t, actual, FilterOptions{SwarmName: []string{"foo"}})
-}
-
-func TestParseFiltersDriver(t *testing.T) {
-	actual, _ := parseFilters([]string{"driver=bar"})
-	assert.Equal(t, actual, FilterOptions{DriverName: []string{"bar"}})
-}
-
-func TestParseFiltersState(t *testing.T) {
-	actual, _ := parseFilters([]string{"state=Running"})
-	assert.Equal(t, actual, FilterOptions{State: []string{"Running"}})
-}
-
-func TestParseFiltersName(t *testing.T) {
-	actual, _ := parseFilters([]string{"name=dev"})
-	assert.Equal(t, actual, FilterOptions{Name: []string{"dev"}})
-}
-
-func TestParseFiltersLabel(t *testing.T) {
-	actual, err := parseFilters([]string{"label=com.example.foo=bar"})
-	assert.EqualValues(t, actual, FilterOptions{Labels: []string{"com.example.foo=bar"}})
-	assert.Nil(t, err, "returned err value must be Nil")
-}
-
-func TestParseFiltersAll(t *testing.T) {
-	actual, _ := parseFilters([]string{"swarm=foo", "driver=bar", "state=Stopped", "name=dev"})
-	assert.Equal(t, actual, FilterOptions{SwarmName: []string{"foo"}, DriverName: []string{"bar"}, State: []string{"Stopped"}, Name: []string{"dev"}})
-}
-
-func TestParseFiltersAllCase(t *testing.T) {
-	actual, err := parseFilters([]string{"sWarM=foo", "DrIver=bar", "StaTe=Stopped", "NAMe=dev", "LABEL=com=foo"})
-	assert.Equal(t, actual, FilterOptions{SwarmName: []string{"foo"}, DriverName: []string{"bar"}, State: []string{"Stopped"}, Name: []string{"dev"}, Labels: []string{"com=foo"}})
-	assert.Nil(t, err, "err should be nil")
-}
-
-func TestParseFiltersDuplicates(t *testing.T) {
-	actual, _ := parseFilters([]string{"swarm=foo", "driver=bar", "name=mark", "swarm=baz", "driver=qux", "state=Running", "state=Starting", "name=time"})
-	assert.Equal(t, actual, FilterOptions{SwarmName: []string{"foo", "baz"}, DriverName: []string{"bar", "qux"}, State: []string{"Running", "Starting"}, Name: []string{"mark", "time"}})
-}
-
-func TestParseFiltersValueWithEqual(t *testing.T) {
-	actual, _ := parseFilters([]string{"driver=bar=baz"})
-	assert.Equal(t, actual, FilterOptions{DriverName: []string{"bar=baz"}})
-}
-
-func TestFilterHostsReturnsFiltersValuesCaseInsensitive(t *testing.T) {
-	opts := FilterOptions{
-		SwarmName:  []string{"fOo"},
-		DriverName: []string{"ViRtUaLboX"},
-		State:      []string{"StOPpeD"},
-		Labels:     []string{"com.EXAMPLE.app=FOO"},
-	}
-	hosts := []*host.Host{}
-	actual := filterHosts(hosts, opts)
-	assert.EqualValues(t, actual, hosts)
-}
-func TestFilterHostsReturnsSameGivenNoFilters(t *testing.T) {
-	opts := FilterOptions{}
-	hosts := []*host.Host{
-		{
-			Name:       "testhost",
-			DriverName: "fakedriver",
-		},
-	}
-	actual := filterHosts(hosts, opts)
-	assert.EqualValues(t, actual, hosts)
-}
-
-func TestFilterHostsReturnSetLabel(t *testing.T) {
-	opts := FilterOptions{
-		Labels: []string{"com.class.foo=bar"},
-	}
-	hosts := []*host.Host{
-		{
-			Name:       "testhost",
-			DriverName: "fakedriver",
-			HostOptions: &host.Options{
-				EngineOptions: &engine.Options{
-					Labels: []string{"com.class.foo=bar"},
-				},
-			},
-		},
-	}
-	actual := filterHosts(hosts, opts)
-	assert.EqualValues(t, actual, hosts)
-}
-
-func TestFilterHostsReturnsEmptyGivenEmptyHosts(t *testing.T) {
-	opts := FilterOptions{
-		SwarmName: []string{"foo"},
-	}
-	hosts := []*host.Host{}
-	assert.Empty(t, filterHosts(hosts, opts))
-}
-
-func TestFilterHostsReturnsEmptyGivenNonMatchingFilters(t *testing.T) {
-	opts := FilterOptions{
-		SwarmName: []string{"foo"},
-	}
-	hosts := []*host.Host{
-		{
-			Name:       "testhost",
-			DriverName: "fakedriver",
-		},
-	}
-	assert.Empty(t, filterHosts(hosts, opts))
-}
-
-func TestFilterHostsBySwarmName(t *testing.T) {
-	opts := FilterOptions{
-		SwarmName: []string{"master"},
-	}
-	master :=
-		&host.Host{
-			Name: "master",
-			HostOptions: &host.Options{
-				SwarmOptions: &swarm.Options{Master: true, Discovery: "foo"},
-			},
-		}
-	node1 :=
-		&host.Host{
-			Name: "node1",
-			HostOptions: &host.Options{
-				SwarmOptions: &swarm.Options{Master: false, Discovery: "foo"},
-			},
-		}
-	othermaster :=
-		&host.Host{
-			Name: "othermaster",
-			HostOptions: &host.Options{
-				SwarmOptions: &swarm.Options{Master: true, Discovery: "bar"},
-			},
-		}
-	hosts := []*host.Host{master, node1, othermaster}
-	expected := []*host.Host{master, node1}
-
-	assert.EqualValues(t, filterHosts(hosts, opts), expected)
-}
-
-func TestFilterHostsByDriverName(t *testing.T) {
-	opts := FilterOptions{
-		DriverName: []string{"fakedriver"},
-	}
-	node1 :=
-		&host.Host{
-			Name:       "node1",
-			DriverName: "fakedriver",
-		}
-	node2 :=
-		&host.Host{
-			Name:       "node2",
-			DriverName: "virtualbox",
-		}
-	node3 :=
-		&host.Host{
-			Name:       "node3",
-			DriverName: "fakedriver",
-		}
-	hosts := []*host.Host{node1, node2, node3}
-	expected := []*host.Host{node1, node3}
-
-	assert.EqualValues(t, filterHosts(hosts, opts), expected)
-}
-
-func TestFilterHostsByState(t *testing.T) {
-	opts := FilterOptions{
-		State: []string{"Paused", "Saved", "Stopped"},
-	}
-	node1 :=
-		&host.Host{
-			Name:       "node1",
-			DriverName: "fakedriver",
-			Driver:     &fakedriver.Driver{MockState: state.Paused},
-		}
-	node2 :=
-		&host.Host{
-			Name:       "node2",
-			DriverName: "virtualbox",
-			Driver:     &fakedriver.Driver{MockState: state.Stopped},
-		}
-	node3 :=
-		&host.Host{
-			Name:       "node3",
-			DriverName: "fakedriver",
-			Driver:     &fakedriver.Driver{MockState: state.Running},
-		}
-	hosts := []*host.Host{node1, node2, node3}
-	expected := []*host.Host{node1, node2}
-
-	assert.EqualValues(t, filterHosts(hosts, opts), expected)
-}
-
-func TestFilterHostsByName(t *testing.T) {
-	opts := FilterOptions{
-		Name: []string{"fire", "ice", "earth", "a.?r"},
-	}
-	node1 :=
-		&host.Host{
-			Name:       "fire",
-			DriverName: "fakedriver",
-			Driver:     &fakedriver.Driver{MockState: state.Paused, MockName: "fire"},
-		}
-	node2 :=
-		&host.Host{
-			Name:       "ice",
-			DriverName: "adriver",
-			Driver:     &fakedriver.Driver{MockState: state.Paused, MockName: "ice"},
-		}
-	node3 :=
-		&host.Host{
-			Name:       "air",
-			DriverName: "nodriver",
-			Driver:     &fakedriver.Driver{MockState: state.Paused, MockName: "air"},
-		}
-	node4 :=
-		&host.Host{
-			Name:       "water",
-			DriverName: "falsedriver",
-			Driver:     &fakedriver.Driver{MockState: state.Paused, MockName: "water"},
-		}
-	hosts := []*host.Host{node1, node2, node3, node4}
-	expected := []*host.Host{node1, node2, node3}
-
-	assert.EqualValues(t, filterHosts(hosts, opts), expected)
-}
-
-func TestFilterHostsMultiFlags(t *testing.T) {
-	opts := FilterOptions{
-		SwarmName:  []string{},
-		DriverName: []string{"fakedriver", "virtualbox"},
-	}
-	node1 :=
-		&host.Host{
-			Name:       "node1",
-			DriverName: "fakedriver",
-		}
-	node2 :=
-		&host.Host{
-			Name:       "node2",
-			DriverName: "virtualbox",
-		}
-	node3 :=
-		&host.Host{
-			Name:       "node3",
-			DriverName: "softlayer",
-		}
-	hosts := []*host.Host{node1, node2, node3}
-	expected := []*host.Host{node1, node2}
-
-	assert.EqualValues(t, filterHosts(hosts, opts), expected)
-}
-
-func TestFilterHostsDifferentFlagsProduceAND(t *testing.T) {
-	opts := FilterOptions{
-		DriverName: []string{"virtualbox"},
-		State:      []string{"Running"},
-	}
-
-	hosts := []*host.Host{
-		{
-			Name:       "node1",
-			DriverName: "fakedriver",
-			Driver:     &fakedriver.Driver{MockState: state.Paused},
-		},
-		{
-			Name:       "node2",
-			DriverName: "virtualbox",
-			Driver:     &fakedriver.Driver{MockState: state.Stopped},
-		},
-		{
-			Name:       "node3",
-			DriverName: "fakedriver",
-			Driver:     &fakedriver.Driver{MockState: state.Running},
-		},
-	}
-
-	assert.Empty(t, filterHosts(hosts, opts))
-}
-
 func TestGetHostListItems(t *testing.T) {
 	defer func(versioner mcndockerclient.DockerVersioner) { mcndockerclient.CurrentDockerVersioner = versioner }(mcndockerclient.CurrentDockerVersioner)
 	mcndockerclient.CurrentDockerVersioner = &mcndockerclient.FakeDockerVersioner{Version: "1.9"}
@@ -345,7 +59,7 @@ func TestGetHostListItems(t *testing.T) {
 		{"foo", state.Running, true, "v1.9", ""},
 	}
 
-	items := getHostListItems(hosts, map[string]error{}, 10*time.Second)
+	items := getHostListItems(hosts, map[string]error{}, 10*time.Second, false)
 
 	for i := range expected {
 		assert.Equal(t, expected[i].name, items[i].Name)
@@ -394,7 +108,7 @@ func TestGetHostListItemsEnvDockerHostUnset(t *testing.T) {
 		"baz": {state.Saved, false},
 	}
 
-	items := getHostListItems(hosts, map[string]error{}, 10*time.Second)
+	items := getHostListItems(hosts, map[string]error{}, 10*time.Second, false)
 
 	for _, item := range items {
 		expected := expected[item.Name]
@@ -466,7 +180,7 @@ func TestGetHostStateTimeout(t *testing.T) {
 		},
 	}
 
-	hostItem := getHostListItems(hosts, nil, time.Millisecond)[0]
+	hostItem := getHostListItems(hosts, nil, time.Millisecond, false)[0]
 
 	assert.Equal(t, "foo", hostItem.Name)
 	assert.Equal(t, state.Timeout, hostItem.State)
@@ -484,7 +198,7 @@ func TestGetHostStateError(t *testing.T) {
 		},
 	}
 
-	hostItem := getHostListItems(hosts, nil, 10*time.Second)[0]
+	hostItem := getHostListItems(hosts, nil, 10*time.Second, false)[0]
 
 	assert.Equal(t, "foo", hostItem.Name)
 	assert.Equal(t, state.Error, hostItem.State)
@@ -510,7 +224,7 @@ func TestGetSomeHostInError(t *testing.T) {
 		"bar": errors.New("invalid memory address or nil pointer dereference"),
 	}
 
-	hostItems := getHostListItems(hosts, hostsInError, 10*time.Second)
+	hostItems := getHostListItems(hosts, hostsInError, 10*time.Second, false)
 	assert.Equal(t, 2, len(hostItems))
 
 	hostItem := hostItems[0]
@@ -533,3 +247,109 @@ func TestOnErrorWithMultilineComment(t *testing.T) {
 
 	assert.Equal(t, itemInError.Error, "missing parameter: the request must contain the parameter InstanceId	status code: 400")
 }
+
+func TestGetHostListItemsDetails(t *testing.T) {
+	defer func(versioner mcndockerclient.DockerVersioner) { mcndockerclient.CurrentDockerVersioner = versioner }(mcndockerclient.CurrentDockerVersioner)
+	mcndockerclient.CurrentDockerVersioner = &mcndockerclient.FakeDockerVersioner{Version: "1.9"}
+
+	created := time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC)
+	hosts := []*host.Host{
+		{
+			Name:       "foo",
+			DriverName: "virtualbox",
+			Driver: &fakedriver.Driver{
+				MockState: state.Running,
+				MockIP:    "192.168.99.100",
+			},
+			Created:   created,
+			RawDriver: []byte(`{"CPU": 2, "Memory": 2048, "DiskSize": 20000}`),
+		},
+	}
+
+	item := getHostListItems(hosts, nil, 10*time.Second, false)[0]
+
+	assert.Equal(t, "192.168.99.100", item.IP)
+	assert.Equal(t, created, item.Created)
+	assert.Equal(t, 2, item.CPUs)
+	assert.Equal(t, 2048, item.Memory)
+	assert.Equal(t, 20000, item.DiskSize)
+	assert.Empty(t, item.OS)
+}
+
+func TestDriverResources(t *testing.T) {
+	cpus, memory, diskSize := driverResources("hyperv", []byte(`{"CPU": 1, "MemSize": 1024, "DiskSize": 20000}`))
+	assert.Equal(t, []int{1, 1024, 20000}, []int{cpus, memory, diskSize})
+
+	cpus, memory, diskSize = driverResources("google", []byte(`{"MachineType": "n1-standard-1", "DiskSize": 20}`))
+	assert.Equal(t, []int{0, 0, 20 * 1024}, []int{cpus, memory, diskSize})
+
+	cpus, memory, diskSize = driverResources("none", nil)
+	assert.Equal(t, []int{0, 0, 0}, []int{cpus, memory, diskSize})
+}
+
+func TestSortHostListItems(t *testing.T) {
+	items := []HostListItem{
+		{Name: "a", State: state.Stopped, Memory: 1024},
+		{Name: "b", State: state.Running, Memory: 2048},
+		{Name: "c", State: state.Stopped, Memory: 512},
+	}
+
+	names := func() []string {
+		names := []string{}
+		for _, item := range items {
+			names = append(names, item.Name)
+		}
+		return names
+	}
+
+	sortHostListItems(items, "state")
+	assert.Equal(t, []string{"b", "a", "c"}, names())
+
+	sortHostListItems(items, "-memory")
+	assert.Equal(t, []string{"b", "a", "c"}, names())
+
+	sortHostListItems(items, "memory")
+	assert.Equal(t, []string{"c", "a", "b"}, names())
+
+	sortHostListItems(items, "-name")
+	assert.Equal(t, []string{"b", "a", "c"}, names())
+}
+
+func TestParseFormatFunctions(t *testing.T) {
+	template, table, err := parseFormat("table {{ upper .Name }}\t{{ humanSize .Memory }}\t{{ humanDuration .ResponseTime }}")
+	assert.NoError(t, err)
+	assert.True(t, table)
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, template.Execute(out, headers))
+	assert.NoError(t, template.Execute(out, HostListItem{Name: "dev", Memory: 2048, ResponseTime: 3 * time.Second}))
+
+	assert.Equal(t, "NAME\tMEMORY\tRESPONSE\nDEV\t2 GiB\t3 seconds\n", out.String())
+}
+
+func TestHumanDuration(t *testing.T) {
+	assert.Equal(t, "3 days ago", humanDuration(time.Now().Add(-72*time.Hour)))
+	assert.Equal(t, "in 2 years", humanDuration(time.Now().Add(2*365*24*time.Hour+time.Hour)))
+	assert.Equal(t, "", humanDuration(time.Time{}))
+	assert.Equal(t, "CREATED", humanDuration("CREATED"))
+}
+
+func TestWatchHosts(t *testing.T) {
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name:   "foo",
+				Driver: &fakedriver.Driver{},
+			},
+		},
+	}
+
+	stop := make(chan os.Signal, 1)
+	stop <- os.Interrupt
+
+	out := &bytes.Buffer{}
+	err := watchHosts(out, api, lsOptions{quiet: true}, time.Hour, stop)
+
+	assert.NoError(t, err)
+	assert.Equal(t, lsClearScreen+"foo\n", out.String())
+}
@@ -14,13 +14,15 @@ import (
 var (
 	// TODO: possibly move this to ssh package
 	baseSSHFSArgs = []string{
-		"-o", "StrictHostKeyChecking=no",
-		"-o", "UserKnownHostsFile=/dev/null",
 		"-o", "LogLevel=quiet", // suppress "Warning: Permanently added '[localhost]:2022' (ECDSA) to the list of known hosts."
 	}
 )
 
 func cmdMount(c CommandLine, api libmachine.API) error {
+	if c.Bool("reverse") {
+		return cmdReverseMount(c, api)
+	}
+
 	args := c.Args()
 	if len(args) < 1 || len(args) > 2 {
 		c.ShowHelp()
@@ -71,7 +73,12 @@ func getMountCmd(src, dest string, unmount bool, hostInfoLoader HostInfoLoader)
 		dest = srcPath
 	}
 
-	sshArgs := baseSSHFSArgs
+	hostKeyOpts, err := hostKeyArgs(srcHost)
+	if err != nil {
+		return nil, err
+	}
+
+	sshArgs := append(hostKeyOpts, baseSSHFSArgs...)
 	if srcHost.GetSSHKeyPath() != "" {
 		sshArgs = append(sshArgs, "-o", "IdentitiesOnly=yes")
 	}
@@ -128,6 +135,12 @@ func getInfoForSshfsArg(hostAndPath string, hostInfoLoader HostInfoLoader) (h Ho
 		args = append(args, "-o", fmt.Sprintf("IdentityFile=%s", h.GetSSHKeyPath()))
 	}
 
+	jumpArgs, err := proxyJumpArgs(h)
+	if err != nil {
+		return nil, "", "", nil, err
+	}
+	args = append(args, jumpArgs...)
+
 	if user == "" {
 		user = h.GetSSHUsername()
 	}
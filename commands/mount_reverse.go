@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/classmarkets/docker-machine/libmachine"
+	"github.com/classmarkets/docker-machine/libmachine/log"
+	"github.com/pkg/sftp"
+)
+
+const reverseMountLogFile = "reverse-mount.log"
+
+var errReverseMountSourceNotDir = errors.New("The source of a reverse mount must be a local directory")
+
+// cmdReverseMount makes a local directory visible inside a machine. sshfs is
+// run on the machine in passive mode, its SFTP requests are served by the
+// CLI over the same SSH session, so the machine doesn't need to be able to
+// connect back.
+func cmdReverseMount(c CommandLine, api libmachine.API) error {
+	args := c.Args()
+	hostInfoLoader := &storeHostInfoLoader{api}
+
+	if c.Bool("unmount") {
+		if len(args) != 1 {
+			c.ShowHelp()
+			return errWrongNumberArguments
+		}
+
+		h, user, mountpoint, _, err := getInfoForSshfsArg(args[0], hostInfoLoader)
+		if err != nil {
+			return err
+		}
+
+		return reverseUnmount(h, user, mountpoint)
+	}
+
+	if len(args) != 2 {
+		c.ShowHelp()
+		return errWrongNumberArguments
+	}
+
+	src, err := reverseMountSource(args[0])
+	if err != nil {
+		return err
+	}
+
+	h, user, mountpoint, _, err := getInfoForSshfsArg(args[1], hostInfoLoader)
+	if err != nil {
+		return err
+	}
+
+	if mountpoint == "" {
+		mountpoint = filepath.ToSlash(src)
+	}
+
+	if !c.Bool("foreground") {
+		return startReverseMount(c, api.GetMachinesDir(), h, src, args[1])
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	defer signal.Stop(stop)
+
+	go func() {
+		if _, ok := <-stop; !ok {
+			return
+		}
+
+		// Unmounting ends sshfs, and with it serving the directory
+		if err := reverseUnmount(h, user, mountpoint); err != nil {
+			log.Errorf("Error unmounting %s: %s", mountpoint, err)
+		}
+	}()
+
+	return serveReverseMount(h, user, src, mountpoint, c.Bool("read-only"))
+}
+
+func reverseMountSource(path string) (string, error) {
+	src, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", errReverseMountSourceNotDir
+	}
+
+	return src, nil
+}
+
+// serveReverseMount mounts src at the mountpoint in the machine and serves
+// it until it's unmounted.
+func serveReverseMount(h HostInfo, user, src, mountpoint string, readOnly bool) error {
+	client, err := newNativeClient(h, user)
+	if err != nil {
+		return err
+	}
+
+	pipe, err := client.Pipe(reverseMountCommand(src, mountpoint, readOnly))
+	if err != nil {
+		return err
+	}
+
+	// The machine only gets to the files under src, a plain SFTP server
+	// would give it every file the local user can read
+	handlers, err := newReverseMountHandlers(src, readOnly)
+	if err != nil {
+		pipe.Close()
+		return err
+	}
+
+	server := sftp.NewRequestServer(pipe, handlers)
+
+	log.Infof("Serving %s at %s:%s", src, h.GetMachineName(), mountpoint)
+
+	serveErr := server.Serve()
+
+	if err := pipe.Close(); err != nil {
+		return err
+	}
+
+	if serveErr != nil && serveErr != io.EOF {
+		return serveErr
+	}
+
+	return nil
+}
+
+// startReverseMount runs the reverse mount in the background, with its
+// output going to a log file in the machine directory.
+func startReverseMount(c CommandLine, machinesDir string, h HostInfo, src, hostAndPath string) error {
+	args := []string{"mount", "--reverse", "--foreground"}
+	if c.Bool("read-only") {
+		args = append(args, "--read-only")
+	}
+	args = append(args, src, hostAndPath)
+
+	logPath := filepath.Join(machinesDir, h.GetMachineName(), reverseMountLogFile)
+	if _, err := startInBackground(c, logPath, args); err != nil {
+		return fmt.Errorf("Error mounting %s: %s", src, err)
+	}
+
+	log.Infof("Mounted %s in the background, unmount it with: %s mount --reverse -u %s", src, os.Args[0], hostAndPath)
+
+	return nil
+}
+
+// reverseUnmount unmounts a reverse mount, which ends the CLI serving it.
+func reverseUnmount(h HostInfo, user, mountpoint string) error {
+	if mountpoint == "" {
+		return errors.New("The mount point to unmount is missing, e.g. machine:/path")
+	}
+
+	client, err := newNativeClient(h, user)
+	if err != nil {
+		return err
+	}
+
+	output, err := client.Output(reverseUnmountCommand(mountpoint))
+	if err != nil {
+		return fmt.Errorf("%s: %s", err, strings.TrimSpace(output))
+	}
+
+	return nil
+}
+
+// reverseMountCommand returns the command running sshfs on the machine. It
+// reads the SFTP responses from its input and writes the requests to its
+// output, the option doing that was renamed from slave to passive.
+func reverseMountCommand(src, mountpoint string, readOnly bool) string {
+	options := "allow_other"
+	if readOnly {
+		options += ",ro"
+	}
+
+	return fmt.Sprintf(`command -v sshfs >/dev/null || { echo "sshfs is not installed on the machine" >&2; exit 1; }; `+
+		`mode=slave; if sshfs -h 2>&1 | grep -q passive; then mode=passive; fi; `+
+		`sudo mkdir -p %[2]s && exec sudo sshfs -o "$mode",%[3]s local:%[1]s %[2]s`,
+		shellQuote(filepath.ToSlash(src)), shellQuote(mountpoint), options)
+}
+
+func reverseUnmountCommand(mountpoint string) string {
+	return fmt.Sprintf("sudo fusermount -u %[1]s 2>/dev/null || sudo umount %[1]s", shellQuote(mountpoint))
+}
@@ -0,0 +1,269 @@
+package commands
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// reverseMountFS serves the SFTP requests of a reverse mount from the local
+// source directory. sshfs asks for the paths under the source as given on
+// its command line, each of them is mapped into the directory with its
+// symlinks resolved, and refused when it leads out of the directory.
+type reverseMountFS struct {
+	// prefix is the source as sshfs names it, root the local directory
+	prefix   string
+	root     string
+	readOnly bool
+}
+
+func newReverseMountHandlers(src string, readOnly bool) (sftp.Handlers, error) {
+	root, err := filepath.EvalSymlinks(src)
+	if err != nil {
+		return sftp.Handlers{}, err
+	}
+
+	fs := &reverseMountFS{
+		prefix:   path.Clean(filepath.ToSlash(src)),
+		root:     root,
+		readOnly: readOnly,
+	}
+
+	return sftp.Handlers{
+		FileGet:  fs,
+		FilePut:  fs,
+		FileCmd:  fs,
+		FileList: fs,
+	}, nil
+}
+
+// localPath maps a requested path into the source directory, without
+// resolving any symlink. Paths outside the source are refused.
+func (fs *reverseMountFS) localPath(p string) (string, error) {
+	p = path.Clean(filepath.ToSlash(p))
+
+	if p != fs.prefix && !strings.HasPrefix(p, strings.TrimSuffix(fs.prefix, "/")+"/") {
+		return "", sftp.ErrSshFxPermissionDenied
+	}
+
+	return filepath.Join(fs.root, filepath.FromSlash(strings.TrimPrefix(p, fs.prefix))), nil
+}
+
+// resolve maps a requested path into the source directory and resolves
+// its symlinks, all of them or all but the last element's when followLast
+// isn't set. A missing last element is left as it is, to be created.
+func (fs *reverseMountFS) resolve(p string, followLast bool) (string, error) {
+	local, err := fs.localPath(p)
+	if err != nil {
+		return "", err
+	}
+
+	if local == fs.root {
+		return local, nil
+	}
+
+	resolved := ""
+	if followLast {
+		resolved, err = filepath.EvalSymlinks(local)
+	}
+	if !followLast || os.IsNotExist(err) {
+		var dir string
+		dir, err = filepath.EvalSymlinks(filepath.Dir(local))
+		resolved = filepath.Join(dir, filepath.Base(local))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if !fs.contains(resolved) {
+		return "", sftp.ErrSshFxPermissionDenied
+	}
+
+	return resolved, nil
+}
+
+func (fs *reverseMountFS) contains(p string) bool {
+	rel, err := filepath.Rel(fs.root, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func (fs *reverseMountFS) Fileread(r *sftp.Request) (io.ReaderAt, error) {
+	p, err := fs.resolve(r.Filepath, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(p)
+}
+
+func (fs *reverseMountFS) Filewrite(r *sftp.Request) (io.WriterAt, error) {
+	if fs.readOnly {
+		return nil, sftp.ErrSshFxPermissionDenied
+	}
+
+	p, err := fs.resolve(r.Filepath, true)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := r.Pflags()
+	osFlags := os.O_WRONLY
+	if flags.Read {
+		osFlags = os.O_RDWR
+	}
+	if flags.Append {
+		osFlags |= os.O_APPEND
+	}
+	if flags.Creat {
+		osFlags |= os.O_CREATE
+	}
+	if flags.Trunc {
+		osFlags |= os.O_TRUNC
+	}
+	if flags.Excl {
+		osFlags |= os.O_EXCL
+	}
+
+	return os.OpenFile(p, osFlags, 0644)
+}
+
+func (fs *reverseMountFS) Filecmd(r *sftp.Request) error {
+	if fs.readOnly {
+		return sftp.ErrSshFxPermissionDenied
+	}
+
+	if r.Method == "Symlink" {
+		// The link is created at Target and points to Filepath, which has
+		// to be in the source directory too
+		target, err := fs.localPath(r.Filepath)
+		if err != nil {
+			return err
+		}
+		link, err := fs.resolve(r.Target, false)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, link)
+	}
+
+	// Only the attributes are changed through a symlink, the other
+	// commands act on the entry itself
+	p, err := fs.resolve(r.Filepath, r.Method == "Setstat")
+	if err != nil {
+		return err
+	}
+
+	switch r.Method {
+	case "Setstat":
+		return fs.setstat(p, r)
+	case "Rename":
+		target, err := fs.resolve(r.Target, false)
+		if err != nil {
+			return err
+		}
+		return os.Rename(p, target)
+	case "Rmdir", "Remove":
+		return os.Remove(p)
+	case "Mkdir":
+		return os.Mkdir(p, 0755)
+	}
+
+	return sftp.ErrSshFxOpUnsupported
+}
+
+func (fs *reverseMountFS) setstat(p string, r *sftp.Request) error {
+	flags := r.AttrFlags()
+	attrs := r.Attributes()
+
+	if flags.Size {
+		if err := os.Truncate(p, int64(attrs.Size)); err != nil {
+			return err
+		}
+	}
+	if flags.Permissions {
+		if err := os.Chmod(p, attrs.FileMode()); err != nil {
+			return err
+		}
+	}
+	if flags.Acmodtime {
+		if err := os.Chtimes(p, time.Unix(int64(attrs.Atime), 0), time.Unix(int64(attrs.Mtime), 0)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (fs *reverseMountFS) Filelist(r *sftp.Request) (sftp.ListerAt, error) {
+	switch r.Method {
+	case "List":
+		p, err := fs.resolve(r.Filepath, true)
+		if err != nil {
+			return nil, err
+		}
+
+		dir, err := os.Open(p)
+		if err != nil {
+			return nil, err
+		}
+		defer dir.Close()
+
+		infos, err := dir.Readdir(-1)
+		if err != nil {
+			return nil, err
+		}
+		return fileInfoLister(infos), nil
+	case "Stat":
+		p, err := fs.resolve(r.Filepath, true)
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		return fileInfoLister{info}, nil
+	case "Readlink":
+		p, err := fs.resolve(r.Filepath, false)
+		if err != nil {
+			return nil, err
+		}
+
+		target, err := os.Readlink(p)
+		if err != nil {
+			return nil, err
+		}
+		return fileInfoLister{linkTarget{target: target}}, nil
+	}
+
+	return nil, sftp.ErrSshFxOpUnsupported
+}
+
+// fileInfoLister lists the entries of a directory, Readdir style.
+type fileInfoLister []os.FileInfo
+
+func (l fileInfoLister) ListAt(infos []os.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(l)) {
+		return 0, io.EOF
+	}
+
+	n := copy(infos, l[offset:])
+	if n < len(infos) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// linkTarget is how a symlink target is returned to Readlink, by its name.
+type linkTarget struct {
+	os.FileInfo
+	target string
+}
+
+func (l linkTarget) Name() string { return l.target }
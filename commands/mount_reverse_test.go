@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/classmarkets/docker-machine/commands/commandstest"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReverseMountCommand(t *testing.T) {
+	cmd := reverseMountCommand("/home/me/my src", "/src", false)
+
+	assert.Contains(t, cmd, `sudo mkdir -p '/src' && exec sudo sshfs -o "$mode",allow_other local:'/home/me/my src' '/src'`)
+	assert.Contains(t, cmd, "mode=slave; if sshfs -h 2>&1 | grep -q passive; then mode=passive; fi")
+}
+
+func TestReverseMountCommandReadOnly(t *testing.T) {
+	cmd := reverseMountCommand("/home/me/src", "/src", true)
+
+	assert.Contains(t, cmd, `-o "$mode",allow_other,ro local:'/home/me/src' '/src'`)
+}
+
+func TestReverseUnmountCommand(t *testing.T) {
+	assert.Equal(t, "sudo fusermount -u '/src' 2>/dev/null || sudo umount '/src'", reverseUnmountCommand("/src"))
+}
+
+func TestReverseMountSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "file")
+	assert.NoError(t, ioutil.WriteFile(file, []byte("file"), 0644))
+
+	src, err := reverseMountSource(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, dir, src)
+
+	_, err = reverseMountSource(file)
+	assert.Equal(t, errReverseMountSourceNotDir, err)
+}
+
+func TestCmdReverseMountRequiresArgs(t *testing.T) {
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"/src"},
+		LocalFlags: &commandstest.FakeFlagger{
+			Data: map[string]interface{}{
+				"reverse": true,
+			},
+		},
+	}
+
+	err := cmdMount(commandLine, nil)
+
+	assert.Equal(t, errWrongNumberArguments, err)
+}
+
+// newTestReverseMountClient serves src the way a reverse mount does, to a
+// client standing for sshfs.
+func newTestReverseMountClient(t *testing.T, src string, readOnly bool) (*sftp.Client, func()) {
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	handlers, err := newReverseMountHandlers(src, readOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := sftp.NewRequestServer(pipeConn{serverReader, serverWriter}, handlers)
+	go server.Serve()
+
+	client, err := sftp.NewClientPipe(clientReader, clientWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client, func() {
+		server.Close()
+		client.Close()
+	}
+}
+
+func TestReverseMountServesSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src")
+	assert.NoError(t, os.Mkdir(src, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(src, "file"), []byte("file"), 0644))
+
+	client, closeClient := newTestReverseMountClient(t, src, false)
+	defer closeClient()
+
+	f, err := client.Open(path.Join(src, "file"))
+	assert.NoError(t, err)
+	content, err := ioutil.ReadAll(f)
+	assert.NoError(t, err)
+	assert.Equal(t, "file", string(content))
+	f.Close()
+
+	f, err = client.Create(path.Join(src, "new"))
+	assert.NoError(t, err)
+	_, err = f.Write([]byte("new"))
+	assert.NoError(t, err)
+	f.Close()
+
+	content, err = ioutil.ReadFile(filepath.Join(src, "new"))
+	assert.NoError(t, err)
+	assert.Equal(t, "new", string(content))
+
+	infos, err := client.ReadDir(src)
+	assert.NoError(t, err)
+	assert.Len(t, infos, 2)
+}
+
+func TestReverseMountRefusesPathsOutsideSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src")
+	assert.NoError(t, os.Mkdir(src, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "secret"), []byte("secret"), 0600))
+	assert.NoError(t, os.Symlink(filepath.Join(dir, "secret"), filepath.Join(src, "escape")))
+	assert.NoError(t, os.Symlink(dir, filepath.Join(src, "parent")))
+
+	client, closeClient := newTestReverseMountClient(t, src, false)
+	defer closeClient()
+
+	for _, p := range []string{
+		"/etc/passwd",
+		"../",
+		"../secret",
+		path.Join(src, "..", "secret"),
+		path.Join(src, "escape"),
+		path.Join(src, "parent", "secret"),
+	} {
+		// The request server only asks for the file on the first read
+		f, err := client.Open(p)
+		if err == nil {
+			_, err = ioutil.ReadAll(f)
+			f.Close()
+		}
+		assert.Error(t, err, p)
+
+		_, err = client.Stat(p)
+		assert.Error(t, err, p)
+	}
+
+	_, err = client.ReadDir("../")
+	assert.Error(t, err)
+
+	_, err = client.Create(path.Join(src, "parent", "created"))
+	assert.Error(t, err)
+	_, err = os.Stat(filepath.Join(dir, "created"))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.Error(t, client.Remove(path.Join(src, "..", "secret")))
+	_, err = os.Stat(filepath.Join(dir, "secret"))
+	assert.NoError(t, err)
+}
+
+func TestReverseMountReadOnly(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	client, closeClient := newTestReverseMountClient(t, dir, true)
+	defer closeClient()
+
+	_, err = client.Create(path.Join(dir, "new"))
+	assert.Error(t, err)
+	assert.Error(t, client.Mkdir(path.Join(dir, "new")))
+}
@@ -4,6 +4,7 @@ import (
 	"os/exec"
 	"testing"
 
+	"github.com/classmarkets/docker-machine/libmachine/ssh"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,7 +23,7 @@ func TestGetMountCmd(t *testing.T) {
 	cmd, err := getMountCmd("myfunhost:/home/docker/foo", "/tmp/foo", false, &hostInfoLoader)
 
 	expectedArgs := append(
-		baseSSHFSArgs,
+		append(ssh.HostKeyArgs("", ""), baseSSHFSArgs...),
 		"-o",
 		"IdentitiesOnly=yes",
 		"-o",
@@ -51,7 +52,7 @@ func TestGetMountCmdWithoutSshKey(t *testing.T) {
 	cmd, err := getMountCmd("myfunhost:/home/docker/foo", "", false, &hostInfoLoader)
 
 	expectedArgs := append(
-		baseSSHFSArgs,
+		append(ssh.HostKeyArgs("", ""), baseSSHFSArgs...),
 		"user@1.2.3.4:/home/docker/foo",
 		"/home/docker/foo",
 	)
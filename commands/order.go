@@ -0,0 +1,203 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/classmarkets/docker-machine/libmachine/filter"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+)
+
+// orderedActions are the actions run on the machines in the order of their
+// dependencies, the dependencies first unless the action is reversed.
+var orderedActions = map[string]bool{
+	"start":   false,
+	"restart": false,
+	"stop":    true,
+}
+
+// machineDependencies returns the names of the machines each machine
+// depends on among hosts: those it declares, and the Swarm master for the
+// agents of its cluster.
+func machineDependencies(hosts []*host.Host) map[string][]string {
+	names := map[string]bool{}
+	swarmMasters := filter.SwarmMasters(hosts)
+	for _, h := range hosts {
+		names[h.Name] = true
+	}
+
+	dependencies := map[string][]string{}
+	for _, h := range hosts {
+		dependencies[h.Name] = []string{}
+		if h.HostOptions == nil {
+			continue
+		}
+
+		seen := map[string]bool{h.Name: true}
+		add := func(name string) {
+			if names[name] && !seen[name] {
+				seen[name] = true
+				dependencies[h.Name] = append(dependencies[h.Name], name)
+			}
+		}
+
+		for _, name := range h.HostOptions.DependsOn {
+			add(name)
+		}
+
+		if swarmOptions := h.HostOptions.SwarmOptions; swarmOptions != nil && swarmOptions.IsSwarm && !swarmOptions.Master {
+			add(swarmMasters[swarmOptions.Discovery])
+		}
+	}
+
+	return dependencies
+}
+
+type hostsByName []*host.Host
+
+func (h hostsByName) Len() int           { return len(h) }
+func (h hostsByName) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h hostsByName) Less(i, j int) bool { return h[i].Name < h[j].Name }
+
+func startPriority(h *host.Host) int {
+	if h.HostOptions == nil {
+		return 0
+	}
+
+	return h.HostOptions.StartPriority
+}
+
+// dependencyLevels groups the machines in levels, the machines of a level
+// only depend on those of the previous ones. Among the machines whose
+// dependencies are met, those with the highest start priority come first.
+func dependencyLevels(hosts []*host.Host) ([][]*host.Host, error) {
+	dependencies := machineDependencies(hosts)
+
+	remaining := append([]*host.Host{}, hosts...)
+	sort.Sort(hostsByName(remaining))
+
+	done := map[string]bool{}
+	levels := [][]*host.Host{}
+
+	for len(remaining) > 0 {
+		ready := []*host.Host{}
+		for _, h := range remaining {
+			if dependenciesDone(dependencies[h.Name], done) {
+				ready = append(ready, h)
+			}
+		}
+
+		if len(ready) == 0 {
+			names := []string{}
+			for _, h := range remaining {
+				names = append(names, h.Name)
+			}
+			return nil, fmt.Errorf("Circular dependency between the machines %s", strings.Join(names, ", "))
+		}
+
+		highest := startPriority(ready[0])
+		for _, h := range ready {
+			if startPriority(h) > highest {
+				highest = startPriority(h)
+			}
+		}
+
+		level := []*host.Host{}
+		left := []*host.Host{}
+		for _, h := range remaining {
+			if dependenciesDone(dependencies[h.Name], done) && startPriority(h) == highest {
+				level = append(level, h)
+			} else {
+				left = append(left, h)
+			}
+		}
+
+		for _, h := range level {
+			done[h.Name] = true
+		}
+
+		levels = append(levels, level)
+		remaining = left
+	}
+
+	return levels, nil
+}
+
+func dependenciesDone(dependencies []string, done map[string]bool) bool {
+	for _, name := range dependencies {
+		if !done[name] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// runOrderedAction runs the action on the machines level by level, the
+// dependencies first, or last if reverse is set. A machine is skipped when
+// one it waits for failed: a dependency, or a dependent if reverse is set.
+func runOrderedAction(actionName string, hosts []*host.Host, reverse bool) []error {
+	levels, err := dependencyLevels(hosts)
+	if err != nil {
+		return []error{err}
+	}
+
+	waitsFor := machineDependencies(hosts)
+	if reverse {
+		dependents := map[string][]string{}
+		for name, dependencies := range waitsFor {
+			for _, dependency := range dependencies {
+				dependents[dependency] = append(dependents[dependency], name)
+			}
+		}
+		waitsFor = dependents
+
+		for i, j := 0, len(levels)-1; i < j; i, j = i+1, j-1 {
+			levels[i], levels[j] = levels[j], levels[i]
+		}
+	}
+
+	errs := []error{}
+	failed := map[string]bool{}
+
+	for _, level := range levels {
+		machines := []*host.Host{}
+		for _, h := range level {
+			if blocker := failedMachine(waitsFor[h.Name], failed); blocker != "" {
+				failed[h.Name] = true
+				errs = append(errs, fmt.Errorf("Skipped %s of %q: %q failed", actionName, h.Name, blocker))
+				continue
+			}
+			machines = append(machines, h)
+		}
+
+		errorChans := make([]chan error, len(machines))
+		for i, h := range machines {
+			errorChans[i] = make(chan error, 1)
+			go machineCommand(actionName, h, errorChans[i])
+		}
+
+		for i, h := range machines {
+			if err := <-errorChans[i]; err != nil {
+				failed[h.Name] = true
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+func failedMachine(names []string, failed map[string]bool) string {
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		if failed[name] {
+			return name
+		}
+	}
+
+	return ""
+}
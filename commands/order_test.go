@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/classmarkets/docker-machine/drivers/fakedriver"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/state"
+	"github.com/classmarkets/docker-machine/libmachine/swarm"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingStopDriver struct {
+	*fakedriver.Driver
+}
+
+func (d *failingStopDriver) Stop() error {
+	return errors.New("stop failed")
+}
+
+func levelNames(levels [][]*host.Host) [][]string {
+	names := [][]string{}
+	for _, level := range levels {
+		levelNames := []string{}
+		for _, h := range level {
+			levelNames = append(levelNames, h.Name)
+		}
+		names = append(names, levelNames)
+	}
+
+	return names
+}
+
+func TestDependencyLevels(t *testing.T) {
+	hosts := []*host.Host{
+		{
+			Name:        "app",
+			HostOptions: &host.Options{DependsOn: []string{"db", "not-in-group"}},
+		},
+		{
+			Name:        "db",
+			HostOptions: &host.Options{},
+		},
+		{
+			Name: "agent",
+			HostOptions: &host.Options{
+				SwarmOptions: &swarm.Options{IsSwarm: true, Discovery: "token://a"},
+			},
+		},
+		{
+			Name: "master",
+			HostOptions: &host.Options{
+				SwarmOptions: &swarm.Options{IsSwarm: true, Master: true, Discovery: "token://a"},
+			},
+		},
+		{
+			Name:        "cache",
+			HostOptions: &host.Options{StartPriority: 10},
+		},
+		{
+			Name: "other",
+		},
+	}
+
+	levels, err := dependencyLevels(hosts)
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"cache"},
+		{"db", "master", "other"},
+		{"agent", "app"},
+	}, levelNames(levels))
+}
+
+func TestDependencyLevelsCircular(t *testing.T) {
+	hosts := []*host.Host{
+		{
+			Name:        "a",
+			HostOptions: &host.Options{DependsOn: []string{"b"}},
+		},
+		{
+			Name:        "b",
+			HostOptions: &host.Options{DependsOn: []string{"a"}},
+		},
+		{
+			Name: "c",
+		},
+	}
+
+	_, err := dependencyLevels(hosts)
+
+	assert.EqualError(t, err, "Circular dependency between the machines a, b")
+}
+
+func TestRunOrderedActionStopsDependenciesLast(t *testing.T) {
+	master := &host.Host{
+		Name:   "master",
+		Driver: &fakedriver.Driver{MockState: state.Running},
+	}
+	agent := &host.Host{
+		Name:        "agent",
+		Driver:      &failingStopDriver{&fakedriver.Driver{MockState: state.Running}},
+		HostOptions: &host.Options{DependsOn: []string{"master"}},
+	}
+	other := &host.Host{
+		Name:   "other",
+		Driver: &fakedriver.Driver{MockState: state.Running},
+	}
+
+	errs := runOrderedAction("stop", []*host.Host{master, agent, other}, true)
+
+	assert.Equal(t, []error{
+		errors.New("stop failed"),
+		errors.New(`Skipped stop of "master": "agent" failed`),
+	}, errs)
+
+	masterState, _ := master.Driver.GetState()
+	assert.Equal(t, state.Running, masterState)
+
+	otherState, _ := other.Driver.GetState()
+	assert.Equal(t, state.Stopped, otherState)
+}
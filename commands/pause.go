@@ -0,0 +1,7 @@
+package commands
+
+import "github.com/classmarkets/docker-machine/libmachine"
+
+func cmdPause(c CommandLine, api libmachine.API) error {
+	return runAction("pause", c, api)
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/classmarkets/docker-machine/commands/commandstest"
+	"github.com/classmarkets/docker-machine/drivers/fakedriver"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/libmachinetest"
+	"github.com/classmarkets/docker-machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCmdPauseResumeSuspend(t *testing.T) {
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"dev"},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name: "dev",
+				Driver: &fakedriver.Driver{
+					MockState: state.Running,
+				},
+			},
+		},
+	}
+
+	assert.NoError(t, cmdPause(commandLine, api))
+	assert.Equal(t, state.Paused, libmachinetest.State(api, "dev"))
+
+	assert.NoError(t, cmdSuspend(commandLine, api))
+	assert.Equal(t, state.Saved, libmachinetest.State(api, "dev"))
+
+	assert.NoError(t, cmdResume(commandLine, api))
+	assert.Equal(t, state.Running, libmachinetest.State(api, "dev"))
+}
+
+func TestCmdPauseNotSupported(t *testing.T) {
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"dev"},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name:       "dev",
+				DriverName: "nopause",
+				Driver: &basicDriver{&fakedriver.Driver{
+					MockState: state.Running,
+				}},
+			},
+		},
+	}
+
+	err := cmdPause(commandLine, api)
+
+	assert.EqualError(t, err, `Driver "nopause" does not support pause`)
+	assert.Equal(t, state.Running, libmachinetest.State(api, "dev"))
+}
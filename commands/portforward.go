@@ -0,0 +1,273 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/classmarkets/docker-machine/libmachine"
+	"github.com/classmarkets/docker-machine/libmachine/log"
+	"github.com/classmarkets/docker-machine/libmachine/ssh"
+	"github.com/classmarkets/docker-machine/libmachine/state"
+)
+
+const (
+	portForwardPidFile  = "port-forward.pid"
+	portForwardLockFile = "port-forward.lock"
+	portForwardLogFile  = "port-forward.log"
+
+	// portForwardChildEnv is set for the port forwarding started in the
+	// background, which records its PID
+	portForwardChildEnv = "MACHINE_PORT_FORWARD_CHILD"
+)
+
+var errNoForwards = errors.New("Nothing to forward, e.g. 8080:80 forwards the local port 8080 to the port 80 of the machine")
+
+type errPortForwardRunning struct {
+	HostName string
+}
+
+func (e errPortForwardRunning) Error() string {
+	return fmt.Sprintf("Port forwarding to %q is already running in the background, stop it with: %s port-forward --stop %s", e.HostName, os.Args[0], e.HostName)
+}
+
+type errPortForwardNotRunning struct {
+	HostName string
+}
+
+func (e errPortForwardNotRunning) Error() string {
+	return fmt.Sprintf("Port forwarding to %q is not running in the background", e.HostName)
+}
+
+func cmdPortForward(c CommandLine, api libmachine.API) error {
+	nameArgs, specs := splitPortForwardArgs(c.Args())
+
+	target, err := targetHostFromArgs(nameArgs, api)
+	if err != nil {
+		return err
+	}
+
+	pidPath := filepath.Join(api.GetMachinesDir(), target, portForwardPidFile)
+
+	if c.Bool("stop") {
+		return stopPortForward(target, pidPath)
+	}
+
+	forwards, err := parseForwards(specs, c.StringSlice("local"), c.StringSlice("remote"))
+	if err != nil {
+		return err
+	}
+
+	if len(forwards) == 0 {
+		c.ShowHelp()
+		return errNoForwards
+	}
+
+	host, err := api.Load(target)
+	if err != nil {
+		return err
+	}
+
+	currentState, err := host.Driver.GetState()
+	if err != nil {
+		return err
+	}
+
+	if currentState != state.Running {
+		return fmt.Errorf("Error: Cannot forward ports: Host %q is not running", host.Name)
+	}
+
+	if c.Bool("background") {
+		return startPortForward(c, api.GetMachinesDir(), host.Name, pidPath, specs, forwards)
+	}
+
+	if os.Getenv(portForwardChildEnv) != "" {
+		unlock, err := lockPortForward(host.Name, pidPath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	client, err := newNativeClient(host.Driver, "")
+	if err != nil {
+		return err
+	}
+
+	tunnel, err := client.OpenTunnel(forwards)
+	if err != nil {
+		return err
+	}
+	defer tunnel.Close()
+
+	for _, forward := range forwards {
+		log.Infof("Forwarding %s", forward)
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	defer signal.Stop(stop)
+
+	stopped := make(chan struct{})
+	go func() {
+		if _, ok := <-stop; ok {
+			close(stopped)
+			tunnel.Close()
+		}
+	}()
+
+	err = tunnel.Wait()
+
+	select {
+	case <-stopped:
+		return nil
+	default:
+		return fmt.Errorf("Lost the connection to %s: %v", host.Name, err)
+	}
+}
+
+// splitPortForwardArgs splits the machine name from the forwards, it's
+// omitted for the default machine.
+func splitPortForwardArgs(args []string) ([]string, []string) {
+	if len(args) == 0 || strings.Contains(args[0], ":") {
+		return nil, args
+	}
+
+	return args[:1], args[1:]
+}
+
+// parseForwards parses the forwards given as arguments and with -L, which
+// listen locally, and those given with -R, which listen on the machine.
+func parseForwards(args, local, remote []string) ([]ssh.Forward, error) {
+	forwards := []ssh.Forward{}
+
+	for _, spec := range append(args, local...) {
+		forward, err := ssh.ParseForward(spec, false)
+		if err != nil {
+			return nil, err
+		}
+		forwards = append(forwards, forward)
+	}
+
+	for _, spec := range remote {
+		forward, err := ssh.ParseForward(spec, true)
+		if err != nil {
+			return nil, err
+		}
+		forwards = append(forwards, forward)
+	}
+
+	return forwards, nil
+}
+
+// startPortForward runs the port forwarding in the background, which
+// records its PID in the machine directory for --stop.
+func startPortForward(c CommandLine, machinesDir, hostName, pidPath string, specs []string, forwards []ssh.Forward) error {
+	if pid, err := runningPortForward(pidPath); err == nil && pid != 0 {
+		return errPortForwardRunning{hostName}
+	}
+
+	args := []string{"port-forward", hostName}
+	for _, spec := range append(specs, c.StringSlice("local")...) {
+		args = append(args, "--local", spec)
+	}
+	for _, spec := range c.StringSlice("remote") {
+		args = append(args, "--remote", spec)
+	}
+
+	logPath := filepath.Join(machinesDir, hostName, portForwardLogFile)
+	if _, err := startInBackground(c, logPath, args, portForwardChildEnv+"=1"); err != nil {
+		return fmt.Errorf("Error forwarding ports: %s", err)
+	}
+
+	for _, forward := range forwards {
+		log.Infof("Forwarding %s", forward)
+	}
+	log.Infof("Stop forwarding with: %s port-forward --stop %s", os.Args[0], hostName)
+
+	return nil
+}
+
+// stopPortForward stops the port forwarding running in the background.
+func stopPortForward(hostName, pidPath string) error {
+	pid, err := runningPortForward(pidPath)
+	if os.IsNotExist(err) || (err == nil && pid == 0) {
+		return errPortForwardNotRunning{hostName}
+	}
+	if err != nil {
+		return err
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+
+	return stopProcess(process)
+}
+
+// lockPortForward records the PID of the port forwarding running in the
+// background, and locks the lock file next to it for as long as it runs,
+// which tells that the PID is still its own. The returned function removes
+// both when it ends.
+func lockPortForward(hostName, pidPath string) (func(), error) {
+	lockPath := filepath.Join(filepath.Dir(pidPath), portForwardLockFile)
+
+	lock, err := lockFile(lockPath)
+	if err == errFileLocked {
+		return nil, errPortForwardRunning{hostName}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ioutil.WriteFile(pidPath, []byte(strconv.Itoa(os.Getpid())), 0600); err != nil {
+		lock.Close()
+		return nil, err
+	}
+
+	return func() {
+		os.Remove(pidPath)
+		lock.Close()
+		os.Remove(lockPath)
+	}, nil
+}
+
+// runningPortForward returns the PID of the port forwarding running in the
+// background, or 0 if none holds the lock anymore. The files it left after
+// being killed are removed then.
+func runningPortForward(pidPath string) (int, error) {
+	lockPath := filepath.Join(filepath.Dir(pidPath), portForwardLockFile)
+
+	lock, err := lockFile(lockPath)
+	if err == nil {
+		os.Remove(pidPath)
+		lock.Close()
+		os.Remove(lockPath)
+		return 0, nil
+	}
+	if err != errFileLocked {
+		return 0, err
+	}
+
+	return readPidFile(pidPath)
+}
+
+func readPidFile(pidPath string) (int, error) {
+	content, err := ioutil.ReadFile(pidPath)
+	if err != nil {
+		return 0, err
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return 0, fmt.Errorf("Invalid PID file %s: %s", pidPath, err)
+	}
+
+	return pid, nil
+}
@@ -0,0 +1,204 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/classmarkets/docker-machine/commands/commandstest"
+	"github.com/classmarkets/docker-machine/drivers/fakedriver"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/libmachinetest"
+	"github.com/classmarkets/docker-machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitPortForwardArgs(t *testing.T) {
+	nameArgs, specs := splitPortForwardArgs([]string{"dev", "8080:80", "2375:/var/run/docker.sock"})
+	assert.Equal(t, []string{"dev"}, nameArgs)
+	assert.Equal(t, []string{"8080:80", "2375:/var/run/docker.sock"}, specs)
+
+	nameArgs, specs = splitPortForwardArgs([]string{"8080:80"})
+	assert.Empty(t, nameArgs)
+	assert.Equal(t, []string{"8080:80"}, specs)
+
+	nameArgs, specs = splitPortForwardArgs([]string{})
+	assert.Empty(t, nameArgs)
+	assert.Empty(t, specs)
+}
+
+func TestParseForwards(t *testing.T) {
+	forwards, err := parseForwards([]string{"8080:80"}, []string{"2375:/var/run/docker.sock"}, []string{"9000:3000"})
+
+	assert.NoError(t, err)
+	assert.Len(t, forwards, 3)
+	assert.False(t, forwards[0].Remote)
+	assert.False(t, forwards[1].Remote)
+	assert.Equal(t, "unix", forwards[1].Dial.Network)
+	assert.True(t, forwards[2].Remote)
+	assert.Equal(t, "localhost:9000", forwards[2].Listen.Address)
+}
+
+func TestParseForwardsInvalid(t *testing.T) {
+	_, err := parseForwards([]string{"8080"}, nil, nil)
+
+	assert.EqualError(t, err, `Invalid forward "8080": expected [bind_address:]port:host:hostport`)
+}
+
+func TestCmdPortForwardRequiresForwards(t *testing.T) {
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs:    []string{"dev"},
+		LocalFlags: &commandstest.FakeFlagger{},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name: "dev",
+				Driver: &fakedriver.Driver{
+					MockState: state.Running,
+				},
+			},
+		},
+	}
+
+	err := cmdPortForward(commandLine, api)
+
+	assert.Equal(t, errNoForwards, err)
+	assert.True(t, commandLine.HelpShown)
+}
+
+func TestCmdPortForwardRequiresRunningHost(t *testing.T) {
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs:    []string{"dev", "8080:80"},
+		LocalFlags: &commandstest.FakeFlagger{},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name: "dev",
+				Driver: &fakedriver.Driver{
+					MockState: state.Stopped,
+				},
+			},
+		},
+	}
+
+	err := cmdPortForward(commandLine, api)
+
+	assert.EqualError(t, err, `Error: Cannot forward ports: Host "dev" is not running`)
+}
+
+func TestStopPortForwardNotRunning(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pidPath := filepath.Join(dir, portForwardPidFile)
+
+	assert.Equal(t, errPortForwardNotRunning{"dev"}, stopPortForward("dev", pidPath))
+
+	// A stale PID file is removed
+	assert.NoError(t, ioutil.WriteFile(pidPath, []byte("999999999"), 0600))
+	assert.Equal(t, errPortForwardNotRunning{"dev"}, stopPortForward("dev", pidPath))
+	_, err = os.Stat(pidPath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestStopPortForward(t *testing.T) {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep not found")
+	}
+
+	dir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cmd := exec.Command(sleep, "60")
+	assert.NoError(t, cmd.Start())
+
+	// The lock is held by the port forwarding while it runs
+	lock, err := lockFile(filepath.Join(dir, portForwardLockFile))
+	assert.NoError(t, err)
+	defer lock.Close()
+
+	pidPath := filepath.Join(dir, portForwardPidFile)
+	assert.NoError(t, ioutil.WriteFile(pidPath, []byte(strconv.Itoa(cmd.Process.Pid)), 0600))
+
+	assert.NoError(t, stopPortForward("dev", pidPath))
+	assert.Error(t, cmd.Wait())
+}
+
+func TestStopPortForwardReusedPid(t *testing.T) {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep not found")
+	}
+
+	dir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cmd := exec.Command(sleep, "60")
+	assert.NoError(t, cmd.Start())
+	defer cmd.Process.Kill()
+
+	// The PID file was left by a killed port forwarding, and the PID
+	// reused by another process
+	pidPath := filepath.Join(dir, portForwardPidFile)
+	assert.NoError(t, ioutil.WriteFile(pidPath, []byte(strconv.Itoa(cmd.Process.Pid)), 0600))
+
+	assert.Equal(t, errPortForwardNotRunning{"dev"}, stopPortForward("dev", pidPath))
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+	select {
+	case <-exited:
+		t.Error("The process reusing the PID was stopped")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	_, err = os.Stat(pidPath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestLockPortForward(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pidPath := filepath.Join(dir, portForwardPidFile)
+
+	unlock, err := lockPortForward("dev", pidPath)
+	assert.NoError(t, err)
+
+	pid, err := runningPortForward(pidPath)
+	assert.NoError(t, err)
+	assert.Equal(t, os.Getpid(), pid)
+
+	_, err = lockPortForward("dev", pidPath)
+	assert.Equal(t, errPortForwardRunning{"dev"}, err)
+
+	unlock()
+
+	pid, err = runningPortForward(pidPath)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, pid)
+
+	_, err = os.Stat(pidPath)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -1,11 +1,22 @@
 package commands
 
 import (
+	"fmt"
+
 	"github.com/classmarkets/docker-machine/libmachine"
+	"github.com/classmarkets/docker-machine/libmachine/host"
 	"github.com/classmarkets/docker-machine/libmachine/log"
 )
 
 func cmdRegenerateCerts(c CommandLine, api libmachine.API) error {
+	hosts, err := selectHosts(c, api)
+	if err == errSelectionDeclined {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
 	if !c.Bool("force") {
 		ok, err := confirmInput("Regenerate TLS machine certs?  Warning: this is irreversible.")
 		if err != nil {
@@ -19,8 +30,31 @@ func cmdRegenerateCerts(c CommandLine, api libmachine.API) error {
 
 	log.Infof("Regenerating TLS certificates")
 
+	action := "configureAuth"
 	if c.Bool("client-certs") {
-		return runAction("configureAllAuth", c, api)
+		action = "configureAllAuth"
+	}
+
+	if err := runActionOnHosts(action, hosts, api); err != nil {
+		return err
+	}
+
+	return refreshDockerContexts(api, hosts)
+}
+
+// refreshDockerContexts rewrites the Docker contexts of the machines, which
+// hold copies of the certificates.
+func refreshDockerContexts(api libmachine.API, hosts []*host.Host) error {
+	configDir := dockerConfigDir()
+	for _, h := range hosts {
+		if !hasDockerContext(configDir, api.GetMachinesDir(), h.Name) {
+			continue
+		}
+
+		if err := writeDockerContext(configDir, api.GetMachinesDir(), h); err != nil {
+			return fmt.Errorf("Error refreshing the Docker context of %q: %s", h.Name, err)
+		}
 	}
-	return runAction("configureAuth", c, api)
+
+	return nil
 }
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/classmarkets/docker-machine/libmachine"
+	"github.com/classmarkets/docker-machine/libmachine/drivers"
+)
+
+var errNoResizeOptions = errors.New("At least one of --cpus, --memory, --disk-size or --instance-type is required")
+
+func cmdResize(c CommandLine, api libmachine.API) error {
+	if len(c.Args()) > 1 {
+		return ErrTooManyArguments
+	}
+
+	opts := drivers.ResizeOptions{
+		CPU:          c.Int("cpus"),
+		Memory:       c.Int("memory"),
+		DiskSize:     c.Int("disk-size"),
+		InstanceType: c.String("instance-type"),
+	}
+
+	if opts.CPU < 0 || opts.Memory < 0 || opts.DiskSize < 0 {
+		return errors.New("--cpus, --memory and --disk-size must be positive")
+	}
+
+	if opts.IsEmpty() {
+		c.ShowHelp()
+		return errNoResizeOptions
+	}
+
+	target, err := targetHost(c, api)
+	if err != nil {
+		return err
+	}
+
+	h, err := api.Load(target)
+	if err != nil {
+		return err
+	}
+
+	if err := drivers.RequireCapability(h.Driver, h.DriverName, drivers.CapabilityResize); err != nil {
+		return err
+	}
+
+	if err := h.Resize(opts); err != nil {
+		return fmt.Errorf("Error resizing %q: %s", h.Name, err)
+	}
+
+	return api.Save(h)
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/classmarkets/docker-machine/commands/commandstest"
+	"github.com/classmarkets/docker-machine/drivers/fakedriver"
+	"github.com/classmarkets/docker-machine/libmachine/drivers"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/libmachinetest"
+	"github.com/classmarkets/docker-machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCmdResize(t *testing.T) {
+	driver := &fakedriver.Driver{MockState: state.Stopped}
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"dev"},
+		LocalFlags: &commandstest.FakeFlagger{
+			Data: map[string]interface{}{
+				"cpus":   2,
+				"memory": 4096,
+			},
+		},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{{Name: "dev", Driver: driver, HostOptions: &host.Options{}}},
+	}
+
+	err := cmdResize(commandLine, api)
+
+	assert.NoError(t, err)
+	assert.Equal(t, drivers.ResizeOptions{CPU: 2, Memory: 4096}, driver.MockResize)
+	assert.Equal(t, 4096, api.Hosts[0].HostOptions.Memory)
+}
+
+func TestCmdResizeNoOptions(t *testing.T) {
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs:    []string{"dev"},
+		LocalFlags: &commandstest.FakeFlagger{},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{{Name: "dev", Driver: &fakedriver.Driver{}}},
+	}
+
+	err := cmdResize(commandLine, api)
+
+	assert.Equal(t, errNoResizeOptions, err)
+	assert.True(t, commandLine.HelpShown)
+}
+
+func TestCmdResizeNotSupported(t *testing.T) {
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"dev"},
+		LocalFlags: &commandstest.FakeFlagger{
+			Data: map[string]interface{}{
+				"cpus": 2,
+			},
+		},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name:       "dev",
+				DriverName: "noresize",
+				Driver:     &basicDriver{&fakedriver.Driver{}},
+			},
+		},
+	}
+
+	err := cmdResize(commandLine, api)
+
+	assert.EqualError(t, err, `Driver "noresize" does not support resize`)
+}
@@ -12,23 +12,44 @@ import (
 )
 
 func cmdRm(c CommandLine, api libmachine.API) error {
-	if len(c.Args()) == 0 {
+	hostNames := c.Args()
+	selected := c.Bool("all") || len(c.StringSlice("filter")) > 0
+
+	if len(hostNames) == 0 && !selected {
 		c.ShowHelp()
 		return ErrNoMachineSpecified
 	}
 
-	log.Info(fmt.Sprintf("About to remove %s", strings.Join(c.Args(), ", ")))
-	log.Warn("WARNING: This action will delete both local reference and remote instance.")
-
 	force := c.Bool("force")
 	confirm := c.Bool("y")
 	var errorOccurred []string
 
+	if selected {
+		hosts, err := selectHosts(c, api)
+		if err == errSelectionDeclined {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// The machines selected with --filter or --all were confirmed
+		confirm = confirm || len(hostNames) == 0
+
+		hostNames = []string{}
+		for _, h := range hosts {
+			hostNames = append(hostNames, h.Name)
+		}
+	}
+
+	log.Info(fmt.Sprintf("About to remove %s", strings.Join(hostNames, ", ")))
+	log.Warn("WARNING: This action will delete both local reference and remote instance.")
+
 	if !userConfirm(confirm, force) {
 		return nil
 	}
 
-	for _, hostName := range c.Args() {
+	for _, hostName := range hostNames {
 		err := removeRemoteMachine(hostName, api)
 		if err != nil {
 			errorOccurred = collectError(fmt.Sprintf("Error removing host %q: %s", hostName, err), force, errorOccurred)
@@ -39,6 +60,14 @@ func cmdRm(c CommandLine, api libmachine.API) error {
 			if removeErr != nil {
 				errorOccurred = collectError(fmt.Sprintf("Can't remove \"%s\"", hostName), force, errorOccurred)
 			} else {
+				if err := removeDockerContext(dockerConfigDir(), api.GetMachinesDir(), hostName); err != nil {
+					log.Warnf("Error removing the Docker context of %q: %s", hostName, err)
+				}
+				if current, _ := readCurrentMachine(api.GetMachinesDir()); current == hostName {
+					if err := writeCurrentMachine(api.GetMachinesDir(), ""); err != nil {
+						log.Warnf("Error forgetting the current machine: %s", err)
+					}
+				}
 				log.Infof("Successfully removed %s", hostName)
 			}
 		}
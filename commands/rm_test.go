@@ -7,6 +7,7 @@ import (
 
 	"github.com/classmarkets/docker-machine/commands/commandstest"
 	"github.com/classmarkets/docker-machine/drivers/fakedriver"
+	"github.com/classmarkets/docker-machine/libmachine/engine"
 	"github.com/classmarkets/docker-machine/libmachine/host"
 	"github.com/classmarkets/docker-machine/libmachine/libmachinetest"
 	"github.com/stretchr/testify/assert"
@@ -231,3 +232,61 @@ func TestDontRemoveMachineIsRemovalFailsAndNotForced(t *testing.T) {
 
 	assert.True(t, libmachinetest.Exists(api, "machineToRemove1"))
 }
+
+func TestCmdRmFilter(t *testing.T) {
+	commandLine := &commandstest.FakeCommandLine{
+		LocalFlags: &commandstest.FakeFlagger{
+			Data: map[string]interface{}{
+				"filter": []string{"label!=keep"},
+				"y":      true,
+			},
+		},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name:   "machineToRemove",
+				Driver: &fakedriver.Driver{},
+			},
+			{
+				Name:   "machineToKeep",
+				Driver: &fakedriver.Driver{},
+				HostOptions: &host.Options{
+					EngineOptions: &engine.Options{
+						Labels: []string{"keep=yes"},
+					},
+				},
+			},
+		},
+	}
+
+	err := cmdRm(commandLine, api)
+	assert.NoError(t, err)
+
+	assert.False(t, libmachinetest.Exists(api, "machineToRemove"))
+	assert.True(t, libmachinetest.Exists(api, "machineToKeep"))
+}
+
+func TestCmdRmAllWithNames(t *testing.T) {
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"machine"},
+		LocalFlags: &commandstest.FakeFlagger{
+			Data: map[string]interface{}{
+				"all": true,
+			},
+		},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name:   "machine",
+				Driver: &fakedriver.Driver{},
+			},
+		},
+	}
+
+	err := cmdRm(commandLine, api)
+	assert.Equal(t, errAllWithNames, err)
+
+	assert.True(t, libmachinetest.Exists(api, "machine"))
+}
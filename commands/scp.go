@@ -3,21 +3,32 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
+	"github.com/classmarkets/docker-machine/libmachine/drivers"
 	"github.com/classmarkets/docker-machine/libmachine/log"
 	"github.com/classmarkets/docker-machine/libmachine/persist"
+	"github.com/classmarkets/docker-machine/libmachine/ssh"
+	"github.com/pkg/sftp"
 )
 
 var (
 	errWrongNumberArguments = errors.New("Improper number of arguments")
+	errSFTPOnlyOptions      = errors.New("--compress and --resume can't be used with --delta")
+	errPinnedHostKeys       = errors.New("scp can't check the pinned host keys of both machines, copy through a local directory instead")
+
+	// copyRetries is how many times a copy with --resume is resumed after
+	// losing the connection, waiting copyRetryDelay before each
+	copyRetries    = 3
+	copyRetryDelay = 5 * time.Second
 
 	// TODO: possibly move this to ssh package
 	baseSSHArgs = []string{
-		"-o", "StrictHostKeyChecking=no",
-		"-o", "UserKnownHostsFile=/dev/null",
 		"-o", "LogLevel=quiet", // suppress "Warning: Permanently added '[localhost]:2022' (ECDSA) to the list of known hosts."
 	}
 )
@@ -53,6 +64,93 @@ func (s *storeHostInfoLoader) load(name string) (HostInfo, error) {
 	return host.Driver, nil
 }
 
+// copyNatively copies with SFTP, unless --delta asks for rsync. It returns
+// false if the scp binary has to be used instead, because a machine doesn't
+// support SFTP or can't be reached with the native client, and neither the
+// native SSH client nor options only supported with SFTP were asked for.
+func copyNatively(c CommandLine, hostInfoLoader HostInfoLoader) (bool, error) {
+	sftpOnly := c.Bool("compress") || c.Bool("resume")
+
+	if c.Bool("delta") {
+		if sftpOnly {
+			return true, errSFTPOnlyOptions
+		}
+		return false, nil
+	}
+
+	copier := &sftpCopier{
+		recursive: c.Bool("recursive"),
+		compress:  c.Bool("compress"),
+		resume:    c.Bool("resume"),
+	}
+	if !c.Bool("quiet") {
+		copier.progress = os.Stdout
+	}
+
+	copy := func() error {
+		return copyWithSFTP(c.Args()[0], c.Args()[1], copier, hostInfoLoader)
+	}
+
+	var err error
+	if copier.resume {
+		err = copyResuming(copy)
+	} else {
+		err = copy()
+	}
+
+	if canFallBackToScp(err) && ssh.GetDefaultClient() != ssh.Native && !sftpOnly {
+		log.Infof("%s, falling back to scp", err)
+		return false, nil
+	}
+
+	return true, err
+}
+
+// copyResuming runs the copy again when the connection to a machine is lost,
+// resuming where it stopped, copyRetries times at most. The machine may not
+// be reachable yet when it's retried, the connection is tried again then.
+func copyResuming(copy func() error) error {
+	err := copy()
+	if !connectionLost(err) {
+		return err
+	}
+
+	for retry := 1; retry <= copyRetries; retry++ {
+		log.Infof("%s, resuming the copy in %s (%d/%d)", err, copyRetryDelay, retry, copyRetries)
+		time.Sleep(copyRetryDelay)
+
+		err = copy()
+		if _, ok := err.(sftpSessionError); !ok && !connectionLost(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// connectionLost returns whether the connection to a machine was lost
+// during the copy.
+func connectionLost(err error) bool {
+	switch err {
+	case io.EOF, io.ErrUnexpectedEOF, sftp.ErrSshFxConnectionLost, sftp.ErrSshFxNoConnection:
+		return true
+	}
+
+	_, ok := err.(net.Error)
+	return ok
+}
+
+// canFallBackToScp returns whether the copy failed before anything was
+// copied, because SFTP isn't supported or the native client couldn't
+// connect.
+func canFallBackToScp(err error) bool {
+	if _, ok := err.(sftpSessionError); ok {
+		return true
+	}
+
+	return err == ssh.ErrSFTPUnavailable
+}
+
 func getScpCmd(src, dest string, recursive bool, delta bool, quiet bool, hostInfoLoader HostInfoLoader) (*exec.Cmd, error) {
 	var cmdPath string
 	var err error
@@ -80,7 +178,12 @@ func getScpCmd(src, dest string, recursive bool, delta bool, quiet bool, hostInf
 
 	// TODO: Check that "-3" flag is available in user's version of scp.
 	// It is on every system I've checked, but the manual mentioned it's "newer"
-	sshArgs := baseSSHArgs
+	hostKeyOpts, err := hostKeyArgs(srcHost, destHost)
+	if err != nil {
+		return nil, err
+	}
+
+	sshArgs := append(hostKeyOpts, baseSSHArgs...)
 	if !delta {
 		sshArgs = append(sshArgs, "-3")
 		if recursive {
@@ -109,7 +212,7 @@ func getScpCmd(src, dest string, recursive bool, delta bool, quiet bool, hostInf
 	// TODO: Check that "--progress" flag is available in user's version of rsync.
 	// Use quiet mode as a workaround, if it should happen to not be supported...
 	if delta {
-		sshArgs = append([]string{"-e"}, "ssh "+strings.Join(sshArgs, " "))
+		sshArgs = append([]string{"-e"}, "ssh "+strings.Join(quoteRsyncArgs(sshArgs), " "))
 		if !quiet {
 			sshArgs = append([]string{"--progress"}, sshArgs...)
 		}
@@ -130,6 +233,62 @@ func getScpCmd(src, dest string, recursive bool, delta bool, quiet bool, hostInf
 	return cmd, nil
 }
 
+// hostKeyArgs returns the ssh options checking the host keys of the given
+// machines against the keys pinned in their known_hosts files. The options
+// apply to every connection of the command, and keys are pinned by machine
+// name, so they can't check the keys of two machines at once.
+func hostKeyArgs(hostInfos ...HostInfo) ([]string, error) {
+	machines := []HostInfo{}
+	for _, h := range hostInfos {
+		if h != nil {
+			machines = append(machines, h)
+		}
+	}
+
+	pinned := false
+	for _, h := range machines {
+		if sshAuth(h).KnownHostsFile != "" {
+			pinned = true
+		}
+	}
+
+	switch {
+	case !pinned:
+		return ssh.HostKeyArgs("", ""), nil
+	case len(machines) > 1:
+		return nil, errPinnedHostKeys
+	}
+
+	auth := sshAuth(machines[0])
+
+	return ssh.HostKeyArgs(auth.KnownHostsFile, auth.HostKeyAlias), nil
+}
+
+// proxyJumpArgs returns the ssh options reaching the machine through its
+// jump host, if it has one.
+func proxyJumpArgs(h HostInfo) ([]string, error) {
+	jumper, ok := h.(drivers.SSHProxyJumper)
+	if !ok {
+		return nil, nil
+	}
+
+	return ssh.ProxyJumpArgs("ssh", jumper.GetSSHProxyJump())
+}
+
+// quoteRsyncArgs quotes the ssh options containing spaces, like a jump host
+// ProxyCommand, since rsync splits the remote shell command on whitespace.
+// rsync reads a doubled single quote inside single quotes as one.
+func quoteRsyncArgs(args []string) []string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if strings.ContainsAny(arg, " '") {
+			arg = "'" + strings.Replace(arg, "'", "''", -1) + "'"
+		}
+		quoted[i] = arg
+	}
+	return quoted
+}
+
 func missesExplicitSSHKey(hostInfo HostInfo) bool {
 	return hostInfo != nil && hostInfo.GetSSHKeyPath() == ""
 }
@@ -167,6 +326,12 @@ func getInfoForScpArg(hostAndPath string, hostInfoLoader HostInfoLoader) (h Host
 		args = append(args, "-o", fmt.Sprintf("IdentityFile=%q", h.GetSSHKeyPath()))
 	}
 
+	jumpArgs, err := proxyJumpArgs(h)
+	if err != nil {
+		return nil, "", "", nil, err
+	}
+	args = append(args, jumpArgs...)
+
 	return
 }
 
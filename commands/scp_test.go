@@ -1,19 +1,25 @@
 package commands
 
 import (
+	"errors"
+	"io"
 	"os/exec"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/classmarkets/docker-machine/libmachine/ssh"
 	"github.com/stretchr/testify/assert"
 )
 
 type MockHostInfo struct {
-	name        string
-	ip          string
-	sshPort     int
-	sshUsername string
-	sshKeyPath  string
+	name           string
+	ip             string
+	sshPort        int
+	sshUsername    string
+	sshKeyPath     string
+	knownHostsPath string
+	proxyJump      ssh.ProxyJump
 }
 
 func (h *MockHostInfo) GetMachineName() string {
@@ -36,6 +42,14 @@ func (h *MockHostInfo) GetSSHKeyPath() string {
 	return h.sshKeyPath
 }
 
+func (h *MockHostInfo) GetKnownHostsPath() string {
+	return h.knownHostsPath
+}
+
+func (h *MockHostInfo) GetSSHProxyJump() ssh.ProxyJump {
+	return h.proxyJump
+}
+
 type MockHostInfoLoader struct {
 	hostInfo MockHostInfo
 }
@@ -82,6 +96,32 @@ func TestGetInfoForRemoteScpArg(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestGetInfoForScpArgWithProxyJump(t *testing.T) {
+	hostInfoLoader := MockHostInfoLoader{MockHostInfo{
+		proxyJump: ssh.ProxyJump{Address: "jump@bastion:2222"},
+	}}
+
+	_, _, _, opts, err := getInfoForScpArg("myfunhost:/home/docker/foo", &hostInfoLoader)
+	assert.Equal(t, []string{"-o", "ProxyJump=jump@bastion:2222"}, opts)
+	assert.NoError(t, err)
+
+	hostInfoLoader.hostInfo.proxyJump.KeyPath = "/fake/bastion/id_rsa"
+
+	_, _, _, opts, err = getInfoForScpArg("myfunhost:/home/docker/foo", &hostInfoLoader)
+	assert.Equal(t, []string{"-o", "ProxyCommand=ssh -i /fake/bastion/id_rsa -o IdentitiesOnly=yes -p 2222 -W %h:%p jump@bastion"}, opts)
+	assert.NoError(t, err)
+
+	hostInfoLoader.hostInfo.proxyJump.Address = "jump@bastion:port"
+
+	_, _, _, _, err = getInfoForScpArg("myfunhost:/home/docker/foo", &hostInfoLoader)
+	assert.Error(t, err)
+}
+
+func TestQuoteRsyncArgs(t *testing.T) {
+	assert.Equal(t, []string{"-o", "'ProxyCommand=ssh -W %h:%p bastion'", "-o", "Port=22"}, quoteRsyncArgs([]string{"-o", "ProxyCommand=ssh -W %h:%p bastion", "-o", "Port=22"}))
+	assert.Equal(t, []string{"'ProxyCommand=''/my ssh'' -W %h:%p bastion'"}, quoteRsyncArgs([]string{"ProxyCommand='/my ssh' -W %h:%p bastion"}))
+}
+
 func TestHostLocation(t *testing.T) {
 	arg, err := generateLocationArg(nil, "user1", "/home/docker/foo")
 
@@ -117,7 +157,7 @@ func TestGetScpCmd(t *testing.T) {
 	cmd, err := getScpCmd("/tmp/foo", "myfunhost:/home/docker/foo", true, false, false, &hostInfoLoader)
 
 	expectedArgs := append(
-		baseSSHArgs,
+		append(ssh.HostKeyArgs("", ""), baseSSHArgs...),
 		"-3",
 		"-r",
 		"-o",
@@ -144,7 +184,7 @@ func TestGetScpCmdWithoutSshKey(t *testing.T) {
 	cmd, err := getScpCmd("/tmp/foo", "myfunhost:/home/docker/foo", true, false, false, &hostInfoLoader)
 
 	expectedArgs := append(
-		baseSSHArgs,
+		append(ssh.HostKeyArgs("", ""), baseSSHArgs...),
 		"-3",
 		"-r",
 		"/tmp/foo",
@@ -167,7 +207,7 @@ func TestGetScpCmdWithDelta(t *testing.T) {
 	expectedArgs := append(
 		[]string{"--progress"},
 		"-e",
-		"ssh "+strings.Join(baseSSHArgs, " "),
+		"ssh "+strings.Join(append(ssh.HostKeyArgs("", ""), baseSSHArgs...), " "),
 		"-r",
 		"/tmp/foo",
 		"user@1.2.3.4:/home/docker/foo",
@@ -177,3 +217,71 @@ func TestGetScpCmdWithDelta(t *testing.T) {
 	assert.Equal(t, expectedCmd, cmd)
 	assert.NoError(t, err)
 }
+
+func TestHostKeyArgs(t *testing.T) {
+	pinned := &MockHostInfo{name: "dev", knownHostsPath: "/store/machines/dev/known_hosts"}
+	otherPinned := &MockHostInfo{name: "prod", knownHostsPath: "/store/machines/prod/known_hosts"}
+	unpinned := &MockHostInfo{name: "old"}
+	otherUnpinned := &MockHostInfo{name: "older"}
+
+	args, err := hostKeyArgs()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"-o", "StrictHostKeyChecking=no",
+		"-o", "UserKnownHostsFile=/dev/null",
+	}, args)
+
+	args, err = hostKeyArgs(nil, pinned)
+	assert.NoError(t, err)
+	assert.Equal(t, ssh.HostKeyArgs("/store/machines/dev/known_hosts", "dev"), args)
+
+	args, err = hostKeyArgs(unpinned, otherUnpinned)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"-o", "StrictHostKeyChecking=no",
+		"-o", "UserKnownHostsFile=/dev/null",
+	}, args)
+
+	// The key of a machine isn't checked against the other one's
+	_, err = hostKeyArgs(pinned, otherPinned)
+	assert.Equal(t, errPinnedHostKeys, err)
+
+	_, err = hostKeyArgs(pinned, unpinned)
+	assert.Equal(t, errPinnedHostKeys, err)
+}
+
+func TestCopyResuming(t *testing.T) {
+	defer func(delay time.Duration) { copyRetryDelay = delay }(copyRetryDelay)
+	copyRetryDelay = 0
+
+	// The connection is lost, then the machine can't be reached for a
+	// while before the copy completes
+	errs := []error{io.ErrUnexpectedEOF, sftpSessionError{errors.New("connection refused")}, nil}
+	attempts := 0
+	err := copyResuming(func() error {
+		attempts++
+		return errs[attempts-1]
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	// Failing to connect at first isn't retried
+	attempts = 0
+	err = copyResuming(func() error {
+		attempts++
+		return sftpSessionError{errors.New("unable to authenticate")}
+	})
+
+	assert.EqualError(t, err, "unable to authenticate")
+	assert.Equal(t, 1, attempts)
+
+	attempts = 0
+	err = copyResuming(func() error {
+		attempts++
+		return io.EOF
+	})
+
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, copyRetries+1, attempts)
+}
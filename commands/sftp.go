@@ -0,0 +1,762 @@
+package commands
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/classmarkets/docker-machine/libmachine/drivers"
+	"github.com/classmarkets/docker-machine/libmachine/log"
+	"github.com/classmarkets/docker-machine/libmachine/ssh"
+	"github.com/docker/docker/pkg/term"
+	"github.com/docker/go-units"
+	"github.com/pkg/sftp"
+)
+
+const (
+	progressInterval = 200 * time.Millisecond
+
+	// maxSymlinks is how many symbolic links are followed resolving a path,
+	// as many as Linux does
+	maxSymlinks = 40
+)
+
+var errCompressionNotSupported = errors.New("Compression is not supported by the connection to the machine")
+
+// fileSystem is one end of a copy made with SFTP, either the local file
+// system or the file system of a machine.
+type fileSystem interface {
+	Stat(name string) (os.FileInfo, error)
+	ReadDir(name string) ([]os.FileInfo, error)
+	Open(name string) (io.ReadCloser, error)
+	Create(name string) (io.WriteCloser, error)
+
+	// OpenAt opens an existing file for writing from offset on
+	OpenAt(name string, offset int64) (io.WriteCloser, error)
+
+	// OpenCompressed reads a file as a gzip stream
+	OpenCompressed(name string) (io.ReadCloser, error)
+
+	// CreateCompressed writes a file from a gzip stream
+	CreateCompressed(name string) (io.WriteCloser, error)
+
+	Mkdir(name string) error
+
+	// Remove removes a file, a symbolic link or an empty directory
+	Remove(name string) error
+
+	// Rename renames a file, replacing newname if it exists
+	Rename(oldname, newname string) error
+
+	Chmod(name string, mode os.FileMode) error
+	Chtimes(name string, atime, mtime time.Time) error
+
+	// RealPath returns the absolute path with its symbolic links resolved
+	RealPath(name string) (string, error)
+
+	Join(elem ...string) string
+	Base(name string) string
+}
+
+type localFileSystem struct{}
+
+func (localFileSystem) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (localFileSystem) ReadDir(name string) ([]os.FileInfo, error) {
+	return ioutil.ReadDir(name)
+}
+
+func (localFileSystem) Open(name string) (io.ReadCloser, error) {
+	return os.Open(name)
+}
+
+func (localFileSystem) Create(name string) (io.WriteCloser, error) {
+	return os.Create(name)
+}
+
+func (localFileSystem) OpenAt(name string, offset int64) (io.WriteCloser, error) {
+	f, err := os.OpenFile(name, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (localFileSystem) OpenCompressed(name string) (io.ReadCloser, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer f.Close()
+
+		gz := gzip.NewWriter(pw)
+		_, err := io.Copy(gz, f)
+		if err == nil {
+			err = gz.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	return pr, nil
+}
+
+func (localFileSystem) CreateCompressed(name string) (io.WriteCloser, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		defer f.Close()
+
+		gz, err := gzip.NewReader(pr)
+		if err == nil {
+			_, err = io.Copy(f, gz)
+		}
+		pr.CloseWithError(err)
+		done <- err
+	}()
+
+	return &gunzipWriter{
+		PipeWriter: pw,
+		done:       done,
+	}, nil
+}
+
+// gunzipWriter decompresses what's written to it in the background, Close
+// waits until everything is written.
+type gunzipWriter struct {
+	*io.PipeWriter
+	done chan error
+}
+
+func (w *gunzipWriter) Close() error {
+	if err := w.PipeWriter.Close(); err != nil {
+		return err
+	}
+
+	return <-w.done
+}
+
+func (localFileSystem) Mkdir(name string) error {
+	return os.Mkdir(name, 0755)
+}
+
+func (localFileSystem) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (localFileSystem) Rename(oldname, newname string) error {
+	return os.Rename(oldname, newname)
+}
+
+func (localFileSystem) Chmod(name string, mode os.FileMode) error {
+	return os.Chmod(name, mode)
+}
+
+func (localFileSystem) Chtimes(name string, atime, mtime time.Time) error {
+	return os.Chtimes(name, atime, mtime)
+}
+
+func (localFileSystem) RealPath(name string) (string, error) {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.EvalSymlinks(abs)
+}
+
+func (localFileSystem) Join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+func (localFileSystem) Base(name string) string {
+	return filepath.Base(name)
+}
+
+// commandRunner runs the commands compressing and decompressing files on a
+// machine.
+type commandRunner interface {
+	CommandReader(command string) (io.ReadCloser, error)
+	CommandWriter(command string) (io.WriteCloser, error)
+}
+
+type remoteFileSystem struct {
+	client *sftp.Client
+
+	// commands is nil if commands can't be run on the machine
+	commands commandRunner
+}
+
+func (fs remoteFileSystem) Stat(name string) (os.FileInfo, error) {
+	return fs.client.Stat(name)
+}
+
+func (fs remoteFileSystem) ReadDir(name string) ([]os.FileInfo, error) {
+	return fs.client.ReadDir(name)
+}
+
+func (fs remoteFileSystem) Open(name string) (io.ReadCloser, error) {
+	return fs.client.Open(name)
+}
+
+func (fs remoteFileSystem) Create(name string) (io.WriteCloser, error) {
+	return fs.client.Create(name)
+}
+
+func (fs remoteFileSystem) OpenAt(name string, offset int64) (io.WriteCloser, error) {
+	f, err := fs.client.OpenFile(name, os.O_WRONLY)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (fs remoteFileSystem) OpenCompressed(name string) (io.ReadCloser, error) {
+	if fs.commands == nil {
+		return nil, errCompressionNotSupported
+	}
+
+	return fs.commands.CommandReader("gzip -c " + shellQuote(name))
+}
+
+func (fs remoteFileSystem) CreateCompressed(name string) (io.WriteCloser, error) {
+	if fs.commands == nil {
+		return nil, errCompressionNotSupported
+	}
+
+	return fs.commands.CommandWriter("gunzip -c > " + shellQuote(name))
+}
+
+func (fs remoteFileSystem) Mkdir(name string) error {
+	return fs.client.Mkdir(name)
+}
+
+func (fs remoteFileSystem) Remove(name string) error {
+	return fs.client.Remove(name)
+}
+
+func (fs remoteFileSystem) Rename(oldname, newname string) error {
+	if err := fs.client.PosixRename(oldname, newname); err == nil {
+		return nil
+	}
+
+	// Without the posix-rename extension, SFTP doesn't replace an existing
+	// file
+	fs.client.Remove(newname)
+
+	return fs.client.Rename(oldname, newname)
+}
+
+func (fs remoteFileSystem) Chmod(name string, mode os.FileMode) error {
+	return fs.client.Chmod(name, mode)
+}
+
+func (fs remoteFileSystem) Chtimes(name string, atime, mtime time.Time) error {
+	return fs.client.Chtimes(name, atime, mtime)
+}
+
+// RealPath resolves the symbolic links one path element at a time, the
+// SFTP client doesn't expose the realpath request.
+func (fs remoteFileSystem) RealPath(name string) (string, error) {
+	if !path.IsAbs(name) {
+		wd, err := fs.client.Getwd()
+		if err != nil {
+			return "", err
+		}
+		name = path.Join(wd, name)
+	}
+
+	resolved := "/"
+	rest := strings.Split(name, "/")
+	for links := 0; len(rest) > 0; {
+		elem := rest[0]
+		rest = rest[1:]
+
+		switch elem {
+		case "", ".":
+			continue
+		case "..":
+			resolved = path.Dir(resolved)
+			continue
+		}
+
+		next := path.Join(resolved, elem)
+		info, err := fs.client.Lstat(next)
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+
+		if links++; links > maxSymlinks {
+			return "", fmt.Errorf("Too many levels of symbolic links in %s", name)
+		}
+
+		target, err := fs.client.ReadLink(next)
+		if err != nil {
+			return "", err
+		}
+		if path.IsAbs(target) {
+			resolved = "/"
+		}
+		rest = append(strings.Split(target, "/"), rest...)
+	}
+
+	return resolved, nil
+}
+
+func (fs remoteFileSystem) Join(elem ...string) string {
+	return path.Join(elem...)
+}
+
+func (fs remoteFileSystem) Base(name string) string {
+	return path.Base(name)
+}
+
+// sftpCopier copies files and directories between file systems, keeping
+// their modes and modification times like scp -p.
+type sftpCopier struct {
+	recursive bool
+
+	// compress gzips the files while they are transferred, which is done on
+	// the machines when copying between two of them
+	compress bool
+
+	// resume skips the files which were already copied and continues the
+	// partially copied ones. The files are then copied into a partial file,
+	// which is renamed once complete.
+	resume bool
+
+	// progress is where the progress of each file is printed, nothing is
+	// printed if it's nil
+	progress io.Writer
+
+	// dirs are the real paths of the directories being copied, a symbolic
+	// link to one of them would be copied endlessly
+	dirs map[string]bool
+}
+
+// copy copies srcPath into dstPath, or into the directory dstPath if it
+// already exists.
+func (c *sftpCopier) copy(src fileSystem, srcPath string, dst fileSystem, dstPath string) error {
+	info, err := src.Stat(srcPath)
+	if err != nil {
+		return err
+	}
+
+	if dstInfo, err := dst.Stat(dstPath); err == nil && dstInfo.IsDir() {
+		dstPath = dst.Join(dstPath, src.Base(srcPath))
+	}
+
+	return c.copyPath(src, srcPath, info, dst, dstPath)
+}
+
+func (c *sftpCopier) copyPath(src fileSystem, srcPath string, info os.FileInfo, dst fileSystem, dstPath string) error {
+	if info.IsDir() {
+		if !c.recursive {
+			return fmt.Errorf("%s is a directory, use --recursive to copy it", srcPath)
+		}
+		return c.copyDir(src, srcPath, info, dst, dstPath)
+	}
+
+	return c.copyFile(src, srcPath, info, dst, dstPath)
+}
+
+func (c *sftpCopier) copyDir(src fileSystem, srcPath string, info os.FileInfo, dst fileSystem, dstPath string) error {
+	realPath, err := src.RealPath(srcPath)
+	if err != nil {
+		return err
+	}
+	if c.dirs[realPath] {
+		log.Warnf("Skipping %s, it links to a directory containing it", srcPath)
+		return nil
+	}
+
+	if c.dirs == nil {
+		c.dirs = map[string]bool{}
+	}
+	c.dirs[realPath] = true
+	defer delete(c.dirs, realPath)
+
+	if err := dst.Mkdir(dstPath); err != nil {
+		if dstInfo, statErr := dst.Stat(dstPath); statErr != nil || !dstInfo.IsDir() {
+			return err
+		}
+	}
+
+	entries, err := src.ReadDir(srcPath)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		entryPath := src.Join(srcPath, entry.Name())
+
+		// Like scp, symbolic links are copied as the files they point to
+		if entry.Mode()&os.ModeSymlink != 0 {
+			if entry, err = src.Stat(entryPath); err != nil {
+				return err
+			}
+		}
+
+		if err := c.copyPath(src, entryPath, entry, dst, dst.Join(dstPath, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return preserveFileInfo(dst, dstPath, info)
+}
+
+func (c *sftpCopier) copyFile(src fileSystem, srcPath string, info os.FileInfo, dst fileSystem, dstPath string) error {
+	target := dstPath
+	offset := int64(0)
+	if c.resume {
+		if dstInfo, err := dst.Stat(dstPath); err == nil && !dstInfo.IsDir() && dstInfo.Size() == info.Size() && dstInfo.ModTime().Unix() == info.ModTime().Unix() {
+			log.Debugf("Skipping %s, it was already copied", srcPath)
+			return nil
+		}
+
+		// Only the partial file is continued, a smaller file at the
+		// destination may be another version. A compressed copy can't be
+		// continued in the middle of the stream.
+		target = partialPath(dstPath)
+		if partInfo, err := dst.Stat(target); err == nil && !partInfo.IsDir() && partInfo.Size() <= info.Size() && !c.compress {
+			offset = partInfo.Size()
+		}
+	}
+
+	r, w, err := c.open(src, srcPath, dst, target, offset)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	var reader io.Reader = r
+	if c.progress != nil {
+		progress := &progressWriter{
+			out:      c.progress,
+			terminal: isTerminal(c.progress),
+			name:     src.Base(srcPath),
+			total:    info.Size(),
+			written:  offset,
+		}
+		if c.compress {
+			// Only the compressed size is known while transferring
+			progress.total = -1
+		}
+		defer progress.done()
+		reader = io.TeeReader(r, progress)
+	}
+
+	if _, err := io.Copy(w, reader); err != nil {
+		w.Close()
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	if err := preserveFileInfo(dst, target, info); err != nil {
+		return err
+	}
+
+	if target != dstPath {
+		return dst.Rename(target, dstPath)
+	}
+
+	return nil
+}
+
+// partialPath is where a file is copied to until it's complete, when the
+// copy can be resumed.
+func partialPath(name string) string {
+	return name + ".part"
+}
+
+// open opens the source and destination of a file copy, the copy continues
+// at offset when resuming.
+func (c *sftpCopier) open(src fileSystem, srcPath string, dst fileSystem, dstPath string, offset int64) (io.ReadCloser, io.WriteCloser, error) {
+	if c.compress {
+		r, err := src.OpenCompressed(srcPath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		w, err := dst.CreateCompressed(dstPath)
+		if err != nil {
+			r.Close()
+			return nil, nil, err
+		}
+
+		return r, w, nil
+	}
+
+	r, err := src.Open(srcPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if offset == 0 {
+		w, err := dst.Create(dstPath)
+		if err != nil {
+			r.Close()
+			return nil, nil, err
+		}
+
+		return r, w, nil
+	}
+
+	log.Debugf("Resuming %s at %d bytes", srcPath, offset)
+
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		r.Close()
+		return nil, nil, fmt.Errorf("Unable to resume copying %s", srcPath)
+	}
+
+	if _, err := seeker.Seek(offset, io.SeekStart); err != nil {
+		r.Close()
+		return nil, nil, err
+	}
+
+	w, err := dst.OpenAt(dstPath, offset)
+	if err != nil {
+		r.Close()
+		return nil, nil, err
+	}
+
+	return r, w, nil
+}
+
+func preserveFileInfo(fs fileSystem, name string, info os.FileInfo) error {
+	if err := fs.Chmod(name, info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	return fs.Chtimes(name, info.ModTime(), info.ModTime())
+}
+
+// progressWriter prints how much of a file was copied, at most every
+// progressInterval. The percentage is left out if the total is negative.
+// Unless out is a terminal, where the line is updated in place, only the
+// final line is printed.
+type progressWriter struct {
+	out         io.Writer
+	terminal    bool
+	name        string
+	total       int64
+	written     int64
+	lastPrinted time.Time
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+
+	if p.terminal && time.Since(p.lastPrinted) >= progressInterval {
+		p.print()
+	}
+
+	return len(b), nil
+}
+
+func (p *progressWriter) done() {
+	p.print()
+	fmt.Fprintln(p.out)
+}
+
+func (p *progressWriter) print() {
+	p.lastPrinted = time.Now()
+
+	if p.terminal {
+		fmt.Fprint(p.out, "\r")
+	}
+
+	if p.total < 0 {
+		fmt.Fprintf(p.out, "%s %s", p.name, units.HumanSize(float64(p.written)))
+		return
+	}
+
+	percent := int64(100)
+	if p.total > 0 {
+		percent = p.written * 100 / p.total
+	}
+
+	fmt.Fprintf(p.out, "%s %3d%% %s", p.name, percent, units.HumanSize(float64(p.written)))
+}
+
+// isTerminal returns whether w writes to a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(f.Fd())
+}
+
+// copyWithSFTP copies between the local file system and machines over SFTP
+// with the native SSH client. Files copied between two machines are
+// streamed through the local host, neither machine needs credentials for the
+// other. It returns ssh.ErrSFTPUnavailable if the SSH server of a machine
+// doesn't support SFTP.
+func copyWithSFTP(src, dest string, copier *sftpCopier, hostInfoLoader HostInfoLoader) error {
+	srcHost, srcUser, srcPath, _, err := getInfoForScpArg(src, hostInfoLoader)
+	if err != nil {
+		return err
+	}
+
+	destHost, destUser, destPath, _, err := getInfoForScpArg(dest, hostInfoLoader)
+	if err != nil {
+		return err
+	}
+
+	srcFS, closeSrc, err := openFileSystem(srcHost, srcUser)
+	if err != nil {
+		return err
+	}
+	defer closeSrc()
+
+	destFS, closeDest, err := openFileSystem(destHost, destUser)
+	if err != nil {
+		return err
+	}
+	defer closeDest()
+
+	return copier.copy(srcFS, remotePath(srcHost, srcPath), destFS, remotePath(destHost, destPath))
+}
+
+// remotePath turns the empty path of "machine:" and ~ into the home
+// directory, where the SFTP session starts, and the paths under ~ into
+// paths relative to it, as the remote shell would expand them.
+func remotePath(h HostInfo, p string) string {
+	if h == nil {
+		return p
+	}
+
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		p = strings.TrimLeft(strings.TrimPrefix(p, "~"), "/")
+	}
+
+	if p == "" {
+		return "."
+	}
+
+	return p
+}
+
+// sftpSessionError is an error connecting or authenticating to a machine
+// with the native client, the scp binary may still get through with the
+// SSH configuration of the user.
+type sftpSessionError struct {
+	err error
+}
+
+func (e sftpSessionError) Error() string {
+	return e.err.Error()
+}
+
+// openFileSystem opens an SFTP session to the machine, or returns the local
+// file system if there's no machine.
+func openFileSystem(h HostInfo, user string) (fileSystem, func(), error) {
+	if h == nil {
+		return localFileSystem{}, func() {}, nil
+	}
+
+	client, err := newNativeClient(h, user)
+	if err != nil {
+		return nil, nil, sftpSessionError{err}
+	}
+
+	sftpClient, err := client.NewSFTPClient()
+	if err == ssh.ErrSFTPUnavailable {
+		return nil, nil, err
+	}
+	if err != nil {
+		return nil, nil, sftpSessionError{err}
+	}
+
+	return remoteFileSystem{sftpClient.Client, sftpClient}, func() {
+		if err := sftpClient.Close(); err != nil {
+			log.Debugf("Error closing SFTP session: %s", err)
+		}
+	}, nil
+}
+
+// newNativeClient returns a native SSH client connecting to the machine as
+// user, or as the SSH user of the machine if it's empty.
+func newNativeClient(h HostInfo, user string) (*ssh.NativeClient, error) {
+	if user == "" {
+		user = h.GetSSHUsername()
+	}
+
+	address, err := h.GetSSHHostname()
+	if err != nil {
+		return nil, err
+	}
+
+	port, err := h.GetSSHPort()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := ssh.NewNativeClient(user, address, port, sshAuth(h))
+	if err != nil {
+		return nil, err
+	}
+
+	return client.(*ssh.NativeClient), nil
+}
+
+// sshAuth returns the credentials, pinned host key and jump host used to
+// connect to the machine.
+func sshAuth(h HostInfo) *ssh.Auth {
+	auth := &ssh.Auth{
+		HostKeyAlias: h.GetMachineName(),
+	}
+
+	if h.GetSSHKeyPath() != "" {
+		auth.Keys = []string{h.GetSSHKeyPath()}
+	}
+
+	if pather, ok := h.(drivers.KnownHostsPather); ok {
+		auth.KnownHostsFile = pather.GetKnownHostsPath()
+	}
+
+	if jumper, ok := h.(drivers.SSHProxyJumper); ok {
+		auth.ProxyJump = jumper.GetSSHProxyJump()
+	}
+
+	return auth
+}
+
+// shellQuote quotes a path for the shell of a machine.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
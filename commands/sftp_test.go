@@ -0,0 +1,407 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/classmarkets/docker-machine/libmachine/ssh"
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/assert"
+)
+
+type pipeConn struct {
+	io.Reader
+	io.WriteCloser
+}
+
+// newTestRemoteFileSystem serves the local file system over an in-process
+// SFTP server.
+func newTestRemoteFileSystem(t *testing.T) (remoteFileSystem, func()) {
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	server, err := sftp.NewServer(pipeConn{serverReader, serverWriter})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve()
+
+	client, err := sftp.NewClientPipe(clientReader, clientWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return remoteFileSystem{client: client, commands: localCommandRunner{}}, func() {
+		// Closing the server first ends the receive loop of the client
+		server.Close()
+		client.Close()
+	}
+}
+
+// localCommandRunner runs the commands of a remote file system locally.
+type localCommandRunner struct{}
+
+func (localCommandRunner) CommandReader(command string) (io.ReadCloser, error) {
+	cmd := exec.Command("sh", "-c", command)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	return &localCommand{ReadCloser: stdout, cmd: cmd}, cmd.Start()
+}
+
+func (localCommandRunner) CommandWriter(command string) (io.WriteCloser, error) {
+	cmd := exec.Command("sh", "-c", command)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	return &localCommand{WriteCloser: stdin, cmd: cmd}, cmd.Start()
+}
+
+type localCommand struct {
+	io.ReadCloser
+	io.WriteCloser
+	cmd *exec.Cmd
+}
+
+func (c *localCommand) Close() error {
+	if c.WriteCloser != nil {
+		c.WriteCloser.Close()
+	}
+	return c.cmd.Wait()
+}
+
+func newTestTree(t *testing.T) string {
+	root, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := filepath.Join(root, "src")
+	assert.NoError(t, os.MkdirAll(filepath.Join(src, "sub"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(src, "run.sh"), []byte("#!/bin/sh\n"), 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(src, "sub", "data"), []byte("data"), 0644))
+
+	mtime := time.Date(2015, time.November, 20, 12, 0, 0, 0, time.UTC)
+	assert.NoError(t, os.Chtimes(filepath.Join(src, "run.sh"), mtime, mtime))
+
+	return root
+}
+
+func assertCopiedTree(t *testing.T, dst string) {
+	content, err := ioutil.ReadFile(filepath.Join(dst, "sub", "data"))
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(content))
+
+	info, err := os.Stat(filepath.Join(dst, "run.sh"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+	assert.True(t, info.ModTime().Equal(time.Date(2015, time.November, 20, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestSFTPCopyLocal(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	copier := &sftpCopier{recursive: true}
+	err := copier.copy(localFileSystem{}, filepath.Join(root, "src"), localFileSystem{}, filepath.Join(root, "dst"))
+
+	assert.NoError(t, err)
+	assertCopiedTree(t, filepath.Join(root, "dst"))
+}
+
+func TestSFTPCopyIntoExistingDirectory(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	assert.NoError(t, os.Mkdir(filepath.Join(root, "dst"), 0755))
+
+	copier := &sftpCopier{}
+	err := copier.copy(localFileSystem{}, filepath.Join(root, "src", "run.sh"), localFileSystem{}, filepath.Join(root, "dst"))
+
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(root, "dst", "run.sh"))
+	assert.NoError(t, err)
+}
+
+func TestSFTPCopyDirectoryWithoutRecursive(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	copier := &sftpCopier{}
+	err := copier.copy(localFileSystem{}, filepath.Join(root, "src"), localFileSystem{}, filepath.Join(root, "dst"))
+
+	assert.EqualError(t, err, filepath.Join(root, "src")+" is a directory, use --recursive to copy it")
+}
+
+func TestSFTPCopyToMachine(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	remote, closeRemote := newTestRemoteFileSystem(t)
+	defer closeRemote()
+
+	copier := &sftpCopier{recursive: true}
+	err := copier.copy(localFileSystem{}, filepath.Join(root, "src"), remote, filepath.ToSlash(filepath.Join(root, "dst")))
+
+	assert.NoError(t, err)
+	assertCopiedTree(t, filepath.Join(root, "dst"))
+}
+
+func TestSFTPCopyBetweenMachines(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	src, closeSrc := newTestRemoteFileSystem(t)
+	defer closeSrc()
+
+	dst, closeDst := newTestRemoteFileSystem(t)
+	defer closeDst()
+
+	copier := &sftpCopier{recursive: true}
+	err := copier.copy(src, filepath.ToSlash(filepath.Join(root, "src")), dst, filepath.ToSlash(filepath.Join(root, "dst")))
+
+	assert.NoError(t, err)
+	assertCopiedTree(t, filepath.Join(root, "dst"))
+}
+
+func TestSFTPCopyProgress(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	progress := &bytes.Buffer{}
+	copier := &sftpCopier{progress: progress}
+	err := copier.copy(localFileSystem{}, filepath.Join(root, "src", "sub", "data"), localFileSystem{}, filepath.Join(root, "data"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "data 100% 4 B\n", progress.String())
+}
+
+func TestProgressWriterTerminal(t *testing.T) {
+	out := &bytes.Buffer{}
+	progress := &progressWriter{out: out, terminal: true, name: "data", total: 4}
+
+	progress.Write([]byte("da"))
+	progress.Write([]byte("ta"))
+	progress.done()
+
+	assert.Equal(t, "\rdata  50% 2 B\rdata 100% 4 B\n", out.String())
+}
+
+func TestSFTPCopySymlinkCycle(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	assert.NoError(t, os.Symlink(filepath.Join(root, "src"), filepath.Join(root, "src", "sub", "loop")))
+
+	copier := &sftpCopier{recursive: true}
+	err := copier.copy(localFileSystem{}, filepath.Join(root, "src"), localFileSystem{}, filepath.Join(root, "dst"))
+
+	assert.NoError(t, err)
+	assertCopiedTree(t, filepath.Join(root, "dst"))
+	_, err = os.Stat(filepath.Join(root, "dst", "sub", "loop"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSFTPCopySymlinkCycleFromMachine(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	assert.NoError(t, os.Symlink("..", filepath.Join(root, "src", "sub", "loop")))
+
+	remote, closeRemote := newTestRemoteFileSystem(t)
+	defer closeRemote()
+
+	copier := &sftpCopier{recursive: true}
+	err := copier.copy(remote, filepath.Join(root, "src"), localFileSystem{}, filepath.Join(root, "dst"))
+
+	assert.NoError(t, err)
+	assertCopiedTree(t, filepath.Join(root, "dst"))
+	_, err = os.Stat(filepath.Join(root, "dst", "sub", "loop"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCanFallBackToScp(t *testing.T) {
+	assert.True(t, canFallBackToScp(ssh.ErrSFTPUnavailable))
+	assert.True(t, canFallBackToScp(sftpSessionError{errors.New("ssh: handshake failed: ssh: unable to authenticate")}))
+	assert.False(t, canFallBackToScp(errors.New("permission denied")))
+	assert.False(t, canFallBackToScp(nil))
+}
+
+func TestSFTPCopyCompressed(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	copier := &sftpCopier{recursive: true, compress: true}
+	err := copier.copy(localFileSystem{}, filepath.Join(root, "src"), localFileSystem{}, filepath.Join(root, "dst"))
+
+	assert.NoError(t, err)
+	assertCopiedTree(t, filepath.Join(root, "dst"))
+}
+
+func TestSFTPCopyCompressedBetweenMachines(t *testing.T) {
+	if _, err := exec.LookPath("gzip"); err != nil {
+		t.Skip("gzip is not installed")
+	}
+
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	src, closeSrc := newTestRemoteFileSystem(t)
+	defer closeSrc()
+
+	dst, closeDst := newTestRemoteFileSystem(t)
+	defer closeDst()
+
+	copier := &sftpCopier{recursive: true, compress: true}
+	err := copier.copy(src, filepath.ToSlash(filepath.Join(root, "src")), dst, filepath.ToSlash(filepath.Join(root, "dst")))
+
+	assert.NoError(t, err)
+	assertCopiedTree(t, filepath.Join(root, "dst"))
+}
+
+func TestSFTPCopyCompressedNotSupported(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	remote, closeRemote := newTestRemoteFileSystem(t)
+	defer closeRemote()
+	remote.commands = nil
+
+	copier := &sftpCopier{compress: true}
+	err := copier.copy(localFileSystem{}, filepath.Join(root, "src", "run.sh"), remote, filepath.ToSlash(filepath.Join(root, "run.sh")))
+
+	assert.Equal(t, errCompressionNotSupported, err)
+}
+
+func TestSFTPCopyResume(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	remote, closeRemote := newTestRemoteFileSystem(t)
+	defer closeRemote()
+
+	// The interrupted copy was made into the directory dst
+	dst := filepath.Join(root, "dst")
+	assert.NoError(t, os.MkdirAll(filepath.Join(dst, "src", "sub"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dst, "src", "sub", "data.part"), []byte("da"), 0644))
+
+	copier := &sftpCopier{recursive: true, resume: true}
+	err := copier.copy(remote, filepath.ToSlash(filepath.Join(root, "src")), localFileSystem{}, dst)
+
+	assert.NoError(t, err)
+	assertCopiedTree(t, filepath.Join(dst, "src"))
+
+	_, err = os.Stat(filepath.Join(dst, "src", "sub", "data.part"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSFTPCopyResumeToRemote(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	remote, closeRemote := newTestRemoteFileSystem(t)
+	defer closeRemote()
+
+	dst := filepath.Join(root, "dst")
+	assert.NoError(t, os.MkdirAll(filepath.Join(dst, "src", "sub"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dst, "src", "sub", "data"), []byte("old"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dst, "src", "sub", "data.part"), []byte("da"), 0644))
+
+	copier := &sftpCopier{recursive: true, resume: true}
+	err := copier.copy(localFileSystem{}, filepath.Join(root, "src"), remote, filepath.ToSlash(dst))
+
+	assert.NoError(t, err)
+	assertCopiedTree(t, filepath.Join(dst, "src"))
+}
+
+func TestSFTPCopyResumeReplacesOtherVersion(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	// A smaller file at the destination isn't taken as a partial copy
+	dst := filepath.Join(root, "data")
+	assert.NoError(t, ioutil.WriteFile(dst, []byte("do"), 0644))
+
+	copier := &sftpCopier{resume: true}
+	err := copier.copy(localFileSystem{}, filepath.Join(root, "src", "sub", "data"), localFileSystem{}, dst)
+
+	assert.NoError(t, err)
+	content, err := ioutil.ReadFile(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(content))
+}
+
+func TestSFTPCopyResumeCompressed(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	// A compressed copy starts the partial file over
+	dst := filepath.Join(root, "dst")
+	assert.NoError(t, os.MkdirAll(filepath.Join(dst, "src", "sub"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dst, "src", "sub", "data.part"), []byte("xx"), 0644))
+
+	copier := &sftpCopier{recursive: true, resume: true, compress: true}
+	err := copier.copy(localFileSystem{}, filepath.Join(root, "src"), localFileSystem{}, dst)
+
+	assert.NoError(t, err)
+	assertCopiedTree(t, filepath.Join(dst, "src"))
+}
+
+func TestSFTPCopyResumeSkipsCopiedFiles(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	src := filepath.Join(root, "src", "run.sh")
+	dst := filepath.Join(root, "run.sh")
+
+	// Same size and modification time, but different content
+	assert.NoError(t, ioutil.WriteFile(dst, []byte("#!/bin/ls\n"), 0600))
+	mtime := time.Date(2015, time.November, 20, 12, 0, 0, 0, time.UTC)
+	assert.NoError(t, os.Chtimes(dst, mtime, mtime))
+
+	copier := &sftpCopier{resume: true}
+	err := copier.copy(localFileSystem{}, src, localFileSystem{}, dst)
+
+	assert.NoError(t, err)
+	content, err := ioutil.ReadFile(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, "#!/bin/ls\n", string(content))
+}
+
+func TestSFTPCopyCompressedProgress(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	progress := &bytes.Buffer{}
+	copier := &sftpCopier{compress: true, progress: progress}
+	err := copier.copy(localFileSystem{}, filepath.Join(root, "src", "sub", "data"), localFileSystem{}, filepath.Join(root, "data"))
+
+	assert.NoError(t, err)
+	assert.NotContains(t, progress.String(), "%")
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, "'/tmp/a b'", shellQuote("/tmp/a b"))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+}
+
+func TestRemotePath(t *testing.T) {
+	assert.Equal(t, ".", remotePath(&MockHostInfo{}, ""))
+	assert.Equal(t, "/tmp", remotePath(&MockHostInfo{}, "/tmp"))
+	assert.Equal(t, "", remotePath(nil, ""))
+	assert.Equal(t, ".", remotePath(&MockHostInfo{}, "~"))
+	assert.Equal(t, ".", remotePath(&MockHostInfo{}, "~/"))
+	assert.Equal(t, "x", remotePath(&MockHostInfo{}, "~/x"))
+	assert.Equal(t, "x/y", remotePath(&MockHostInfo{}, "~/x/y"))
+	assert.Equal(t, "~user/x", remotePath(&MockHostInfo{}, "~user/x"))
+	assert.Equal(t, "~/x", remotePath(nil, "~/x"))
+}
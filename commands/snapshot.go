@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/classmarkets/docker-machine/libmachine"
+	"github.com/classmarkets/docker-machine/libmachine/drivers"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/log"
+	"github.com/classmarkets/docker-machine/libmachine/state"
+)
+
+const snapshotNameTimeFormat = "20060102-150405"
+
+func cmdSnapshotCreate(c CommandLine, api libmachine.API) error {
+	h, snapshotter, err := loadSnapshotter(c, api)
+	if err != nil {
+		return err
+	}
+
+	name := c.Args().Get(1)
+	if name == "" {
+		name = fmt.Sprintf("%s-%s", h.Name, time.Now().Format(snapshotNameTimeFormat))
+	}
+
+	log.Infof("Taking snapshot %q of %q...", name, h.Name)
+	if err := snapshotter.CreateSnapshot(name); err != nil {
+		return fmt.Errorf("Error taking snapshot %q of %q: %s", name, h.Name, err)
+	}
+
+	log.Infof("Snapshot %q of %q was taken.", name, h.Name)
+
+	return nil
+}
+
+func cmdSnapshotLs(c CommandLine, api libmachine.API) error {
+	if len(c.Args()) > 1 {
+		return ErrTooManyArguments
+	}
+
+	h, snapshotter, err := loadSnapshotter(c, api)
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := snapshotter.ListSnapshots()
+	if err != nil {
+		return fmt.Errorf("Error listing snapshots of %q: %s", h.Name, err)
+	}
+
+	if c.Bool("quiet") {
+		for _, snapshot := range snapshots {
+			fmt.Println(snapshot.Name)
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 5, 1, 3, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "NAME\tCURRENT\tID")
+	for _, snapshot := range snapshots {
+		current := "-"
+		if snapshot.Current {
+			current = "*"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", snapshot.Name, current, snapshot.ID)
+	}
+
+	return nil
+}
+
+// cmdSnapshotRestore reverts a machine to a snapshot. A running machine is
+// stopped first and started again once the snapshot is restored.
+func cmdSnapshotRestore(c CommandLine, api libmachine.API) error {
+	h, snapshotter, name, err := loadSnapshotterWithName(c, api)
+	if err != nil {
+		return err
+	}
+
+	currentState, err := h.Driver.GetState()
+	if err != nil {
+		return fmt.Errorf("Error getting state for host %s: %s", h.Name, err)
+	}
+
+	wasRunning := currentState == state.Running || currentState == state.Paused
+	if wasRunning {
+		if err := h.Stop(); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("Restoring snapshot %q of %q...", name, h.Name)
+	if err := snapshotter.RestoreSnapshot(name); err != nil {
+		return fmt.Errorf("Error restoring snapshot %q of %q: %s", name, h.Name, err)
+	}
+
+	log.Infof("Snapshot %q of %q was restored.", name, h.Name)
+
+	if wasRunning {
+		if err := h.Start(); err != nil {
+			return err
+		}
+	}
+
+	return api.Save(h)
+}
+
+func cmdSnapshotRm(c CommandLine, api libmachine.API) error {
+	h, snapshotter, name, err := loadSnapshotterWithName(c, api)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Removing snapshot %q of %q...", name, h.Name)
+	if err := snapshotter.RemoveSnapshot(name); err != nil {
+		return fmt.Errorf("Error removing snapshot %q of %q: %s", name, h.Name, err)
+	}
+
+	log.Infof("Successfully removed snapshot %q of %q", name, h.Name)
+
+	return nil
+}
+
+// loadSnapshotter loads the target machine and makes sure its driver
+// supports snapshots.
+func loadSnapshotter(c CommandLine, api libmachine.API) (*host.Host, drivers.Snapshotter, error) {
+	if len(c.Args()) > 2 {
+		return nil, nil, ErrTooManyArguments
+	}
+
+	target, err := targetHost(c, api)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	h, err := api.Load(target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := drivers.RequireCapability(h.Driver, h.DriverName, drivers.CapabilitySnapshot); err != nil {
+		return nil, nil, err
+	}
+
+	snapshotter, ok := h.Driver.(drivers.Snapshotter)
+	if !ok {
+		return nil, nil, drivers.ErrCapabilityNotSupported{DriverName: h.DriverName, Capability: drivers.CapabilitySnapshot}
+	}
+
+	return h, snapshotter, nil
+}
+
+func loadSnapshotterWithName(c CommandLine, api libmachine.API) (*host.Host, drivers.Snapshotter, string, error) {
+	if len(c.Args()) < 2 {
+		c.ShowHelp()
+		return nil, nil, "", errNoSnapshotName
+	}
+
+	h, snapshotter, err := loadSnapshotter(c, api)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	return h, snapshotter, c.Args().Get(1), nil
+}
@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/classmarkets/docker-machine/commands/commandstest"
+	"github.com/classmarkets/docker-machine/drivers/fakedriver"
+	"github.com/classmarkets/docker-machine/libmachine/drivers"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/libmachinetest"
+	"github.com/classmarkets/docker-machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+// basicDriver hides the optional interfaces of the driver it wraps.
+type basicDriver struct {
+	drivers.Driver
+}
+
+func TestCmdSnapshotCreate(t *testing.T) {
+	driver := &fakedriver.Driver{MockState: state.Running}
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"dev", "base"},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{{Name: "dev", Driver: driver}},
+	}
+
+	err := cmdSnapshotCreate(commandLine, api)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []drivers.Snapshot{{Name: "base"}}, driver.MockSnapshots)
+}
+
+func TestCmdSnapshotCreateDefaultName(t *testing.T) {
+	driver := &fakedriver.Driver{MockState: state.Running}
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"dev"},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{{Name: "dev", Driver: driver}},
+	}
+
+	err := cmdSnapshotCreate(commandLine, api)
+
+	assert.NoError(t, err)
+	assert.Len(t, driver.MockSnapshots, 1)
+	assert.Regexp(t, `^dev-[0-9]{8}-[0-9]{6}$`, driver.MockSnapshots[0].Name)
+}
+
+func TestCmdSnapshotNotSupported(t *testing.T) {
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"dev", "base"},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name:       "dev",
+				DriverName: "nosnapshot",
+				Driver:     &basicDriver{&fakedriver.Driver{}},
+			},
+		},
+	}
+
+	err := cmdSnapshotCreate(commandLine, api)
+
+	assert.EqualError(t, err, `Driver "nosnapshot" does not support snapshot`)
+}
+
+func TestCmdSnapshotLs(t *testing.T) {
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"dev"},
+		LocalFlags: &commandstest.FakeFlagger{
+			Data: map[string]interface{}{
+				"quiet": true,
+			},
+		},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name: "dev",
+				Driver: &fakedriver.Driver{
+					MockSnapshots: []drivers.Snapshot{{Name: "base"}, {Name: "upgrade"}},
+				},
+			},
+		},
+	}
+
+	stdoutGetter := commandstest.NewStdoutGetter()
+	defer stdoutGetter.Stop()
+
+	err := cmdSnapshotLs(commandLine, api)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "base\nupgrade\n", stdoutGetter.Output())
+}
+
+func TestCmdSnapshotRestore(t *testing.T) {
+	driver := &fakedriver.Driver{
+		MockState:     state.Stopped,
+		MockSnapshots: []drivers.Snapshot{{Name: "base"}, {Name: "upgrade", Current: true}},
+	}
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"dev", "base"},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{{Name: "dev", Driver: driver}},
+	}
+
+	err := cmdSnapshotRestore(commandLine, api)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []drivers.Snapshot{{Name: "base", Current: true}, {Name: "upgrade"}}, driver.MockSnapshots)
+	assert.Equal(t, state.Stopped, driver.MockState)
+}
+
+func TestCmdSnapshotRestoreMissingName(t *testing.T) {
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"dev"},
+	}
+	api := &libmachinetest.FakeAPI{}
+
+	err := cmdSnapshotRestore(commandLine, api)
+
+	assert.Equal(t, errNoSnapshotName, err)
+	assert.True(t, commandLine.HelpShown)
+}
+
+func TestCmdSnapshotRm(t *testing.T) {
+	driver := &fakedriver.Driver{
+		MockSnapshots: []drivers.Snapshot{{Name: "base"}, {Name: "upgrade"}},
+	}
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"dev", "upgrade"},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{{Name: "dev", Driver: driver}},
+	}
+
+	err := cmdSnapshotRm(commandLine, api)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []drivers.Snapshot{{Name: "base"}}, driver.MockSnapshots)
+}
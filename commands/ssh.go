@@ -1,12 +1,16 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/classmarkets/docker-machine/libmachine"
+	"github.com/classmarkets/docker-machine/libmachine/ssh"
 	"github.com/classmarkets/docker-machine/libmachine/state"
 )
 
+var errAgentForwardingNotSupported = errors.New("The SSH client does not support agent forwarding")
+
 type errStateInvalidForSSH struct {
 	HostName string
 }
@@ -23,7 +27,15 @@ func cmdSSH(c CommandLine, api libmachine.API) error {
 		return nil
 	}
 
-	target, err := targetHost(c, api)
+	// -A is parsed by hand, like ssh's, since the other flags are passed
+	// through to the command
+	args := c.Args()
+	forwardAgent := len(args) > 0 && args[0] == "-A"
+	if forwardAgent {
+		args = args[1:]
+	}
+
+	target, err := targetHostFromArgs(args, api)
 	if err != nil {
 		return err
 	}
@@ -47,5 +59,20 @@ func cmdSSH(c CommandLine, api libmachine.API) error {
 		return err
 	}
 
-	return client.Shell(c.Args().Tail()...)
+	if forwardAgent {
+		forwarder, ok := client.(ssh.AgentForwarder)
+		if !ok {
+			return errAgentForwardingNotSupported
+		}
+
+		if err := forwarder.ForwardAgent(); err != nil {
+			return err
+		}
+	}
+
+	if len(args) == 0 {
+		return client.Shell()
+	}
+
+	return client.Shell(args[1:]...)
 }
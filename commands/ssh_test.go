@@ -109,3 +109,27 @@ func TestCmdSSH(t *testing.T) {
 		}
 	}
 }
+
+func TestCmdSSHForwardAgent(t *testing.T) {
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"-A", "default", "git", "clone", "git@github.com:docker/machine.git"},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name: "default",
+				Driver: &fakedriver.Driver{
+					MockState: state.Running,
+				},
+			},
+		},
+	}
+	clientCreator := &FakeSSHClientCreator{}
+	host.SetSSHClientCreator(clientCreator)
+
+	err := cmdSSH(commandLine, api)
+
+	assert.NoError(t, err)
+	assert.True(t, clientCreator.client.(*sshtest.FakeClient).ForwardedAgent)
+	assert.Equal(t, []string{"git", "clone", "git@github.com:docker/machine.git"}, clientCreator.client.(*sshtest.FakeClient).ActivatedShell)
+}
@@ -0,0 +1,286 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/classmarkets/docker-machine/libmachine"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/log"
+	"github.com/classmarkets/docker-machine/libmachine/mcnutils"
+	"github.com/classmarkets/docker-machine/libmachine/persist"
+	"github.com/classmarkets/docker-machine/libmachine/ssh"
+	"github.com/classmarkets/docker-machine/libmachine/state"
+)
+
+const (
+	sshConfigFile        = "ssh_config"
+	sshConfigAliasPrefix = "docker-machine-"
+	sshConfigComment     = "# Added by docker-machine, includes the configuration of the machines"
+	sshConfigBegin       = "# BEGIN docker-machine ssh-config, regenerated by docker-machine ssh-config --write"
+	sshConfigEnd         = "# END docker-machine ssh-config"
+)
+
+// sshUserConfigPath is the ssh_config of the user, which includes the
+// configuration of the machines.
+var sshUserConfigPath = func() string {
+	return filepath.Join(mcnutils.GetHomeDir(), ".ssh", "config")
+}
+
+// sshConfigAlias is the name of the machine in ssh_config, prefixed so it
+// doesn't hide the hosts of the user.
+func sshConfigAlias(machineName string) string {
+	return sshConfigAliasPrefix + machineName
+}
+
+// sshConfigEntry returns the ssh_config Host block connecting to the machine
+// like the native client does, with its key, pinned host key and jump host.
+func sshConfigEntry(h HostInfo) (string, error) {
+	hostname, err := h.GetSSHHostname()
+	if err != nil {
+		return "", err
+	}
+
+	port, err := h.GetSSHPort()
+	if err != nil {
+		return "", err
+	}
+
+	auth := sshAuth(h)
+
+	entry := &bytes.Buffer{}
+	fmt.Fprintf(entry, "Host %s\n", sshConfigAlias(h.GetMachineName()))
+	fmt.Fprintf(entry, "  HostName %s\n", hostname)
+	fmt.Fprintf(entry, "  Port %d\n", port)
+	fmt.Fprintf(entry, "  User %s\n", h.GetSSHUsername())
+
+	for _, key := range auth.Keys {
+		fmt.Fprintf(entry, "  IdentityFile %s\n", sshConfigQuote(key))
+	}
+	if len(auth.Keys) > 0 {
+		fmt.Fprintf(entry, "  IdentitiesOnly yes\n")
+	}
+
+	if auth.KnownHostsFile != "" {
+		fmt.Fprintf(entry, "  UserKnownHostsFile %s\n", sshConfigQuote(auth.KnownHostsFile))
+		fmt.Fprintf(entry, "  HostKeyAlias %s\n", auth.HostKeyAlias)
+	}
+
+	proxyArgs, err := ssh.ProxyJumpArgs("ssh", auth.ProxyJump)
+	if err != nil {
+		return "", err
+	}
+
+	// The jump host is given as -o Option=value
+	for i := 1; i < len(proxyArgs); i += 2 {
+		option := strings.SplitN(proxyArgs[i], "=", 2)
+		fmt.Fprintf(entry, "  %s %s\n", option[0], option[1])
+	}
+
+	return entry.String(), nil
+}
+
+func sshConfigQuote(s string) string {
+	if strings.ContainsAny(s, " \t") {
+		return fmt.Sprintf("%q", s)
+	}
+
+	return s
+}
+
+// sshConfigPattern matches the ssh_config files of the machines.
+func sshConfigPattern(machinesDir string) string {
+	return filepath.Join(machinesDir, "*", sshConfigFile)
+}
+
+// writeSSHConfig writes the ssh_config Host block of the machine into its
+// directory. The ssh_config of the user is left as it is.
+func writeSSHConfig(machinesDir string, h HostInfo) error {
+	entry, err := sshConfigEntry(h)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(machinesDir, h.GetMachineName(), sshConfigFile)
+
+	return ioutil.WriteFile(path, []byte(entry), 0600)
+}
+
+// sshConfigIncludes returns whether the ssh_config includes pattern.
+func sshConfigIncludes(configPath, pattern string) (bool, error) {
+	content, err := ioutil.ReadFile(configPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	include := "Include " + sshConfigQuote(pattern)
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == include {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// includeSSHConfig adds an Include of pattern to the top of the ssh_config,
+// unless it's already there. It has to come before the Host blocks to apply
+// to every host.
+func includeSSHConfig(configPath, pattern string) error {
+	included, err := sshConfigIncludes(configPath, pattern)
+	if err != nil || included {
+		return err
+	}
+
+	content, err := ioutil.ReadFile(configPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
+		return err
+	}
+
+	updated := sshConfigComment + "\n" + "Include " + sshConfigQuote(pattern) + "\n"
+	if len(content) > 0 {
+		updated += "\n" + string(content)
+	}
+
+	return ioutil.WriteFile(configPath, []byte(updated), 0600)
+}
+
+// cmdSSHConfig prints the ssh_config Host blocks of the machines, or writes
+// them into a file between marker comments, replacing those written before.
+func cmdSSHConfig(c CommandLine, api libmachine.API) error {
+	var (
+		hosts        []*host.Host
+		hostsInError map[string]error
+		err          error
+	)
+
+	if len(c.Args()) == 0 {
+		hosts, hostsInError, err = persist.LoadAllHosts(api)
+		if err != nil {
+			return err
+		}
+	} else {
+		hosts, hostsInError = persist.LoadHosts(api, c.Args())
+	}
+
+	if len(hostsInError) > 0 {
+		errs := []error{}
+		for _, err := range hostsInError {
+			errs = append(errs, err)
+		}
+		return consolidateErrs(errs)
+	}
+
+	if c.Bool("include") {
+		if err := includeSSHConfig(sshUserConfigPath(), sshConfigPattern(api.GetMachinesDir())); err != nil {
+			return fmt.Errorf("Error including the SSH configuration of the machines: %s", err)
+		}
+
+		for _, h := range hosts {
+			if err := writeSSHConfig(api.GetMachinesDir(), h.Driver); err != nil {
+				log.Warnf("Skipping %q: %s", h.Name, err)
+			}
+		}
+
+		return nil
+	}
+
+	section, err := sshConfigSection(hosts, c.Bool("include-stopped"))
+	if err != nil {
+		return err
+	}
+
+	path := c.String("write")
+	if path == "" {
+		fmt.Print(section)
+		return nil
+	}
+
+	return writeSSHConfigSection(path, section)
+}
+
+// sshConfigSection returns the Host blocks of the machines, separated by
+// blank lines. Stopped machines are skipped unless includeStopped is set.
+func sshConfigSection(hosts []*host.Host, includeStopped bool) (string, error) {
+	entries := []string{}
+
+	for _, h := range hosts {
+		if !includeStopped {
+			currentState, err := h.Driver.GetState()
+			if err != nil {
+				return "", fmt.Errorf("Error getting state of %q: %s", h.Name, err)
+			}
+
+			if currentState != state.Running {
+				continue
+			}
+		}
+
+		entry, err := sshConfigEntry(h.Driver)
+		if err != nil {
+			if includeStopped {
+				log.Warnf("Skipping %q: %s", h.Name, err)
+				continue
+			}
+			return "", fmt.Errorf("Error getting the SSH configuration of %q: %s", h.Name, err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return strings.Join(entries, "\n"), nil
+}
+
+// writeSSHConfigSection writes section between the marker comments of the
+// file, the rest of it is kept.
+func writeSSHConfigSection(path, section string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, []byte(replaceSSHConfigSection(string(content), section)), 0600)
+}
+
+// replaceSSHConfigSection replaces what's between the marker comments of
+// content with section, or appends it with the markers if they're missing.
+func replaceSSHConfigSection(content, section string) string {
+	block := sshConfigBegin + "\n" + section + sshConfigEnd + "\n"
+
+	begin := strings.Index(content, sshConfigBegin)
+	if begin >= 0 {
+		if end := strings.Index(content[begin:], sshConfigEnd); end >= 0 {
+			end += begin + len(sshConfigEnd)
+			if end < len(content) && content[end] == '\n' {
+				end++
+			}
+
+			return content[:begin] + block + content[end:]
+		}
+	}
+
+	if content == "" {
+		return block
+	}
+
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	return content + "\n" + block
+}
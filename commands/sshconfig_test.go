@@ -0,0 +1,201 @@
+package commands
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/classmarkets/docker-machine/commands/commandstest"
+	"github.com/classmarkets/docker-machine/drivers/fakedriver"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/libmachinetest"
+	"github.com/classmarkets/docker-machine/libmachine/ssh"
+	"github.com/classmarkets/docker-machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSHConfigEntry(t *testing.T) {
+	entry, err := sshConfigEntry(&MockHostInfo{
+		name:           "myfunhost",
+		ip:             "12.34.56.78",
+		sshPort:        234,
+		sshUsername:    "root",
+		sshKeyPath:     "/fake/keypath/id_rsa",
+		knownHostsPath: "/fake/machine dir/known_hosts",
+		proxyJump:      ssh.ProxyJump{Address: "jump@bastion:2222"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `Host docker-machine-myfunhost
+  HostName 12.34.56.78
+  Port 234
+  User root
+  IdentityFile /fake/keypath/id_rsa
+  IdentitiesOnly yes
+  UserKnownHostsFile "/fake/machine dir/known_hosts"
+  HostKeyAlias myfunhost
+  ProxyJump jump@bastion:2222
+`, entry)
+}
+
+func TestSSHConfigEntryProxyJumpKey(t *testing.T) {
+	entry, err := sshConfigEntry(&MockHostInfo{
+		name:        "myfunhost",
+		ip:          "10.0.0.2",
+		sshPort:     22,
+		sshUsername: "docker",
+		proxyJump:   ssh.ProxyJump{Address: "jump@bastion", KeyPath: "/Users/me/My Keys/bastion"},
+	})
+
+	assert.NoError(t, err)
+	assert.Contains(t, entry, "  ProxyCommand ssh -i '/Users/me/My Keys/bastion' -o IdentitiesOnly=yes -p 22 -W %h:%p jump@bastion\n")
+}
+
+func TestIncludeSSHConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	configPath := filepath.Join(dir, ".ssh", "config")
+	assert.NoError(t, includeSSHConfig(configPath, "/machines/*/ssh_config"))
+
+	content, err := ioutil.ReadFile(configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, sshConfigComment+"\nInclude /machines/*/ssh_config\n", string(content))
+
+	assert.NoError(t, ioutil.WriteFile(configPath, []byte("Host foo\n  User bar\n"), 0600))
+	assert.NoError(t, includeSSHConfig(configPath, "/machines/*/ssh_config"))
+	assert.NoError(t, includeSSHConfig(configPath, "/machines/*/ssh_config"))
+
+	content, err = ioutil.ReadFile(configPath)
+	assert.NoError(t, err)
+	assert.Equal(t, sshConfigComment+"\nInclude /machines/*/ssh_config\n\nHost foo\n  User bar\n", string(content))
+}
+
+func TestReplaceSSHConfigSection(t *testing.T) {
+	section := "Host docker-machine-dev\n  Port 22\n"
+	block := sshConfigBegin + "\n" + section + sshConfigEnd + "\n"
+
+	assert.Equal(t, block, replaceSSHConfigSection("", section))
+
+	content := replaceSSHConfigSection("Host foo\n  User bar", section)
+	assert.Equal(t, "Host foo\n  User bar\n\n"+block, content)
+
+	content = replaceSSHConfigSection(content+"\nHost baz\n", "Host docker-machine-prod\n  Port 2222\n")
+	assert.Equal(t, "Host foo\n  User bar\n\n"+sshConfigBegin+"\nHost docker-machine-prod\n  Port 2222\n"+sshConfigEnd+"\n\nHost baz\n", content)
+
+	assert.Equal(t, content, replaceSSHConfigSection(content, "Host docker-machine-prod\n  Port 2222\n"))
+}
+
+func TestCmdSSHConfig(t *testing.T) {
+	commandLine := &commandstest.FakeCommandLine{
+		LocalFlags: &commandstest.FakeFlagger{},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name:   "running",
+				Driver: &fakedriver.Driver{MockState: state.Running, MockName: "running"},
+			},
+			{
+				Name:   "stopped",
+				Driver: &fakedriver.Driver{MockState: state.Stopped, MockName: "stopped"},
+			},
+		},
+	}
+
+	stdoutGetter := commandstest.NewStdoutGetter()
+	defer stdoutGetter.Stop()
+
+	err := cmdSSHConfig(commandLine, api)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Host docker-machine-running\n  HostName \n  Port 0\n  User \n", stdoutGetter.Output())
+}
+
+func TestCmdSSHConfigWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.d", "docker-machine")
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"stopped"},
+		LocalFlags: &commandstest.FakeFlagger{
+			Data: map[string]interface{}{
+				"write":           path,
+				"include-stopped": true,
+			},
+		},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name:   "running",
+				Driver: &fakedriver.Driver{MockState: state.Running, MockName: "running"},
+			},
+			{
+				Name:   "stopped",
+				Driver: &fakedriver.Driver{MockState: state.Stopped, MockName: "stopped"},
+			},
+		},
+	}
+
+	assert.NoError(t, cmdSSHConfig(commandLine, api))
+	assert.NoError(t, cmdSSHConfig(commandLine, api))
+
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, sshConfigBegin+"\nHost docker-machine-stopped\n  HostName \n  Port 0\n  User \n"+sshConfigEnd+"\n", string(content))
+}
+
+type stateErrorDriver struct {
+	*fakedriver.Driver
+}
+
+func (d *stateErrorDriver) GetState() (state.State, error) {
+	return state.Error, errors.New("unreachable")
+}
+
+func TestCmdSSHConfigIncludeSkipsState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	defer func(userConfigPath func() string) {
+		sshUserConfigPath = userConfigPath
+	}(sshUserConfigPath)
+	userConfigPath := filepath.Join(dir, "ssh_config")
+	sshUserConfigPath = func() string { return userConfigPath }
+
+	machinesDir := filepath.Join(dir, "machines")
+	assert.NoError(t, os.MkdirAll(filepath.Join(machinesDir, "unreachable"), 0700))
+
+	commandLine := &commandstest.FakeCommandLine{
+		LocalFlags: &commandstest.FakeFlagger{
+			Data: map[string]interface{}{
+				"include": true,
+			},
+		},
+	}
+	api := &libmachinetest.FakeAPI{
+		MachinesDir: machinesDir,
+		Hosts: []*host.Host{
+			{
+				Name:   "unreachable",
+				Driver: &stateErrorDriver{&fakedriver.Driver{MockName: "unreachable"}},
+			},
+		},
+	}
+
+	assert.NoError(t, cmdSSHConfig(commandLine, api))
+
+	included, err := sshConfigIncludes(userConfigPath, sshConfigPattern(machinesDir))
+	assert.NoError(t, err)
+	assert.True(t, included)
+
+	_, err = os.Stat(filepath.Join(machinesDir, "unreachable", sshConfigFile))
+	assert.NoError(t, err)
+}
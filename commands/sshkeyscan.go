@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/classmarkets/docker-machine/libmachine"
+	"github.com/classmarkets/docker-machine/libmachine/drivers"
+	"github.com/classmarkets/docker-machine/libmachine/log"
+	"github.com/classmarkets/docker-machine/libmachine/ssh"
+)
+
+var scanHostKey = ssh.ScanHostKey
+
+// cmdSSHKeyscan prints the host key of a machine and checks it against the
+// pinned key. With --reset, the pinned keys are replaced with the current
+// key of the machine, e.g. after it was legitimately re-keyed.
+func cmdSSHKeyscan(c CommandLine, api libmachine.API) error {
+	if len(c.Args()) > 1 {
+		return ErrExpectedOneMachine
+	}
+
+	target, err := targetHost(c, api)
+	if err != nil {
+		return err
+	}
+
+	h, err := api.Load(target)
+	if err != nil {
+		return err
+	}
+
+	knownHostsFile := drivers.GetKnownHostsPath(h.Driver)
+	if knownHostsFile == "" {
+		return fmt.Errorf("Driver %q does not pin host keys", h.DriverName)
+	}
+
+	hostname, err := h.Driver.GetSSHHostname()
+	if err != nil {
+		return err
+	}
+
+	port, err := h.Driver.GetSSHPort()
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("reset") {
+		// Any type of key is accepted, the machine may have been re-keyed
+		// with another type
+		key, err := scanHostKey(hostname, port, nil)
+		if err != nil {
+			return err
+		}
+
+		if err := ssh.ReplaceHostKey(knownHostsFile, h.Name, key); err != nil {
+			return err
+		}
+
+		log.Infof("Replaced the pinned host keys of %q.", h.Name)
+		fmt.Printf("%s %s\n", key.Type(), ssh.Fingerprint(key))
+
+		return nil
+	}
+
+	key, err := scanHostKey(hostname, port, ssh.PinnedKeyTypes(knownHostsFile, h.Name))
+	if err != nil {
+		return err
+	}
+
+	if err := ssh.VerifyHostKey(knownHostsFile, h.Name, key); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s %s\n", key.Type(), ssh.Fingerprint(key))
+
+	return nil
+}
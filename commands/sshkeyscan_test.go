@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/classmarkets/docker-machine/commands/commandstest"
+	"github.com/classmarkets/docker-machine/drivers/fakedriver"
+	"github.com/classmarkets/docker-machine/libmachine/drivers"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/libmachinetest"
+	"github.com/classmarkets/docker-machine/libmachine/ssh"
+	"github.com/stretchr/testify/assert"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func newFakeHostKey(t *testing.T) gossh.PublicKey {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicKey, err := gossh.NewPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return publicKey
+}
+
+func TestCmdSSHKeyscan(t *testing.T) {
+	defer func(scan func(string, int, []string) (gossh.PublicKey, error)) { scanHostKey = scan }(scanHostKey)
+
+	storePath, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(storePath)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(storePath, "machines", "dev"), 0700))
+
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name: "dev",
+				Driver: &fakedriver.Driver{
+					BaseDriver: &drivers.BaseDriver{
+						MachineName: "dev",
+						StorePath:   storePath,
+					},
+				},
+			},
+		},
+	}
+	knownHostsFile := filepath.Join(storePath, "machines", "dev", "known_hosts")
+
+	key := newFakeHostKey(t)
+	scanHostKey = func(string, int, []string) (gossh.PublicKey, error) {
+		return key, nil
+	}
+
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"dev"},
+	}
+
+	// The first scan pins the key
+	assert.NoError(t, cmdSSHKeyscan(commandLine, api))
+	assert.NoError(t, ssh.VerifyHostKey(knownHostsFile, "dev", key))
+	pinnedKey := key
+
+	key = newFakeHostKey(t)
+	assert.Equal(t, ssh.ErrHostKeyMismatch{Alias: "dev", KnownHostsFile: knownHostsFile}, cmdSSHKeyscan(commandLine, api))
+
+	commandLine.LocalFlags = &commandstest.FakeFlagger{
+		Data: map[string]interface{}{"reset": true},
+	}
+
+	// The pinned key is kept if the machine can't be scanned
+	scanHostKey = func(string, int, []string) (gossh.PublicKey, error) {
+		return nil, errors.New("connection refused")
+	}
+	assert.EqualError(t, cmdSSHKeyscan(commandLine, api), "connection refused")
+	assert.NoError(t, ssh.VerifyHostKey(knownHostsFile, "dev", pinnedKey))
+
+	scanHostKey = func(string, int, []string) (gossh.PublicKey, error) {
+		return key, nil
+	}
+	assert.NoError(t, cmdSSHKeyscan(commandLine, api))
+	assert.NoError(t, ssh.VerifyHostKey(knownHostsFile, "dev", key))
+	assert.Error(t, ssh.VerifyHostKey(knownHostsFile, "dev", pinnedKey))
+}
+
+func TestCmdSSHKeyscanNotPinned(t *testing.T) {
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name:       "dev",
+				DriverName: "fake",
+				Driver:     &fakedriver.Driver{},
+			},
+		},
+	}
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"dev"},
+	}
+
+	assert.EqualError(t, cmdSSHKeyscan(commandLine, api), `Driver "fake" does not pin host keys`)
+}
@@ -90,6 +90,48 @@ func TestCmdStop(t *testing.T) {
 				"machine":        state.Running,
 			},
 		},
+		{
+			commandLine: &commandstest.FakeCommandLine{
+				CliArgs: []string{},
+				LocalFlags: &commandstest.FakeFlagger{
+					Data: map[string]interface{}{
+						"filter": []string{"name=^web"},
+						"y":      true,
+					},
+				},
+			},
+			api: &libmachinetest.FakeAPI{
+				Hosts: []*host.Host{
+					{
+						Name: "web1",
+						Driver: &fakedriver.Driver{
+							MockName:  "web1",
+							MockState: state.Running,
+						},
+					},
+					{
+						Name: "web2",
+						Driver: &fakedriver.Driver{
+							MockName:  "web2",
+							MockState: state.Running,
+						},
+					},
+					{
+						Name: "db",
+						Driver: &fakedriver.Driver{
+							MockName:  "db",
+							MockState: state.Running,
+						},
+					},
+				},
+			},
+			expectedErr: nil,
+			expectedStates: map[string]state.State{
+				"web1": state.Stopped,
+				"web2": state.Stopped,
+				"db":   state.Running,
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,7 @@
+package commands
+
+import "github.com/classmarkets/docker-machine/libmachine"
+
+func cmdSuspend(c CommandLine, api libmachine.API) error {
+	return runAction("suspend", c, api)
+}
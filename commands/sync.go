@@ -0,0 +1,453 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/classmarkets/docker-machine/libmachine"
+	"github.com/classmarkets/docker-machine/libmachine/log"
+	"github.com/docker/docker/builder/dockerignore"
+	"github.com/docker/docker/pkg/fileutils"
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	syncDefaultDelay = 300
+	syncIgnoreFile   = ".dockerignore"
+)
+
+var (
+	errSyncSourceNotLocal     = errors.New("The source of a sync must be a local directory")
+	errSyncDestinationNotHost = errors.New("The destination of a sync must be a machine, e.g. machine:/path")
+	errSyncDeleteDestination  = errors.New("Refusing to delete what isn't in the source from the home or root directory of the machine, give --delete a destination directory, e.g. machine:/path")
+)
+
+func cmdSync(c CommandLine, api libmachine.API) error {
+	args := c.Args()
+	if len(args) != 2 {
+		c.ShowHelp()
+		return errWrongNumberArguments
+	}
+
+	s, closeSyncer, err := newSyncer(args[0], args[1], c, &storeHostInfoLoader{api})
+	if err != nil {
+		return err
+	}
+	defer closeSyncer()
+
+	if !c.Bool("watch") {
+		return s.syncAll()
+	}
+
+	// Watching starts before the first sync, so that no change made while
+	// it runs is missed
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("Error watching %s: %s", s.srcRoot, err)
+	}
+	defer watcher.Close()
+	s.watcher = watcher
+
+	if err := s.syncAll(); err != nil {
+		return err
+	}
+
+	delay := syncDefaultDelay
+	if c.IsSet("delay") {
+		delay = c.Int("delay")
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt)
+	defer signal.Stop(stop)
+
+	log.Infof("Watching %s for changes, press Ctrl-C to stop", s.srcRoot)
+
+	return s.watch(time.Duration(delay)*time.Millisecond, stop)
+}
+
+func newSyncer(src, dest string, c CommandLine, hostInfoLoader HostInfoLoader) (*syncer, func(), error) {
+	srcHost, _, srcPath, _, err := getInfoForScpArg(src, hostInfoLoader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if srcHost != nil {
+		return nil, nil, errSyncSourceNotLocal
+	}
+
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !info.IsDir() {
+		return nil, nil, errSyncSourceNotLocal
+	}
+
+	destHost, destUser, destPath, _, err := getInfoForScpArg(dest, hostInfoLoader)
+	if err != nil {
+		return nil, nil, err
+	}
+	if destHost == nil {
+		return nil, nil, errSyncDestinationNotHost
+	}
+
+	destPath = remotePath(destHost, destPath)
+	if c.Bool("delete") && containsHome(destPath) {
+		return nil, nil, errSyncDeleteDestination
+	}
+
+	excludes, err := syncExcludes(srcPath, c.StringSlice("exclude"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	destFS, closeDest, err := openFileSystem(destHost, destUser)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s := &syncer{
+		src:      localFileSystem{},
+		srcRoot:  srcPath,
+		dst:      destFS,
+		dstRoot:  destPath,
+		excludes: excludes,
+		delete:   c.Bool("delete"),
+		copier:   &sftpCopier{recursive: true},
+	}
+	if !c.Bool("quiet") {
+		s.out = os.Stdout
+		s.copier.progress = os.Stdout
+	}
+
+	return s, closeDest, nil
+}
+
+// containsHome returns whether a path of a machine, as given by remotePath,
+// is its home directory or one containing it.
+func containsHome(p string) bool {
+	p = path.Clean(p)
+	if p == "." || p == "/" || p == "~" {
+		return true
+	}
+
+	// The home directory of another user, or a parent of the home directory
+	if strings.HasPrefix(p, "~") && !strings.Contains(p, "/") {
+		return true
+	}
+	return strings.Trim(strings.Replace(p, "..", "", -1), "/") == ""
+}
+
+// syncExcludes reads the patterns of the .dockerignore file of the source
+// directory, if there's one, and adds those given with --exclude.
+func syncExcludes(srcRoot string, patterns []string) (*fileutils.PatternMatcher, error) {
+	f, err := os.Open(filepath.Join(srcRoot, syncIgnoreFile))
+	if err == nil {
+		defer f.Close()
+
+		ignored, err := dockerignore.ReadAll(f)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading %s: %s", syncIgnoreFile, err)
+		}
+
+		patterns = append(ignored, patterns...)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return fileutils.NewPatternMatcher(patterns)
+}
+
+// syncer keeps a directory of a machine in sync with a local directory. Only
+// the files whose size or modification time differ are copied.
+type syncer struct {
+	src     fileSystem
+	srcRoot string
+	dst     fileSystem
+	dstRoot string
+
+	// excludes matches the paths, relative to srcRoot, which aren't synced
+	excludes *fileutils.PatternMatcher
+
+	// delete removes what was removed from the source, or was never in it,
+	// from the destination
+	delete bool
+
+	copier *sftpCopier
+
+	// out is where the deleted paths are printed, nothing is printed if
+	// it's nil
+	out io.Writer
+
+	// watcher is told to watch each synced directory, if it's set
+	watcher *fsnotify.Watcher
+
+	// dirs are the real paths of the source directories being synced, a
+	// symbolic link to one of them would be synced endlessly
+	dirs map[string]bool
+}
+
+// syncAll syncs the whole source directory.
+func (s *syncer) syncAll() error {
+	return s.syncDir("")
+}
+
+// syncPaths syncs the changed paths, relative to the source directory.
+func (s *syncer) syncPaths(paths []string) error {
+	sort.Strings(paths)
+
+	for _, rel := range paths {
+		if s.excluded(rel) {
+			continue
+		}
+
+		info, err := s.src.Stat(s.srcPath(rel))
+		if os.IsNotExist(err) {
+			if s.delete {
+				if err := s.remove(rel); err != nil && !os.IsNotExist(err) {
+					return err
+				}
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			err = s.syncDir(rel)
+		} else {
+			err = s.syncFile(rel, info)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *syncer) syncDir(rel string) error {
+	srcDir := s.srcPath(rel)
+	dstDir := s.dstPath(rel)
+
+	realPath, err := s.src.RealPath(srcDir)
+	if err != nil {
+		return err
+	}
+	if s.dirs[realPath] {
+		log.Warnf("Skipping %s, it links to a directory containing it", srcDir)
+		return nil
+	}
+
+	if s.dirs == nil {
+		s.dirs = map[string]bool{}
+	}
+	s.dirs[realPath] = true
+	defer delete(s.dirs, realPath)
+
+	if s.watcher != nil {
+		if err := s.watcher.Add(srcDir); err != nil {
+			return fmt.Errorf("Error watching %s: %s", srcDir, err)
+		}
+	}
+
+	if dstInfo, err := s.dst.Stat(dstDir); err != nil || !dstInfo.IsDir() {
+		if err == nil {
+			if err := s.remove(rel); err != nil {
+				return err
+			}
+		}
+
+		if err := s.dst.Mkdir(dstDir); err != nil {
+			return err
+		}
+	}
+
+	entries, err := s.src.ReadDir(srcDir)
+	if err != nil {
+		return err
+	}
+
+	synced := map[string]bool{}
+	for _, entry := range entries {
+		entryRel := joinRel(rel, entry.Name())
+		if s.excluded(entryRel) {
+			continue
+		}
+		synced[entry.Name()] = true
+
+		// Symbolic links are synced as the files they point to
+		if entry.Mode()&os.ModeSymlink != 0 {
+			if entry, err = s.src.Stat(s.srcPath(entryRel)); err != nil {
+				return err
+			}
+		}
+
+		if entry.IsDir() {
+			err = s.syncDir(entryRel)
+		} else {
+			err = s.syncFile(entryRel, entry)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if !s.delete {
+		return nil
+	}
+
+	dstEntries, err := s.dst.ReadDir(dstDir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range dstEntries {
+		entryRel := joinRel(rel, entry.Name())
+		if synced[entry.Name()] || s.excluded(entryRel) {
+			continue
+		}
+
+		if err := s.remove(entryRel); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *syncer) syncFile(rel string, info os.FileInfo) error {
+	dstPath := s.dstPath(rel)
+
+	if dstInfo, err := s.dst.Stat(dstPath); err == nil {
+		if dstInfo.IsDir() {
+			if err := s.remove(rel); err != nil {
+				return err
+			}
+		} else if dstInfo.Size() == info.Size() && dstInfo.ModTime().Unix() == info.ModTime().Unix() {
+			return nil
+		}
+	}
+
+	return s.copier.copyFile(s.src, s.srcPath(rel), info, s.dst, dstPath)
+}
+
+// remove removes a path from the destination, with everything in it if it's
+// a directory.
+func (s *syncer) remove(rel string) error {
+	if err := removeAll(s.dst, s.dstPath(rel)); err != nil {
+		return err
+	}
+
+	if s.out != nil {
+		fmt.Fprintf(s.out, "Deleted %s\n", rel)
+	}
+
+	return nil
+}
+
+// watch syncs the changes reported by the watcher until stop receives. The
+// changes are batched, they're synced delay after the first one.
+func (s *syncer) watch(delay time.Duration, stop <-chan os.Signal) error {
+	changed := map[string]bool{}
+	var timer <-chan time.Time
+
+	for {
+		select {
+		case event, ok := <-s.watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			rel, err := filepath.Rel(s.srcRoot, event.Name)
+			if err != nil || rel == "." {
+				continue
+			}
+
+			changed[filepath.ToSlash(rel)] = true
+			if timer == nil {
+				timer = time.After(delay)
+			}
+		case err, ok := <-s.watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Warnf("Error watching %s: %s", s.srcRoot, err)
+		case <-timer:
+			paths := []string{}
+			for rel := range changed {
+				paths = append(paths, rel)
+			}
+			changed = map[string]bool{}
+			timer = nil
+
+			// A failed sync shouldn't end watching, the next change may fix it
+			if err := s.syncPaths(paths); err != nil {
+				log.Errorf("Error syncing %s: %s", s.srcRoot, err)
+			}
+		case <-stop:
+			return nil
+		}
+	}
+}
+
+func (s *syncer) excluded(rel string) bool {
+	if s.excludes == nil {
+		return false
+	}
+
+	excluded, err := s.excludes.Matches(rel)
+	if err != nil {
+		log.Debugf("Error matching %s against the excluded patterns: %s", rel, err)
+		return false
+	}
+
+	return excluded
+}
+
+func (s *syncer) srcPath(rel string) string {
+	return s.src.Join(s.srcRoot, filepath.FromSlash(rel))
+}
+
+func (s *syncer) dstPath(rel string) string {
+	return s.dst.Join(s.dstRoot, rel)
+}
+
+func joinRel(dir, name string) string {
+	if dir == "" {
+		return name
+	}
+
+	return dir + "/" + name
+}
+
+// removeAll removes a path with everything in it. Symbolic links are removed
+// without following them.
+func removeAll(fs fileSystem, name string) error {
+	err := fs.Remove(name)
+	if err == nil {
+		return nil
+	}
+
+	entries, readErr := fs.ReadDir(name)
+	if readErr != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := removeAll(fs, fs.Join(name, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return fs.Remove(name)
+}
@@ -0,0 +1,276 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/classmarkets/docker-machine/commands/commandstest"
+	"github.com/docker/docker/pkg/fileutils"
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSyncer(t *testing.T, root string, patterns ...string) (*syncer, func()) {
+	remote, closeRemote := newTestRemoteFileSystem(t)
+
+	excludes, err := fileutils.NewPatternMatcher(patterns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &syncer{
+		src:      localFileSystem{},
+		srcRoot:  filepath.Join(root, "src"),
+		dst:      remote,
+		dstRoot:  filepath.ToSlash(filepath.Join(root, "dst")),
+		excludes: excludes,
+		copier:   &sftpCopier{recursive: true},
+	}, closeRemote
+}
+
+func TestSyncAll(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	s, closeSyncer := newTestSyncer(t, root)
+	defer closeSyncer()
+
+	assert.NoError(t, s.syncAll())
+	assertCopiedTree(t, filepath.Join(root, "dst"))
+}
+
+func TestSyncAllSkipsUnchangedFiles(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	s, closeSyncer := newTestSyncer(t, root)
+	defer closeSyncer()
+
+	assert.NoError(t, s.syncAll())
+
+	// Same size and modification time, but different content
+	dst := filepath.Join(root, "dst", "run.sh")
+	assert.NoError(t, ioutil.WriteFile(dst, []byte("#!/bin/ls\n"), 0700))
+	mtime := time.Date(2015, time.November, 20, 12, 0, 0, 0, time.UTC)
+	assert.NoError(t, os.Chtimes(dst, mtime, mtime))
+
+	assert.NoError(t, s.syncAll())
+
+	content, err := ioutil.ReadFile(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, "#!/bin/ls\n", string(content))
+}
+
+func TestSyncAllExcludes(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	s, closeSyncer := newTestSyncer(t, root, "sub", "*.sh")
+	defer closeSyncer()
+
+	assert.NoError(t, s.syncAll())
+
+	_, err := os.Stat(filepath.Join(root, "dst", "sub"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(root, "dst", "run.sh"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSyncAllDelete(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "dst", "old", "dir"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(root, "dst", "old", "dir", "file"), []byte("old"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(root, "dst", "kept"), []byte("kept"), 0644))
+
+	s, closeSyncer := newTestSyncer(t, root, "kept")
+	defer closeSyncer()
+	s.delete = true
+
+	assert.NoError(t, s.syncAll())
+
+	assertCopiedTree(t, filepath.Join(root, "dst"))
+	_, err := os.Stat(filepath.Join(root, "dst", "old"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(root, "dst", "kept"))
+	assert.NoError(t, err)
+}
+
+func TestSyncPaths(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	s, closeSyncer := newTestSyncer(t, root)
+	defer closeSyncer()
+	s.delete = true
+
+	assert.NoError(t, s.syncAll())
+
+	assert.NoError(t, os.Remove(filepath.Join(root, "src", "run.sh")))
+	assert.NoError(t, os.Mkdir(filepath.Join(root, "src", "new"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(root, "src", "new", "file"), []byte("new"), 0644))
+
+	assert.NoError(t, s.syncPaths([]string{"run.sh", "new/file", "new"}))
+
+	_, err := os.Stat(filepath.Join(root, "dst", "run.sh"))
+	assert.True(t, os.IsNotExist(err))
+	content, err := ioutil.ReadFile(filepath.Join(root, "dst", "new", "file"))
+	assert.NoError(t, err)
+	assert.Equal(t, "new", string(content))
+}
+
+func TestSyncWatch(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	s, closeSyncer := newTestSyncer(t, root)
+	defer closeSyncer()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Skipf("Unable to watch files: %s", err)
+	}
+	defer watcher.Close()
+	s.watcher = watcher
+
+	assert.NoError(t, s.syncAll())
+
+	stop := make(chan os.Signal)
+	done := make(chan error)
+	go func() {
+		done <- s.watch(10*time.Millisecond, stop)
+	}()
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(root, "src", "sub", "changed"), []byte("changed"), 0644))
+
+	dst := filepath.Join(root, "dst", "sub", "changed")
+	for i := 0; i < 200; i++ {
+		if content, err := ioutil.ReadFile(dst); err == nil && string(content) == "changed" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	close(stop)
+	assert.NoError(t, <-done)
+
+	content, err := ioutil.ReadFile(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, "changed", string(content))
+}
+
+func TestSyncExcludesReadsDockerignore(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	src := filepath.Join(root, "src")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(src, ".dockerignore"), []byte("sub\n"), 0644))
+
+	excludes, err := syncExcludes(src, []string{"*.sh"})
+	assert.NoError(t, err)
+
+	for path, expected := range map[string]bool{
+		"sub/data": true,
+		"run.sh":   true,
+		"other":    false,
+	} {
+		excluded, err := excludes.Matches(path)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, excluded, path)
+	}
+}
+
+func TestCmdSyncRequiresLocalSource(t *testing.T) {
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"myfunhost:/src", "myfunhost:/dst"},
+	}
+
+	_, _, err := newSyncer(commandLine.CliArgs[0], commandLine.CliArgs[1], commandLine, &MockHostInfoLoader{MockHostInfo{}})
+
+	assert.Equal(t, errSyncSourceNotLocal, err)
+}
+
+func TestCmdSyncRequiresMachineDestination(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{filepath.Join(root, "src"), filepath.Join(root, "dst")},
+	}
+
+	_, _, err := newSyncer(commandLine.CliArgs[0], commandLine.CliArgs[1], commandLine, &MockHostInfoLoader{MockHostInfo{}})
+
+	assert.Equal(t, errSyncDestinationNotHost, err)
+}
+
+func TestSyncAllSymlinkCycle(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	assert.NoError(t, os.Symlink("..", filepath.Join(root, "src", "sub", "loop")))
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "dst", "sub", "loop"), 0755))
+
+	s, closeSyncer := newTestSyncer(t, root)
+	defer closeSyncer()
+	s.delete = true
+
+	assert.NoError(t, s.syncAll())
+
+	assertCopiedTree(t, filepath.Join(root, "dst"))
+	_, err := os.Stat(filepath.Join(root, "dst", "sub", "loop", "sub"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestContainsHome(t *testing.T) {
+	for _, p := range []string{"", ".", "./", "~", "~/", "~docker", "/", "//", "..", "../..", "../../"} {
+		assert.True(t, containsHome(p), p)
+	}
+
+	for _, p := range []string{"src", "./src", "~/src", "/src", "../other", "/home/docker/src"} {
+		assert.False(t, containsHome(p), p)
+	}
+}
+
+func TestCmdSyncDeleteRequiresDestinationDirectory(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	for _, dest := range []string{"myfunhost:", "myfunhost:.", "myfunhost:~", "myfunhost:~/", "myfunhost:~/..", "myfunhost:~/src/..", "myfunhost:/"} {
+		commandLine := &commandstest.FakeCommandLine{
+			CliArgs: []string{filepath.Join(root, "src"), dest},
+			LocalFlags: &commandstest.FakeFlagger{
+				Data: map[string]interface{}{
+					"delete": true,
+				},
+			},
+		}
+
+		_, _, err := newSyncer(commandLine.CliArgs[0], commandLine.CliArgs[1], commandLine, &MockHostInfoLoader{MockHostInfo{}})
+
+		assert.Equal(t, errSyncDeleteDestination, err, dest)
+	}
+}
+
+func TestCmdSyncDeleteAllowsDirectoryInHome(t *testing.T) {
+	root := newTestTree(t)
+	defer os.RemoveAll(root)
+
+	for _, dest := range []string{"myfunhost:src", "myfunhost:~/src", "myfunhost:~/src/sub/.."} {
+		commandLine := &commandstest.FakeCommandLine{
+			CliArgs: []string{filepath.Join(root, "src"), dest},
+			LocalFlags: &commandstest.FakeFlagger{
+				Data: map[string]interface{}{
+					"delete": true,
+				},
+			},
+		}
+
+		_, _, err := newSyncer(commandLine.CliArgs[0], commandLine.CliArgs[1], commandLine, &MockHostInfoLoader{MockHostInfo{}})
+
+		assert.NotEqual(t, errSyncDeleteDestination, err, dest)
+	}
+}
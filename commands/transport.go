@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/classmarkets/docker-machine/libmachine/engine"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/log"
+	"github.com/classmarkets/docker-machine/libmachine/state"
+)
+
+var errSwarmOverSSH = errors.New("Swarm needs the engine to be reached over tcp, not ssh")
+
+// dockerTransport returns how the Docker client reaches the engine of the
+// machine, given with --transport or else the transport it was created
+// with.
+func dockerTransport(c CommandLine, h *host.Host) (string, error) {
+	transport := c.String("transport")
+	if transport == "" && h.HostOptions != nil && h.HostOptions.EngineOptions != nil {
+		transport = h.HostOptions.EngineOptions.Transport
+	}
+
+	switch transport {
+	case "", engine.TransportTCP:
+		return engine.TransportTCP, nil
+	case engine.TransportSSH:
+		return engine.TransportSSH, nil
+	}
+
+	return "", fmt.Errorf("Unknown transport %q, expected %s or %s", transport, engine.TransportTCP, engine.TransportSSH)
+}
+
+// sshDockerHost returns the ssh:// URL of the engine of the machine. The
+// Docker client runs ssh with it. Once the ssh_config of the user includes
+// those of the machines, see ssh-config --include, the URL names the
+// machine's entry written here, which gives ssh the key and pinned host key.
+// Otherwise it's the address of the machine, and ssh relies on the
+// configuration and ssh-agent of the user.
+func sshDockerHost(machinesDir string, h *host.Host) (string, error) {
+	currentState, err := h.Driver.GetState()
+	if err != nil {
+		return "", fmt.Errorf("Error getting host state: %s", err)
+	}
+
+	if currentState != state.Running {
+		return "", fmt.Errorf("Host %q is not running", h.Name)
+	}
+
+	if err := writeSSHConfig(machinesDir, h.Driver); err != nil {
+		return "", fmt.Errorf("Error writing the SSH configuration: %s", err)
+	}
+
+	included, err := sshConfigIncludes(sshUserConfigPath(), sshConfigPattern(machinesDir))
+	if err != nil {
+		return "", fmt.Errorf("Error reading the SSH configuration: %s", err)
+	}
+
+	if included {
+		return fmt.Sprintf("ssh://%s@%s", h.Driver.GetSSHUsername(), sshConfigAlias(h.Name)), nil
+	}
+
+	log.Warnf("%s isn't included in %s, so ssh connects to %q without its key and known host.", sshConfigPattern(machinesDir), sshUserConfigPath(), h.Name)
+	log.Warnf("Run 'docker-machine ssh-config --include' to include it.")
+
+	hostname, err := h.Driver.GetSSHHostname()
+	if err != nil {
+		return "", err
+	}
+
+	port, err := h.Driver.GetSSHPort()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("ssh://%s@%s", h.Driver.GetSSHUsername(), net.JoinHostPort(hostname, strconv.Itoa(port))), nil
+}
@@ -3,7 +3,9 @@ package commands
 import (
 	"fmt"
 
+	"github.com/classmarkets/docker-machine/commands/mcndirs"
 	"github.com/classmarkets/docker-machine/libmachine"
+	"github.com/classmarkets/docker-machine/libmachine/engine"
 )
 
 func cmdURL(c CommandLine, api libmachine.API) error {
@@ -21,7 +23,17 @@ func cmdURL(c CommandLine, api libmachine.API) error {
 		return err
 	}
 
-	url, err := host.URL()
+	transport, err := dockerTransport(c, host)
+	if err != nil {
+		return err
+	}
+
+	var url string
+	if transport == engine.TransportSSH {
+		url, err = sshDockerHost(mcndirs.GetMachineDir(), host)
+	} else {
+		url, err = host.URL()
+	}
 	if err != nil {
 		return err
 	}
@@ -1,9 +1,13 @@
 package commands
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/classmarkets/docker-machine/commands/commandstest"
+	"github.com/classmarkets/docker-machine/commands/mcndirs"
 	"github.com/classmarkets/docker-machine/drivers/fakedriver"
 	"github.com/classmarkets/docker-machine/libmachine/host"
 	"github.com/classmarkets/docker-machine/libmachine/libmachinetest"
@@ -55,3 +59,101 @@ func TestCmdURL(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "tcp://120.0.0.1:2376\n", stdoutGetter.Output())
 }
+
+// sshFakeDriver is reached over SSH at its IP.
+type sshFakeDriver struct {
+	*fakedriver.Driver
+}
+
+func (d *sshFakeDriver) GetSSHHostname() (string, error) { return d.MockIP, nil }
+func (d *sshFakeDriver) GetSSHPort() (int, error)        { return 22, nil }
+func (d *sshFakeDriver) GetSSHUsername() string          { return "docker" }
+
+func TestCmdURLSSHTransport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	defer func(baseDir string, userConfigPath func() string) {
+		mcndirs.BaseDir = baseDir
+		sshUserConfigPath = userConfigPath
+	}(mcndirs.BaseDir, sshUserConfigPath)
+	mcndirs.BaseDir = dir
+	userConfigPath := filepath.Join(dir, "ssh_config")
+	sshUserConfigPath = func() string { return userConfigPath }
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "machines", "machine"), 0700))
+
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"machine"},
+		LocalFlags: &commandstest.FakeFlagger{
+			Data: map[string]interface{}{
+				"transport": "ssh",
+			},
+		},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name: "machine",
+				Driver: &sshFakeDriver{&fakedriver.Driver{
+					MockState: state.Running,
+					MockIP:    "120.0.0.1",
+					MockName:  "machine",
+				}},
+			},
+		},
+		MachinesDir: filepath.Join(dir, "machines"),
+	}
+
+	stdoutGetter := commandstest.NewStdoutGetter()
+	err = cmdURL(commandLine, api)
+	stdoutGetter.Stop()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ssh://docker@120.0.0.1:22\n", stdoutGetter.Output())
+	_, err = os.Stat(filepath.Join(dir, "machines", "machine", "ssh_config"))
+	assert.NoError(t, err)
+	_, err = os.Stat(userConfigPath)
+	assert.True(t, os.IsNotExist(err), "the ssh_config of the user is left as it is")
+
+	includeCommandLine := &commandstest.FakeCommandLine{
+		LocalFlags: &commandstest.FakeFlagger{
+			Data: map[string]interface{}{
+				"include": true,
+			},
+		},
+	}
+	assert.NoError(t, cmdSSHConfig(includeCommandLine, api))
+
+	stdoutGetter = commandstest.NewStdoutGetter()
+	defer stdoutGetter.Stop()
+
+	err = cmdURL(commandLine, api)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ssh://docker@docker-machine-machine\n", stdoutGetter.Output())
+}
+
+func TestCmdURLUnknownTransport(t *testing.T) {
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"machine"},
+		LocalFlags: &commandstest.FakeFlagger{
+			Data: map[string]interface{}{
+				"transport": "udp",
+			},
+		},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name:   "machine",
+				Driver: &fakedriver.Driver{MockState: state.Running},
+			},
+		},
+	}
+
+	err := cmdURL(commandLine, api)
+
+	assert.EqualError(t, err, `Unknown transport "udp", expected tcp or ssh`)
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/classmarkets/docker-machine/libmachine"
+	"github.com/classmarkets/docker-machine/libmachine/log"
+)
+
+var errNoCurrentMachine = errors.New("No current machine, select one with: docker-machine use <name>")
+
+// currentMachineFile records the machine selected with use, in the machines
+// directory. It's hidden so it isn't taken for a machine.
+const currentMachineFile = ".current"
+
+func currentMachinePath(machinesDir string) string {
+	return filepath.Join(machinesDir, currentMachineFile)
+}
+
+// readCurrentMachine returns the machine selected with use, or "" if there's
+// none.
+func readCurrentMachine(machinesDir string) (string, error) {
+	content, err := ioutil.ReadFile(currentMachinePath(machinesDir))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("Error reading the current machine: %s", err)
+	}
+
+	return strings.TrimSpace(string(content)), nil
+}
+
+// writeCurrentMachine records the machine selected with use, or forgets it
+// if name is empty.
+func writeCurrentMachine(machinesDir, name string) error {
+	path := currentMachinePath(machinesDir)
+
+	if name == "" {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	return ioutil.WriteFile(path, []byte(name+"\n"), 0600)
+}
+
+func cmdUse(c CommandLine, api libmachine.API) error {
+	machinesDir := api.GetMachinesDir()
+
+	if c.Bool("unset") {
+		if len(c.Args()) > 0 {
+			return ErrTooManyArguments
+		}
+
+		if err := writeCurrentMachine(machinesDir, ""); err != nil {
+			return err
+		}
+
+		if c.Bool("docker-context") {
+			return setCurrentDockerContext(dockerConfigDir(), "")
+		}
+
+		return nil
+	}
+
+	if len(c.Args()) == 0 {
+		current, err := readCurrentMachine(machinesDir)
+		if err != nil {
+			return err
+		}
+		if current == "" {
+			return errNoCurrentMachine
+		}
+
+		fmt.Println(current)
+		return nil
+	}
+
+	if len(c.Args()) > 1 {
+		return ErrExpectedOneMachine
+	}
+
+	name := c.Args().First()
+
+	h, err := api.Load(name)
+	if err != nil {
+		return err
+	}
+
+	if err := writeCurrentMachine(machinesDir, h.Name); err != nil {
+		return err
+	}
+
+	if c.Bool("docker-context") {
+		configDir := dockerConfigDir()
+
+		if err := writeDockerContext(configDir, machinesDir, h); err != nil {
+			return fmt.Errorf("Error writing the Docker context: %s", err)
+		}
+
+		if err := setCurrentDockerContext(configDir, dockerContextName(h.Name)); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("Using %s", h.Name)
+
+	return nil
+}
+
+// setCurrentDockerContext makes the Docker CLI use the context, or the
+// default one if it's empty, like docker context use. The other settings of
+// its config.json are kept.
+func setCurrentDockerContext(configDir, contextName string) error {
+	path := filepath.Join(configDir, "config.json")
+
+	config := map[string]interface{}{}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(content) > 0 {
+		if err := json.Unmarshal(content, &config); err != nil {
+			return fmt.Errorf("Error reading %s: %s", path, err)
+		}
+	}
+
+	if contextName == "" {
+		delete(config, "currentContext")
+	} else {
+		config["currentContext"] = contextName
+	}
+
+	content, err = json.MarshalIndent(config, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, content, 0600)
+}
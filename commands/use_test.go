@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/classmarkets/docker-machine/commands/commandstest"
+	"github.com/classmarkets/docker-machine/drivers/fakedriver"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/libmachinetest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCmdUse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	api := &libmachinetest.FakeAPI{
+		MachinesDir: dir,
+		Hosts: []*host.Host{
+			{
+				Name:   defaultMachineName,
+				Driver: &fakedriver.Driver{},
+			},
+			{
+				Name:   "dev",
+				Driver: &fakedriver.Driver{},
+			},
+		},
+	}
+
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"dev"},
+	}
+	assert.NoError(t, cmdUse(commandLine, api))
+
+	target, err := targetHost(&commandstest.FakeCommandLine{}, api)
+	assert.NoError(t, err)
+	assert.Equal(t, "dev", target)
+
+	target, err = targetHost(&commandstest.FakeCommandLine{CliArgs: []string{"other"}}, api)
+	assert.NoError(t, err)
+	assert.Equal(t, "other", target)
+
+	commandLine = &commandstest.FakeCommandLine{
+		LocalFlags: &commandstest.FakeFlagger{
+			Data: map[string]interface{}{
+				"unset": true,
+			},
+		},
+	}
+	assert.NoError(t, cmdUse(commandLine, api))
+
+	target, err = targetHost(&commandstest.FakeCommandLine{}, api)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultMachineName, target)
+}
+
+func TestCmdUseUnknownMachine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	api := &libmachinetest.FakeAPI{MachinesDir: dir}
+
+	err = cmdUse(&commandstest.FakeCommandLine{CliArgs: []string{"dev"}}, api)
+	assert.Error(t, err)
+
+	current, err := readCurrentMachine(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, current)
+}
+
+func TestCmdUseNoCurrentMachine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = cmdUse(&commandstest.FakeCommandLine{}, &libmachinetest.FakeAPI{MachinesDir: dir})
+	assert.Equal(t, errNoCurrentMachine, err)
+}
+
+func TestTargetHostCurrentMachineRemoved(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, writeCurrentMachine(dir, "removed"))
+
+	api := &libmachinetest.FakeAPI{
+		MachinesDir: dir,
+		Hosts: []*host.Host{
+			{
+				Name:   defaultMachineName,
+				Driver: &fakedriver.Driver{},
+			},
+		},
+	}
+
+	target, err := targetHost(&commandstest.FakeCommandLine{}, api)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultMachineName, target)
+}
+
+func TestSetCurrentDockerContext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"auths": {"registry.example.com": {}}}`), 0600))
+
+	assert.NoError(t, setCurrentDockerContext(dir, "dev"))
+
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"auths": {"registry.example.com": {}}, "currentContext": "dev"}`, string(content))
+
+	assert.NoError(t, setCurrentDockerContext(dir, ""))
+
+	content, err = ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"auths": {"registry.example.com": {}}}`, string(content))
+}
@@ -29,7 +29,12 @@ func printVersion(c CommandLine, api libmachine.API, out io.Writer) error {
 		return err
 	}
 
-	version, err := mcndockerclient.DockerVersion(host)
+	url, err := host.URL()
+	if err != nil {
+		return err
+	}
+
+	version, err := mcndockerclient.DockerVersion(host.DockerHost(url))
 	if err != nil {
 		return err
 	}
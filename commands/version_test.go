@@ -7,9 +7,11 @@ import (
 	"bytes"
 
 	"github.com/classmarkets/docker-machine/commands/commandstest"
+	"github.com/classmarkets/docker-machine/drivers/fakedriver"
 	"github.com/classmarkets/docker-machine/libmachine/host"
 	"github.com/classmarkets/docker-machine/libmachine/libmachinetest"
 	"github.com/classmarkets/docker-machine/libmachine/mcndockerclient"
+	"github.com/classmarkets/docker-machine/libmachine/state"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -55,7 +57,8 @@ func TestCmdVersionOnHost(t *testing.T) {
 	api := &libmachinetest.FakeAPI{
 		Hosts: []*host.Host{
 			{
-				Name: "machine",
+				Name:   "machine",
+				Driver: &fakedriver.Driver{MockState: state.Running, MockIP: "192.168.99.100"},
 			},
 		},
 	}
@@ -77,7 +80,8 @@ func TestCmdVersionFailure(t *testing.T) {
 	api := &libmachinetest.FakeAPI{
 		Hosts: []*host.Host{
 			{
-				Name: "machine",
+				Name:   "machine",
+				Driver: &fakedriver.Driver{MockState: state.Running, MockIP: "192.168.99.100"},
 			},
 		},
 	}
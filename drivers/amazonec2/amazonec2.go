@@ -59,6 +59,8 @@ var (
 	errorNoSubnetsFound                  = errors.New("The desired subnet could not be located in this region. Is '--amazonec2-subnet-id' or AWS_SUBNET_ID configured correctly?")
 	errorDisableSSLWithoutCustomEndpoint = errors.New("using --amazonec2-insecure-transport also requires --amazonec2-endpoint")
 	errorReadingUserData                 = errors.New("unable to read --amazonec2-userdata file")
+	errorResizeNeedsInstanceType         = errors.New("amazonec2 driver can only change the CPU and memory of an instance through its instance type")
+	errorResizeDiskNotSupported          = errors.New("amazonec2 driver does not support resizing the root volume")
 )
 
 type Driver struct {
@@ -880,6 +882,32 @@ func (d *Driver) Stop() error {
 	return err
 }
 
+// Resize changes the instance type of a stopped instance.
+func (d *Driver) Resize(opts drivers.ResizeOptions) error {
+	if opts.CPU != 0 || opts.Memory != 0 {
+		return errorResizeNeedsInstanceType
+	}
+
+	if opts.DiskSize != 0 {
+		return errorResizeDiskNotSupported
+	}
+
+	if opts.InstanceType == "" || opts.InstanceType == d.InstanceType {
+		return nil
+	}
+
+	_, err := d.getClient().ModifyInstanceAttribute(&ec2.ModifyInstanceAttributeInput{
+		InstanceId:   &d.InstanceId,
+		InstanceType: &ec2.AttributeValue{Value: aws.String(opts.InstanceType)},
+	})
+	if err != nil {
+		return err
+	}
+
+	d.InstanceType = opts.InstanceType
+	return nil
+}
+
 func (d *Driver) Restart() error {
 	_, err := d.getClient().RebootInstances(&ec2.RebootInstancesInput{
 		InstanceIds: []*string{&d.InstanceId},
@@ -13,6 +13,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/classmarkets/docker-machine/commands/commandstest"
+	"github.com/classmarkets/docker-machine/libmachine/drivers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -558,3 +559,40 @@ func TestBase64UserDataIsCorrectWhenFileProvided(t *testing.T) {
 	assert.NoError(t, ud_err)
 	assert.Equal(t, contentBase64, userdata)
 }
+
+func TestResizeInstanceType(t *testing.T) {
+	client := &fakeEC2WithModifyInstance{}
+	driver := NewCustomTestDriver(client)
+	driver.InstanceId = "i-1234"
+	driver.InstanceType = "t2.micro"
+
+	err := driver.Resize(drivers.ResizeOptions{InstanceType: "t2.large"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "t2.large", driver.InstanceType)
+	assert.Equal(t, "i-1234", *client.input.InstanceId)
+	assert.Equal(t, "t2.large", *client.input.InstanceType.Value)
+}
+
+func TestResizeCPUAndMemoryNeedInstanceType(t *testing.T) {
+	driver := NewCustomTestDriver(&fakeEC2WithModifyInstance{})
+
+	err := driver.Resize(drivers.ResizeOptions{CPU: 2, Memory: 4096})
+
+	assert.Equal(t, errorResizeNeedsInstanceType, err)
+}
+
+func TestResizeDiskNotSupported(t *testing.T) {
+	driver := NewCustomTestDriver(&fakeEC2WithModifyInstance{})
+
+	err := driver.Resize(drivers.ResizeOptions{DiskSize: 40000})
+
+	assert.Equal(t, errorResizeDiskNotSupported, err)
+}
+
+func TestGetCapabilities(t *testing.T) {
+	capabilities, err := drivers.GetCapabilities(NewDriver("machineFoo", "path"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []drivers.Capability{drivers.CapabilityResize}, capabilities)
+}
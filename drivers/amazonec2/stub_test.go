@@ -132,6 +132,16 @@ func (f *fakeEC2SecurityGroupTestRecorder) AuthorizeSecurityGroupIngress(input *
 	return value, err
 }
 
+type fakeEC2WithModifyInstance struct {
+	*fakeEC2
+	input *ec2.ModifyInstanceAttributeInput
+}
+
+func (f *fakeEC2WithModifyInstance) ModifyInstanceAttribute(input *ec2.ModifyInstanceAttributeInput) (*ec2.ModifyInstanceAttributeOutput, error) {
+	f.input = input
+	return &ec2.ModifyInstanceAttributeOutput{}, nil
+}
+
 func NewTestDriver() *Driver {
 	driver := NewDriver("machineFoo", "path")
 	driver.clientFactory = func() Ec2Client {
@@ -10,9 +10,11 @@ import (
 
 type Driver struct {
 	*drivers.BaseDriver
-	MockState state.State
-	MockIP    string
-	MockName  string
+	MockState     state.State
+	MockIP        string
+	MockName      string
+	MockSnapshots []drivers.Snapshot
+	MockResize    drivers.ResizeOptions
 }
 
 func (d *Driver) GetCreateFlags() []mcnflag.Flag {
@@ -107,3 +109,50 @@ func (d *Driver) Remove() error {
 func (d *Driver) Upgrade() error {
 	return nil
 }
+
+func (d *Driver) CreateSnapshot(name string) error {
+	d.MockSnapshots = append(d.MockSnapshots, drivers.Snapshot{Name: name})
+	return nil
+}
+
+func (d *Driver) ListSnapshots() ([]drivers.Snapshot, error) {
+	return d.MockSnapshots, nil
+}
+
+func (d *Driver) RestoreSnapshot(name string) error {
+	for i := range d.MockSnapshots {
+		d.MockSnapshots[i].Current = d.MockSnapshots[i].Name == name
+	}
+	return nil
+}
+
+func (d *Driver) RemoveSnapshot(name string) error {
+	snapshots := []drivers.Snapshot{}
+	for _, snapshot := range d.MockSnapshots {
+		if snapshot.Name != name {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	d.MockSnapshots = snapshots
+	return nil
+}
+
+func (d *Driver) Resize(opts drivers.ResizeOptions) error {
+	d.MockResize = opts
+	return nil
+}
+
+func (d *Driver) Pause() error {
+	d.MockState = state.Paused
+	return nil
+}
+
+func (d *Driver) Resume() error {
+	d.MockState = state.Running
+	return nil
+}
+
+func (d *Driver) Suspend() error {
+	d.MockState = state.Saved
+	return nil
+}
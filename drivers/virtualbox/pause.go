@@ -0,0 +1,34 @@
+package virtualbox
+
+import (
+	"fmt"
+
+	"github.com/classmarkets/docker-machine/libmachine/state"
+)
+
+// Pause freezes the running VM, it keeps using memory but no CPU.
+func (d *Driver) Pause() error {
+	return d.vbm("controlvm", d.MachineName, "pause")
+}
+
+// Resume continues a paused VM, or starts a VM whose state was saved.
+func (d *Driver) Resume() error {
+	s, err := d.GetState()
+	if err != nil {
+		return err
+	}
+
+	switch s {
+	case state.Paused:
+		return d.vbm("controlvm", d.MachineName, "resume")
+	case state.Saved:
+		return d.Start()
+	}
+
+	return fmt.Errorf("Unable to resume a VM which is %s", s)
+}
+
+// Suspend saves the state of the VM to disk and stops it.
+func (d *Driver) Suspend() error {
+	return d.vbm("controlvm", d.MachineName, "savestate")
+}
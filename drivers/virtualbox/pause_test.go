@@ -0,0 +1,54 @@
+package virtualbox
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPause(t *testing.T) {
+	driver := NewDriver("default", "path")
+	mockCalls(t, driver, []Call{
+		{"vbm controlvm default pause", "", nil},
+	})
+
+	assert.NoError(t, driver.Pause())
+}
+
+func TestResumePaused(t *testing.T) {
+	driver := NewDriver("default", "path")
+	mockCalls(t, driver, []Call{
+		{"vbm showvminfo default --machinereadable", `VMState="paused"`, nil},
+		{"vbm controlvm default resume", "", nil},
+	})
+
+	assert.NoError(t, driver.Resume())
+}
+
+func TestResumeStopped(t *testing.T) {
+	driver := NewDriver("default", "path")
+	mockCalls(t, driver, []Call{
+		{"vbm showvminfo default --machinereadable", `VMState="poweroff"`, nil},
+	})
+
+	assert.EqualError(t, driver.Resume(), "Unable to resume a VM which is Stopped")
+}
+
+func TestSuspend(t *testing.T) {
+	driver := NewDriver("default", "path")
+	mockCalls(t, driver, []Call{
+		{"vbm controlvm default savestate", "", nil},
+	})
+
+	assert.NoError(t, driver.Suspend())
+}
+
+func TestStopSaved(t *testing.T) {
+	driver := NewDriver("default", "path")
+	mockCalls(t, driver, []Call{
+		{"vbm showvminfo default --machinereadable", `VMState="saved"`, nil},
+		{"vbm discardstate default", "", nil},
+	})
+
+	assert.NoError(t, driver.Stop())
+}
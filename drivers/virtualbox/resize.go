@@ -0,0 +1,116 @@
+package virtualbox
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/classmarkets/docker-machine/libmachine/drivers"
+	"github.com/classmarkets/docker-machine/libmachine/log"
+	"github.com/classmarkets/docker-machine/libmachine/state"
+)
+
+var (
+	ErrResizeRunningVM  = errors.New("the VM must be stopped before it can be resized")
+	ErrNoInstanceType   = errors.New("VirtualBox VMs have no instance type, use the CPU, memory and disk size options instead")
+	ErrDiskCannotShrink = errors.New("the disk of a VM can only grow")
+)
+
+// Resize changes the CPU count, memory and disk size of a stopped VM.
+func (d *Driver) Resize(opts drivers.ResizeOptions) error {
+	if opts.InstanceType != "" {
+		return ErrNoInstanceType
+	}
+
+	if opts.DiskSize != 0 && opts.DiskSize < d.DiskSize {
+		return ErrDiskCannotShrink
+	}
+
+	s, err := d.GetState()
+	if err != nil {
+		return err
+	}
+
+	if s != state.Stopped {
+		return ErrResizeRunningVM
+	}
+
+	args := []string{}
+	if opts.CPU != 0 {
+		args = append(args, "--cpus", strconv.Itoa(opts.CPU))
+	}
+	if opts.Memory != 0 {
+		args = append(args, "--memory", strconv.Itoa(opts.Memory))
+	}
+
+	if len(args) > 0 {
+		if err := d.vbm(append([]string{"modifyvm", d.MachineName}, args...)...); err != nil {
+			return err
+		}
+
+		if opts.CPU != 0 {
+			d.CPU = opts.CPU
+		}
+		if opts.Memory != 0 {
+			d.Memory = opts.Memory
+		}
+	}
+
+	if opts.DiskSize != 0 && opts.DiskSize != d.DiskSize {
+		if err := d.resizeDisk(opts.DiskSize); err != nil {
+			return err
+		}
+
+		d.DiskSize = opts.DiskSize
+	}
+
+	return nil
+}
+
+// resizeDisk grows the disk of the VM to size MB. VirtualBox can't resize
+// VMDK images, so these are converted to VDI first.
+func (d *Driver) resizeDisk(size int) error {
+	disk, err := getVMDiskInfo(d.MachineName, d.VBoxManager)
+	if err != nil {
+		return err
+	}
+
+	diskPath := disk.Path
+	if diskPath == "" || diskPath == "none" {
+		diskPath = d.diskPath()
+	}
+
+	if strings.EqualFold(filepath.Ext(diskPath), ".vmdk") {
+		vdiPath := strings.TrimSuffix(diskPath, filepath.Ext(diskPath)) + ".vdi"
+
+		log.Infof("Converting %s to VDI so that it can be resized...", diskPath)
+		if err := d.vbm("clonemedium", "disk", diskPath, vdiPath, "--format", "VDI"); err != nil {
+			return err
+		}
+
+		if err := d.vbm("storageattach", d.MachineName,
+			"--storagectl", "SATA",
+			"--port", "1",
+			"--device", "0",
+			"--type", "hdd",
+			"--medium", vdiPath); err != nil {
+			return err
+		}
+
+		if err := d.vbm("closemedium", "disk", diskPath, "--delete"); err != nil {
+			log.Warnf("Unable to remove %s: %s", diskPath, err)
+		}
+
+		diskPath = vdiPath
+	}
+
+	if err := d.vbm("modifymedium", "disk", diskPath, "--resize", strconv.Itoa(size)); err != nil {
+		return fmt.Errorf("Unable to resize %s: %s", diskPath, err)
+	}
+
+	log.Info("The disk was resized, the partitions and filesystems inside the VM may have to be grown to use the new space.")
+
+	return nil
+}
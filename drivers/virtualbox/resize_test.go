@@ -0,0 +1,88 @@
+package virtualbox
+
+import (
+	"testing"
+
+	"github.com/classmarkets/docker-machine/libmachine/drivers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResizeCPUAndMemory(t *testing.T) {
+	driver := NewDriver("default", "path")
+	mockCalls(t, driver, []Call{
+		{"vbm showvminfo default --machinereadable", `VMState="poweroff"`, nil},
+		{"vbm modifyvm default --cpus 4 --memory 4096", "", nil},
+	})
+
+	err := driver.Resize(drivers.ResizeOptions{CPU: 4, Memory: 4096})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, driver.CPU)
+	assert.Equal(t, 4096, driver.Memory)
+	assert.Equal(t, defaultDiskSize, driver.DiskSize)
+}
+
+func TestResizeDiskConvertsVMDK(t *testing.T) {
+	driver := NewDriver("default", "path")
+	mockCalls(t, driver, []Call{
+		{"vbm showvminfo default --machinereadable", `VMState="poweroff"`, nil},
+		{"vbm showvminfo default --machinereadable", `"SATA-1-0"="path/machines/default/disk.vmdk"`, nil},
+		{"vbm clonemedium disk path/machines/default/disk.vmdk path/machines/default/disk.vdi --format VDI", "", nil},
+		{"vbm storageattach default --storagectl SATA --port 1 --device 0 --type hdd --medium path/machines/default/disk.vdi", "", nil},
+		{"vbm closemedium disk path/machines/default/disk.vmdk --delete", "", nil},
+		{"vbm modifymedium disk path/machines/default/disk.vdi --resize 40000", "", nil},
+	})
+
+	err := driver.Resize(drivers.ResizeOptions{DiskSize: 40000})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 40000, driver.DiskSize)
+}
+
+func TestResizeDiskVDI(t *testing.T) {
+	driver := NewDriver("default", "path")
+	mockCalls(t, driver, []Call{
+		{"vbm showvminfo default --machinereadable", `VMState="poweroff"`, nil},
+		{"vbm showvminfo default --machinereadable", `"SATA-1-0"="path/machines/default/disk.vdi"`, nil},
+		{"vbm modifymedium disk path/machines/default/disk.vdi --resize 40000", "", nil},
+	})
+
+	err := driver.Resize(drivers.ResizeOptions{DiskSize: 40000})
+
+	assert.NoError(t, err)
+}
+
+func TestResizeDiskCannotShrink(t *testing.T) {
+	driver := NewDriver("default", "path")
+
+	err := driver.Resize(drivers.ResizeOptions{DiskSize: 1000})
+
+	assert.Equal(t, ErrDiskCannotShrink, err)
+}
+
+func TestResizeRunning(t *testing.T) {
+	driver := NewDriver("default", "path")
+	mockCalls(t, driver, []Call{
+		{"vbm showvminfo default --machinereadable", `VMState="running"`, nil},
+	})
+
+	err := driver.Resize(drivers.ResizeOptions{CPU: 2})
+
+	assert.Equal(t, ErrResizeRunningVM, err)
+}
+
+func TestResizeInstanceType(t *testing.T) {
+	driver := NewDriver("default", "path")
+
+	err := driver.Resize(drivers.ResizeOptions{InstanceType: "t2.large"})
+
+	assert.Equal(t, ErrNoInstanceType, err)
+}
+
+func TestGetCapabilities(t *testing.T) {
+	driver := NewDriver("default", "path")
+
+	assert.True(t, drivers.Supports(driver, drivers.CapabilitySnapshot))
+	assert.True(t, drivers.Supports(driver, drivers.CapabilityResize))
+	assert.True(t, drivers.Supports(driver, drivers.CapabilityPause))
+}
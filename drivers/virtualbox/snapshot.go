@@ -0,0 +1,113 @@
+package virtualbox
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/classmarkets/docker-machine/libmachine/drivers"
+	"github.com/classmarkets/docker-machine/libmachine/state"
+)
+
+const noSnapshots = "does not have any snapshots"
+
+var (
+	reSnapshotLine = regexp.MustCompile(`^(SnapshotName|SnapshotUUID|CurrentSnapshotUUID)((?:-[0-9]+)*)="(.*)"$`)
+
+	ErrSnapshotNameRequired = errors.New("a snapshot name is required")
+	ErrRestoreRunningVM     = errors.New("the VM must be stopped before a snapshot can be restored")
+)
+
+// CreateSnapshot takes a snapshot of the VM, which may be running.
+func (d *Driver) CreateSnapshot(name string) error {
+	if name == "" {
+		return ErrSnapshotNameRequired
+	}
+
+	return d.vbm("snapshot", d.MachineName, "take", name)
+}
+
+// ListSnapshots returns the snapshots of the VM in the order VirtualBox
+// lists them, i.e. parents before their children.
+func (d *Driver) ListSnapshots() ([]drivers.Snapshot, error) {
+	stdout, err := d.vbmOut("snapshot", d.MachineName, "list", "--machinereadable")
+	if err != nil {
+		if strings.Contains(stdout, noSnapshots) {
+			return []drivers.Snapshot{}, nil
+		}
+		return nil, err
+	}
+
+	return parseSnapshots(stdout), nil
+}
+
+// RestoreSnapshot reverts the VM to the given snapshot. VirtualBox can only
+// restore snapshots of VMs which are not running.
+func (d *Driver) RestoreSnapshot(name string) error {
+	if name == "" {
+		return ErrSnapshotNameRequired
+	}
+
+	s, err := d.GetState()
+	if err != nil {
+		return err
+	}
+
+	if s == state.Running || s == state.Paused {
+		return ErrRestoreRunningVM
+	}
+
+	return d.vbm("snapshot", d.MachineName, "restore", name)
+}
+
+// RemoveSnapshot deletes the given snapshot, merging its state into its
+// children.
+func (d *Driver) RemoveSnapshot(name string) error {
+	if name == "" {
+		return ErrSnapshotNameRequired
+	}
+
+	return d.vbm("snapshot", d.MachineName, "delete", name)
+}
+
+// parseSnapshots parses the output of `VBoxManage snapshot list
+// --machinereadable`, where each snapshot of the tree is identified by a
+// suffix such as "-1-2".
+func parseSnapshots(stdout string) []drivers.Snapshot {
+	snapshots := []drivers.Snapshot{}
+	indexes := map[string]int{}
+	currentID := ""
+
+	for _, line := range strings.Split(stdout, "\n") {
+		res := reSnapshotLine.FindStringSubmatch(strings.TrimSpace(line))
+		if res == nil {
+			continue
+		}
+
+		key, suffix, val := res[1], res[2], res[3]
+		if key == "CurrentSnapshotUUID" {
+			currentID = val
+			continue
+		}
+
+		i, ok := indexes[suffix]
+		if !ok {
+			i = len(snapshots)
+			indexes[suffix] = i
+			snapshots = append(snapshots, drivers.Snapshot{})
+		}
+
+		switch key {
+		case "SnapshotName":
+			snapshots[i].Name = val
+		case "SnapshotUUID":
+			snapshots[i].ID = val
+		}
+	}
+
+	for i := range snapshots {
+		snapshots[i].Current = currentID != "" && snapshots[i].ID == currentID
+	}
+
+	return snapshots
+}
@@ -0,0 +1,116 @@
+package virtualbox
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/classmarkets/docker-machine/libmachine/drivers"
+	"github.com/stretchr/testify/assert"
+)
+
+const snapshotListOutput = `SnapshotName="base"
+SnapshotUUID="5e3b8cd2-8f55-4a4b-9c0b-1b6b5e2b9a01"
+SnapshotName-1="before-upgrade"
+SnapshotUUID-1="7a9c1f44-2d1e-4e8a-8c3e-0f1d2c3b4a02"
+SnapshotName-1-1="after-upgrade"
+SnapshotUUID-1-1="9b2d3e55-6f7a-4b8c-9d0e-1f2a3b4c5d03"
+CurrentSnapshotName="before-upgrade"
+CurrentSnapshotUUID="7a9c1f44-2d1e-4e8a-8c3e-0f1d2c3b4a02"
+CurrentSnapshotNode="SnapshotName-1"
+`
+
+func TestCreateSnapshot(t *testing.T) {
+	driver := newTestDriver("default")
+	driver.VBoxManager = &VBoxManagerMock{
+		args: "snapshot default take base",
+	}
+
+	err := driver.CreateSnapshot("base")
+
+	assert.NoError(t, err)
+}
+
+func TestCreateSnapshotWithoutName(t *testing.T) {
+	driver := newTestDriver("default")
+
+	err := driver.CreateSnapshot("")
+
+	assert.Equal(t, ErrSnapshotNameRequired, err)
+}
+
+func TestListSnapshots(t *testing.T) {
+	driver := newTestDriver("default")
+	driver.VBoxManager = &VBoxManagerMock{
+		args:   "snapshot default list --machinereadable",
+		stdOut: snapshotListOutput,
+	}
+
+	snapshots, err := driver.ListSnapshots()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []drivers.Snapshot{
+		{Name: "base", ID: "5e3b8cd2-8f55-4a4b-9c0b-1b6b5e2b9a01"},
+		{Name: "before-upgrade", ID: "7a9c1f44-2d1e-4e8a-8c3e-0f1d2c3b4a02", Current: true},
+		{Name: "after-upgrade", ID: "9b2d3e55-6f7a-4b8c-9d0e-1f2a3b4c5d03"},
+	}, snapshots)
+}
+
+func TestListSnapshotsEmpty(t *testing.T) {
+	driver := newTestDriver("default")
+	driver.VBoxManager = &VBoxManagerMock{
+		args:   "snapshot default list --machinereadable",
+		stdOut: "This machine does not have any snapshots\n",
+		err:    errors.New("exit status 1"),
+	}
+
+	snapshots, err := driver.ListSnapshots()
+
+	assert.NoError(t, err)
+	assert.Empty(t, snapshots)
+}
+
+func TestListSnapshotsError(t *testing.T) {
+	driver := newTestDriver("default")
+	driver.VBoxManager = &VBoxManagerMock{
+		args: "snapshot default list --machinereadable",
+		err:  errors.New("BUG"),
+	}
+
+	_, err := driver.ListSnapshots()
+
+	assert.EqualError(t, err, "BUG")
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	driver := NewDriver("default", "path")
+	mockCalls(t, driver, []Call{
+		{"vbm showvminfo default --machinereadable", `VMState="poweroff"`, nil},
+		{"vbm snapshot default restore base", "", nil},
+	})
+
+	err := driver.RestoreSnapshot("base")
+
+	assert.NoError(t, err)
+}
+
+func TestRestoreSnapshotRunning(t *testing.T) {
+	driver := NewDriver("default", "path")
+	mockCalls(t, driver, []Call{
+		{"vbm showvminfo default --machinereadable", `VMState="running"`, nil},
+	})
+
+	err := driver.RestoreSnapshot("base")
+
+	assert.Equal(t, ErrRestoreRunningVM, err)
+}
+
+func TestRemoveSnapshot(t *testing.T) {
+	driver := newTestDriver("default")
+	driver.VBoxManager = &VBoxManagerMock{
+		args: "snapshot default delete base",
+	}
+
+	err := driver.RemoveSnapshot("base")
+
+	assert.NoError(t, err)
+}
@@ -624,6 +624,10 @@ func (d *Driver) Stop() error {
 		log.Infof("Resuming VM ...")
 	}
 
+	if currentState == state.Saved {
+		return d.vbm("discardstate", d.MachineName)
+	}
+
 	if err := d.vbm("controlvm", d.MachineName, "acpipowerbutton"); err != nil {
 		return err
 	}
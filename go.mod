@@ -18,13 +18,17 @@ require (
 	github.com/docker/docker v17.12.0-ce-rc1.0.20180621001606-093424bec097+incompatible
 	github.com/docker/go-units v0.2.1-0.20151230175859-0bbddae09c5a
 	github.com/exoscale/egoscale v0.9.23
+	github.com/fsnotify/fsnotify v1.4.7
 	github.com/go-ini/ini v0.0.0-20151124192405-03e0e7d51a13
 	github.com/golang/protobuf v0.0.0-20160221214941-3c84672111d9
 	github.com/google/go-querystring v0.0.0-20140804062624-30f7a39f4a21
 	github.com/intel-go/cpuid v0.0.0-20181003105527-1a4a6f06a1c6
 	github.com/jinzhu/copier v0.0.0-20180308034124-7e38e58719c3
 	github.com/jmespath/go-jmespath v0.0.0-20151117175822-3433f3ea46d9
+	github.com/kr/fs v0.1.0 // indirect
 	github.com/mitchellh/mapstructure v0.0.0-20140721150620-740c764bc614
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/pkg/sftp v1.8.3
 	github.com/pmezard/go-difflib v1.0.0
 	github.com/rackspace/gophercloud v1.0.1-0.20150408191457-ce0f487f6747
 	github.com/samalba/dockerclient v0.0.0-20151231000007-f661dd4754aa
@@ -269,26 +269,37 @@ func (xcg *X509CertGenerator) ValidateCertificate(addr string, authOptions *auth
 }
 
 func CheckCertificateDate(certPath string) (bool, error) {
+	notAfter, err := CertificateExpiry(certPath)
+	if err != nil {
+		return false, err
+	}
+	if time.Now().After(notAfter) {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// CertificateExpiry returns the date after which the certificate is no
+// longer valid.
+func CertificateExpiry(certPath string) (time.Time, error) {
 	log.Debugf("Reading certificate data from %s", certPath)
 	certBytes, err := ioutil.ReadFile(certPath)
 	if err != nil {
-		return false, err
+		return time.Time{}, err
 	}
 
 	log.Debug("Decoding PEM data...")
 	pemBlock, _ := pem.Decode(certBytes)
 	if pemBlock == nil {
-		return false, errors.New("Failed to decode PEM data")
+		return time.Time{}, errors.New("Failed to decode PEM data")
 	}
 
 	log.Debug("Parsing certificate...")
 	cert, err := x509.ParseCertificate(pemBlock.Bytes)
 	if err != nil {
-		return false, err
-	}
-	if time.Now().After(cert.NotAfter) {
-		return false, nil
+		return time.Time{}, err
 	}
 
-	return true, nil
+	return cert.NotAfter, nil
 }
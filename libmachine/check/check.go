@@ -1,14 +1,20 @@
 package check
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"strings"
 
 	"github.com/classmarkets/docker-machine/libmachine/auth"
 	"github.com/classmarkets/docker-machine/libmachine/cert"
+	"github.com/classmarkets/docker-machine/libmachine/drivers"
+	"github.com/classmarkets/docker-machine/libmachine/engine"
 	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/log"
+	"github.com/classmarkets/docker-machine/libmachine/ssh"
 )
 
 var (
@@ -60,7 +66,18 @@ func (mcc *MachineConnChecker) Check(h *host.Host, swarm bool) (string, *auth.Op
 
 	authOptions := h.AuthOptions()
 
-	if err := checkCert(u.Host, authOptions); err != nil {
+	check := checkCert
+	if h.HostOptions != nil && h.HostOptions.EngineOptions != nil && h.HostOptions.EngineOptions.Transport == engine.TransportSSH {
+		check = func(hostURL string, authOptions *auth.Options) error {
+			return checkCertThroughSSH(hostURL, authOptions, h.Driver)
+		}
+	} else if sshAuth := drivers.GetSSHAuth(h.Driver); sshAuth.ProxyJump.Address != "" {
+		check = func(hostURL string, authOptions *auth.Options) error {
+			return checkCertThroughJumpHost(hostURL, authOptions, sshAuth)
+		}
+	}
+
+	if err := check(u.Host, authOptions); err != nil {
 		if swarm {
 			// Connection to the swarm port cannot be checked. Maybe it's just the swarm containers that are down
 			// TODO: check the containers and restart them
@@ -88,6 +105,94 @@ func checkCert(hostURL string, authOptions *auth.Options) error {
 	return nil
 }
 
+// checkCertThroughJumpHost validates the certificates of a machine which is
+// only reachable through its SSH jump host, by tunneling the TLS handshake.
+func checkCertThroughJumpHost(hostURL string, authOptions *auth.Options, sshAuth *ssh.Auth) error {
+	if err := validateCertificateThroughJumpHost(hostURL, authOptions, sshAuth); err != nil {
+		return ErrCertInvalid{
+			wrappedErr: err,
+			hostURL:    hostURL,
+		}
+	}
+
+	return nil
+}
+
+func validateCertificateThroughJumpHost(addr string, authOptions *auth.Options, sshAuth *ssh.Auth) error {
+	tlsConfig, err := cert.ReadTLSConfig(addr, authOptions)
+	if err != nil {
+		return err
+	}
+
+	// tls.Client doesn't derive the server name from the address like
+	// tls.Dial does
+	serverName, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	tlsConfig.ServerName = serverName
+
+	log.Debugf("Checking certificates of %s through jump host %s", addr, sshAuth.ProxyJump.Address)
+
+	jumpClient, err := ssh.DialJumpHost(sshAuth)
+	if err != nil {
+		return err
+	}
+	defer jumpClient.Close()
+
+	conn, err := jumpClient.Dial("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("Error dialing %s through jump host: %s", addr, err)
+	}
+
+	tlsConn := tls.Client(conn, tlsConfig)
+	defer tlsConn.Close()
+
+	return tlsConn.Handshake()
+}
+
+// checkCertThroughSSH validates the certificates of a machine whose daemon
+// only listens on its loopback interface, by tunneling the TLS handshake
+// through the SSH connection to the machine.
+func checkCertThroughSSH(hostURL string, authOptions *auth.Options, d drivers.Driver) error {
+	if err := validateCertificateThroughSSH(hostURL, authOptions, d); err != nil {
+		return ErrCertInvalid{
+			wrappedErr: err,
+			hostURL:    hostURL,
+		}
+	}
+
+	return nil
+}
+
+func validateCertificateThroughSSH(addr string, authOptions *auth.Options, d drivers.Driver) error {
+	tlsConfig, err := cert.ReadTLSConfig(addr, authOptions)
+	if err != nil {
+		return err
+	}
+
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+
+	// The server certificate is valid for localhost, which the daemon is
+	// reached as from the machine
+	tlsConfig.ServerName = "localhost"
+
+	log.Debugf("Checking certificates of %s through SSH", addr)
+
+	conn, err := drivers.DialThroughSSH(d, "tcp", net.JoinHostPort("127.0.0.1", port))
+	if err != nil {
+		return err
+	}
+
+	tlsConn := tls.Client(conn, tlsConfig)
+	defer tlsConn.Close()
+
+	return tlsConn.Handshake()
+}
+
 // TODO: This could use a unit test.
 func parseSwarm(hostURL string, h *host.Host) (string, error) {
 	swarmOptions := h.HostOptions.SwarmOptions
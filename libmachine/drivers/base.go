@@ -3,6 +3,8 @@ package drivers
 import (
 	"errors"
 	"path/filepath"
+
+	"github.com/classmarkets/docker-machine/libmachine/ssh"
 )
 
 const (
@@ -23,6 +25,9 @@ type BaseDriver struct {
 	SwarmMaster    bool
 	SwarmHost      string
 	SwarmDiscovery string
+
+	SSHProxyJump        string
+	SSHProxyJumpKeyPath string
 }
 
 // DriverName returns the name of the driver
@@ -78,6 +83,26 @@ func (d *BaseDriver) ResolveStorePath(file string) string {
 	return filepath.Join(d.StorePath, "machines", d.MachineName, file)
 }
 
+// GetKnownHostsPath returns the path of the known_hosts file pinning the
+// host key of the machine
+func (d *BaseDriver) GetKnownHostsPath() string {
+	if d == nil || d.StorePath == "" {
+		return ""
+	}
+	return d.ResolveStorePath("known_hosts")
+}
+
+// GetSSHProxyJump returns the jump host through which the machine is reached
+func (d *BaseDriver) GetSSHProxyJump() ssh.ProxyJump {
+	if d == nil {
+		return ssh.ProxyJump{}
+	}
+	return ssh.ProxyJump{
+		Address: d.SSHProxyJump,
+		KeyPath: d.SSHProxyJumpKeyPath,
+	}
+}
+
 // SetSwarmConfigFromFlags configures the driver for swarm
 func (d *BaseDriver) SetSwarmConfigFromFlags(flags DriverOptions) {
 	d.SwarmMaster = flags.Bool("swarm-master")
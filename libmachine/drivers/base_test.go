@@ -2,6 +2,7 @@ package drivers
 
 import (
 	"errors"
+	"path/filepath"
 	"testing"
 
 	"github.com/classmarkets/docker-machine/libmachine/mcnflag"
@@ -48,3 +49,20 @@ func TestEngineInstallUrlFlagSet(t *testing.T) {
 	options := createDriverOptionWithEngineInstall("https://test.docker.com")
 	assert.True(t, EngineInstallURLFlagSet(options))
 }
+
+type knownHostsDriver struct {
+	Driver
+	knownHostsPath string
+}
+
+func (d *knownHostsDriver) GetKnownHostsPath() string {
+	return d.knownHostsPath
+}
+
+func TestGetSSHControlPath(t *testing.T) {
+	d := &knownHostsDriver{knownHostsPath: filepath.Join("store", "machines", "dev", "known_hosts")}
+	assert.Equal(t, filepath.Join("store", "machines", "dev", "ssh.sock"), GetSSHControlPath(d))
+
+	d.knownHostsPath = ""
+	assert.Empty(t, GetSSHControlPath(d))
+}
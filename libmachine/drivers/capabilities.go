@@ -0,0 +1,99 @@
+package drivers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/classmarkets/docker-machine/libmachine/log"
+)
+
+// Capability names an optional feature that a driver can implement on top
+// of the Driver interface.
+type Capability string
+
+const (
+	// CapabilitySnapshot is advertised by drivers implementing Snapshotter.
+	CapabilitySnapshot Capability = "snapshot"
+
+	// CapabilityResize is advertised by drivers implementing Resizer.
+	CapabilityResize Capability = "resize"
+
+	// CapabilityPause is advertised by drivers implementing Pauser.
+	CapabilityPause Capability = "pause"
+)
+
+// ErrNotSupported is returned by drivers asked to perform an optional
+// operation they don't implement.
+var ErrNotSupported = errors.New("Operation not supported by the driver")
+
+// ErrCapabilityNotSupported is returned when an operation requires a
+// capability the driver of a machine does not advertise.
+type ErrCapabilityNotSupported struct {
+	DriverName string
+	Capability Capability
+}
+
+func (e ErrCapabilityNotSupported) Error() string {
+	return fmt.Sprintf("Driver %q does not support %s", e.DriverName, e.Capability)
+}
+
+// CapabilityReporter is implemented by drivers which wrap another driver,
+// e.g. over RPC, and therefore can't be inspected with type assertions. The
+// other drivers advertise the optional interfaces they implement.
+type CapabilityReporter interface {
+	// GetCapabilities returns the optional features of the wrapped driver
+	GetCapabilities() ([]Capability, error)
+}
+
+// GetCapabilities returns the optional features supported by a driver.
+func GetCapabilities(d Driver) ([]Capability, error) {
+	if reporter, ok := d.(CapabilityReporter); ok {
+		return reporter.GetCapabilities()
+	}
+
+	capabilities := []Capability{}
+
+	if _, ok := d.(Snapshotter); ok {
+		capabilities = append(capabilities, CapabilitySnapshot)
+	}
+
+	if _, ok := d.(Resizer); ok {
+		capabilities = append(capabilities, CapabilityResize)
+	}
+
+	if _, ok := d.(Pauser); ok {
+		capabilities = append(capabilities, CapabilityPause)
+	}
+
+	return capabilities, nil
+}
+
+// Supports returns true if the driver advertises the given capability.
+func Supports(d Driver, capability Capability) bool {
+	capabilities, err := GetCapabilities(d)
+	if err != nil {
+		log.Debugf("Error getting driver capabilities: %s", err)
+		return false
+	}
+
+	for _, c := range capabilities {
+		if c == capability {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RequireCapability returns ErrCapabilityNotSupported if the driver does not
+// advertise the given capability.
+func RequireCapability(d Driver, driverName string, capability Capability) error {
+	if !Supports(d, capability) {
+		return ErrCapabilityNotSupported{
+			DriverName: driverName,
+			Capability: capability,
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package drivers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type MockCapabilityReporter struct {
+	MockDriver
+	capabilities []Capability
+	err          error
+}
+
+func (d *MockCapabilityReporter) GetCapabilities() ([]Capability, error) {
+	return d.capabilities, d.err
+}
+
+func TestGetCapabilities(t *testing.T) {
+	capabilities, err := GetCapabilities(&MockDriver{})
+
+	assert.NoError(t, err)
+	assert.Empty(t, capabilities)
+}
+
+func TestGetCapabilitiesSnapshotter(t *testing.T) {
+	capabilities, err := GetCapabilities(&MockSnapshotDriver{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Capability{CapabilitySnapshot}, capabilities)
+}
+
+func TestGetCapabilitiesReporter(t *testing.T) {
+	capabilities, err := GetCapabilities(&MockCapabilityReporter{capabilities: []Capability{CapabilitySnapshot}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Capability{CapabilitySnapshot}, capabilities)
+}
+
+func TestSupports(t *testing.T) {
+	assert.True(t, Supports(&MockSnapshotDriver{}, CapabilitySnapshot))
+	assert.False(t, Supports(&MockDriver{}, CapabilitySnapshot))
+	assert.False(t, Supports(&MockCapabilityReporter{err: errors.New("BUG")}, CapabilitySnapshot))
+}
+
+func TestSerialDriverGetCapabilities(t *testing.T) {
+	callRecorder := &CallRecorder{}
+
+	driver := newSerialDriverWithLock(&MockSnapshotDriver{MockDriver{calls: callRecorder}}, &MockLocker{calls: callRecorder})
+	capabilities, err := GetCapabilities(driver)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Capability{CapabilitySnapshot}, capabilities)
+	assert.Equal(t, []string{"Lock", "Unlock"}, callRecorder.calls)
+}
+
+func TestRequireCapability(t *testing.T) {
+	driver := &MockCapabilityReporter{capabilities: []Capability{CapabilityResize}}
+
+	assert.NoError(t, RequireCapability(driver, "mock", CapabilityResize))
+	assert.EqualError(t, RequireCapability(driver, "mock", CapabilitySnapshot), `Driver "mock" does not support snapshot`)
+}
@@ -0,0 +1,14 @@
+package drivers
+
+// Pauser is implemented by drivers which can freeze a running machine, or
+// save its state to disk, without going through a full stop/start cycle.
+type Pauser interface {
+	// Pause freezes a running machine, leaving it in memory
+	Pause() error
+
+	// Resume continues a paused or saved machine
+	Resume() error
+
+	// Suspend saves the state of the machine to disk and stops it
+	Suspend() error
+}
@@ -0,0 +1,29 @@
+package drivers
+
+// ResizeOptions describes the new resources of a machine. Zero values leave
+// the corresponding resource unchanged.
+type ResizeOptions struct {
+	// CPU is the number of CPUs
+	CPU int
+
+	// Memory is the size of the memory in MB
+	Memory int
+
+	// DiskSize is the size of the disk in MB
+	DiskSize int
+
+	// InstanceType is the provider specific instance type, e.g. t2.large
+	InstanceType string
+}
+
+// IsEmpty returns true if no resource is to be changed.
+func (o ResizeOptions) IsEmpty() bool {
+	return o.CPU == 0 && o.Memory == 0 && o.DiskSize == 0 && o.InstanceType == ""
+}
+
+// Resizer is implemented by drivers which are able to change the resources
+// of an existing machine. Resize is only called on stopped machines.
+type Resizer interface {
+	// Resize changes the resources of the machine
+	Resize(opts ResizeOptions) error
+}
@@ -3,6 +3,7 @@ package rpcdriver
 import (
 	"fmt"
 	"net/rpc"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/classmarkets/docker-machine/libmachine/drivers/plugin/localbinary"
 	"github.com/classmarkets/docker-machine/libmachine/log"
 	"github.com/classmarkets/docker-machine/libmachine/mcnflag"
+	"github.com/classmarkets/docker-machine/libmachine/ssh"
 	"github.com/classmarkets/docker-machine/libmachine/state"
 	"github.com/classmarkets/docker-machine/libmachine/version"
 )
@@ -83,6 +85,17 @@ const (
 	RestartMethod            = `.Restart`
 	KillMethod               = `.Kill`
 	UpgradeMethod            = `.Upgrade`
+	GetCapabilitiesMethod    = `.GetCapabilities`
+	CreateSnapshotMethod     = `.CreateSnapshot`
+	ListSnapshotsMethod      = `.ListSnapshots`
+	RestoreSnapshotMethod    = `.RestoreSnapshot`
+	RemoveSnapshotMethod     = `.RemoveSnapshot`
+	ResizeMethod             = `.Resize`
+	PauseMethod              = `.Pause`
+	ResumeMethod             = `.Resume`
+	SuspendMethod            = `.Suspend`
+	GetKnownHostsPathMethod  = `.GetKnownHostsPath`
+	GetSSHProxyJumpMethod    = `.GetSSHProxyJump`
 )
 
 func (ic *InternalClient) Call(serviceMethod string, args interface{}, reply interface{}) error {
@@ -365,3 +378,110 @@ func (c *RPCClientDriver) Kill() error {
 func (c *RPCClientDriver) Upgrade() error {
 	return c.Client.Call(UpgradeMethod, struct{}{}, nil)
 }
+
+// GetCapabilities returns the optional features of the driver plugin.
+// Plugins built before capabilities were introduced don't support any.
+func (c *RPCClientDriver) GetCapabilities() ([]drivers.Capability, error) {
+	var capabilities []drivers.Capability
+
+	if err := c.Client.Call(GetCapabilitiesMethod, struct{}{}, &capabilities); err != nil {
+		if isMethodNotFound(err) {
+			return []drivers.Capability{}, nil
+		}
+		return nil, err
+	}
+
+	return capabilities, nil
+}
+
+func (c *RPCClientDriver) CreateSnapshot(name string) error {
+	return notSupportedErr(c.Client.Call(CreateSnapshotMethod, name, nil))
+}
+
+func (c *RPCClientDriver) ListSnapshots() ([]drivers.Snapshot, error) {
+	var snapshots []drivers.Snapshot
+
+	if err := c.Client.Call(ListSnapshotsMethod, struct{}{}, &snapshots); err != nil {
+		return nil, notSupportedErr(err)
+	}
+
+	return snapshots, nil
+}
+
+func (c *RPCClientDriver) RestoreSnapshot(name string) error {
+	return notSupportedErr(c.Client.Call(RestoreSnapshotMethod, name, nil))
+}
+
+func (c *RPCClientDriver) RemoveSnapshot(name string) error {
+	return notSupportedErr(c.Client.Call(RemoveSnapshotMethod, name, nil))
+}
+
+func (c *RPCClientDriver) Resize(opts drivers.ResizeOptions) error {
+	return notSupportedErr(c.Client.Call(ResizeMethod, opts, nil))
+}
+
+func (c *RPCClientDriver) Pause() error {
+	return notSupportedErr(c.Client.Call(PauseMethod, struct{}{}, nil))
+}
+
+func (c *RPCClientDriver) Resume() error {
+	return notSupportedErr(c.Client.Call(ResumeMethod, struct{}{}, nil))
+}
+
+func (c *RPCClientDriver) Suspend() error {
+	return notSupportedErr(c.Client.Call(SuspendMethod, struct{}{}, nil))
+}
+
+// GetKnownHostsPath returns the path of the known_hosts file pinning the
+// host key of the machine. Host keys of machines managed by plugins built
+// before host keys were pinned aren't checked.
+func (c *RPCClientDriver) GetKnownHostsPath() string {
+	var knownHostsPath string
+
+	if err := c.Client.Call(GetKnownHostsPathMethod, struct{}{}, &knownHostsPath); err != nil {
+		if isMethodNotFound(err) {
+			log.Debugf("Plugin does not pin host keys: %s", err)
+		} else {
+			log.Warnf("Error attempting call to get known_hosts path: %s", err)
+		}
+	}
+
+	return knownHostsPath
+}
+
+// GetSSHProxyJump returns the jump host through which the machine is
+// reached. Machines managed by plugins built before jump hosts were
+// supported are connected to directly.
+func (c *RPCClientDriver) GetSSHProxyJump() ssh.ProxyJump {
+	var proxyJump ssh.ProxyJump
+
+	if err := c.Client.Call(GetSSHProxyJumpMethod, struct{}{}, &proxyJump); err != nil {
+		if isMethodNotFound(err) {
+			log.Debugf("Plugin does not support jump hosts: %s", err)
+		} else {
+			log.Warnf("Error attempting call to get jump host: %s", err)
+		}
+	}
+
+	return proxyJump
+}
+
+func isMethodNotFound(err error) bool {
+	_, ok := err.(rpc.ServerError)
+	return ok && strings.HasPrefix(err.Error(), "rpc: can't find method")
+}
+
+// notSupportedErr turns the errors of an optional method which isn't
+// implemented by the plugin, or isn't known by an older plugin, back into
+// drivers.ErrNotSupported.
+func notSupportedErr(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if err.Error() == drivers.ErrNotSupported.Error() || isMethodNotFound(err) {
+		return drivers.ErrNotSupported
+	}
+
+	return err
+}
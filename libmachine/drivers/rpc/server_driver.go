@@ -9,6 +9,7 @@ import (
 	"github.com/classmarkets/docker-machine/libmachine/drivers"
 	"github.com/classmarkets/docker-machine/libmachine/log"
 	"github.com/classmarkets/docker-machine/libmachine/mcnflag"
+	"github.com/classmarkets/docker-machine/libmachine/ssh"
 	"github.com/classmarkets/docker-machine/libmachine/state"
 	"github.com/classmarkets/docker-machine/libmachine/version"
 )
@@ -221,6 +222,114 @@ func (r *RPCServerDriver) Stop(_ *struct{}, _ *struct{}) error {
 	return r.ActualDriver.Stop()
 }
 
+func (r *RPCServerDriver) GetCapabilities(_ *struct{}, reply *[]drivers.Capability) error {
+	capabilities, err := drivers.GetCapabilities(r.ActualDriver)
+	*reply = capabilities
+	return err
+}
+
+func (r *RPCServerDriver) snapshotter() (drivers.Snapshotter, error) {
+	snapshotter, ok := r.ActualDriver.(drivers.Snapshotter)
+	if !ok {
+		return nil, drivers.ErrNotSupported
+	}
+
+	return snapshotter, nil
+}
+
+func (r *RPCServerDriver) CreateSnapshot(name string, _ *struct{}) error {
+	snapshotter, err := r.snapshotter()
+	if err != nil {
+		return err
+	}
+
+	return snapshotter.CreateSnapshot(name)
+}
+
+func (r *RPCServerDriver) ListSnapshots(_ *struct{}, reply *[]drivers.Snapshot) error {
+	snapshotter, err := r.snapshotter()
+	if err != nil {
+		return err
+	}
+
+	snapshots, err := snapshotter.ListSnapshots()
+	*reply = snapshots
+	return err
+}
+
+func (r *RPCServerDriver) RestoreSnapshot(name string, _ *struct{}) error {
+	snapshotter, err := r.snapshotter()
+	if err != nil {
+		return err
+	}
+
+	return snapshotter.RestoreSnapshot(name)
+}
+
+func (r *RPCServerDriver) RemoveSnapshot(name string, _ *struct{}) error {
+	snapshotter, err := r.snapshotter()
+	if err != nil {
+		return err
+	}
+
+	return snapshotter.RemoveSnapshot(name)
+}
+
+func (r *RPCServerDriver) Resize(opts drivers.ResizeOptions, _ *struct{}) error {
+	resizer, ok := r.ActualDriver.(drivers.Resizer)
+	if !ok {
+		return drivers.ErrNotSupported
+	}
+
+	return resizer.Resize(opts)
+}
+
+func (r *RPCServerDriver) pauser() (drivers.Pauser, error) {
+	pauser, ok := r.ActualDriver.(drivers.Pauser)
+	if !ok {
+		return nil, drivers.ErrNotSupported
+	}
+
+	return pauser, nil
+}
+
+func (r *RPCServerDriver) Pause(_ *struct{}, _ *struct{}) error {
+	pauser, err := r.pauser()
+	if err != nil {
+		return err
+	}
+
+	return pauser.Pause()
+}
+
+func (r *RPCServerDriver) Resume(_ *struct{}, _ *struct{}) error {
+	pauser, err := r.pauser()
+	if err != nil {
+		return err
+	}
+
+	return pauser.Resume()
+}
+
+func (r *RPCServerDriver) Suspend(_ *struct{}, _ *struct{}) error {
+	pauser, err := r.pauser()
+	if err != nil {
+		return err
+	}
+
+	return pauser.Suspend()
+}
+
+func (r *RPCServerDriver) GetKnownHostsPath(_ *struct{}, reply *string) error {
+	*reply = drivers.GetKnownHostsPath(r.ActualDriver)
+	return nil
+}
+
+func (r *RPCServerDriver) GetSSHProxyJump(_ *struct{}, reply *ssh.ProxyJump) error {
+	*reply = drivers.GetSSHProxyJump(r.ActualDriver)
+	return nil
+}
+
 func (r *RPCServerDriver) Heartbeat(_ *struct{}, _ *struct{}) error {
 	r.HeartbeatCh <- true
 	return nil
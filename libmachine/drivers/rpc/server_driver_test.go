@@ -2,9 +2,11 @@ package rpcdriver
 
 import (
 	"errors"
+	"net/rpc"
 	"testing"
 
 	"github.com/classmarkets/docker-machine/drivers/fakedriver"
+	"github.com/classmarkets/docker-machine/libmachine/drivers"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -73,3 +75,38 @@ func TestRPCServerDriverCreate(t *testing.T) {
 		assert.Equal(t, tc.expectedErr, tc.serverDriver.Create(nil, nil))
 	}
 }
+
+type noSnapshotDriver struct {
+	drivers.Driver
+}
+
+func TestRPCServerDriverSnapshotNotSupported(t *testing.T) {
+	serverDriver := NewRPCServerDriver(&noSnapshotDriver{&fakedriver.Driver{}})
+
+	var capabilities []drivers.Capability
+	assert.NoError(t, serverDriver.GetCapabilities(nil, &capabilities))
+	assert.Empty(t, capabilities)
+	assert.Equal(t, drivers.ErrNotSupported, serverDriver.CreateSnapshot("snap", nil))
+}
+
+func TestRPCServerDriverSnapshot(t *testing.T) {
+	fakeDriver := &fakedriver.Driver{}
+	serverDriver := NewRPCServerDriver(fakeDriver)
+
+	var capabilities []drivers.Capability
+	assert.NoError(t, serverDriver.GetCapabilities(nil, &capabilities))
+	assert.Equal(t, []drivers.Capability{drivers.CapabilitySnapshot, drivers.CapabilityResize, drivers.CapabilityPause}, capabilities)
+
+	assert.NoError(t, serverDriver.CreateSnapshot("snap", nil))
+
+	var snapshots []drivers.Snapshot
+	assert.NoError(t, serverDriver.ListSnapshots(nil, &snapshots))
+	assert.Equal(t, []drivers.Snapshot{{Name: "snap"}}, snapshots)
+}
+
+func TestNotSupportedErr(t *testing.T) {
+	assert.Nil(t, notSupportedErr(nil))
+	assert.Equal(t, drivers.ErrNotSupported, notSupportedErr(rpc.ServerError(drivers.ErrNotSupported.Error())))
+	assert.Equal(t, drivers.ErrNotSupported, notSupportedErr(rpc.ServerError("rpc: can't find method RPCServerDriver.CreateSnapshot")))
+	assert.Equal(t, rpc.ServerError("BUG"), notSupportedErr(rpc.ServerError("BUG")))
+}
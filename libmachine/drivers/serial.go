@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 
 	"github.com/classmarkets/docker-machine/libmachine/mcnflag"
+	"github.com/classmarkets/docker-machine/libmachine/ssh"
 	"github.com/classmarkets/docker-machine/libmachine/state"
 )
 
@@ -167,6 +168,123 @@ func (d *SerialDriver) Stop() error {
 	return d.Driver.Stop()
 }
 
+// GetCapabilities returns the optional features of the wrapped driver
+func (d *SerialDriver) GetCapabilities() ([]Capability, error) {
+	d.Lock()
+	defer d.Unlock()
+	return GetCapabilities(d.Driver)
+}
+
+// CreateSnapshot takes a snapshot of the machine
+func (d *SerialDriver) CreateSnapshot(name string) error {
+	snapshotter, ok := d.Driver.(Snapshotter)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	d.Lock()
+	defer d.Unlock()
+	return snapshotter.CreateSnapshot(name)
+}
+
+// ListSnapshots returns the snapshots of the machine
+func (d *SerialDriver) ListSnapshots() ([]Snapshot, error) {
+	snapshotter, ok := d.Driver.(Snapshotter)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+
+	d.Lock()
+	defer d.Unlock()
+	return snapshotter.ListSnapshots()
+}
+
+// RestoreSnapshot reverts the machine to the given snapshot
+func (d *SerialDriver) RestoreSnapshot(name string) error {
+	snapshotter, ok := d.Driver.(Snapshotter)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	d.Lock()
+	defer d.Unlock()
+	return snapshotter.RestoreSnapshot(name)
+}
+
+// RemoveSnapshot deletes the given snapshot
+func (d *SerialDriver) RemoveSnapshot(name string) error {
+	snapshotter, ok := d.Driver.(Snapshotter)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	d.Lock()
+	defer d.Unlock()
+	return snapshotter.RemoveSnapshot(name)
+}
+
+// Resize changes the resources of the machine
+func (d *SerialDriver) Resize(opts ResizeOptions) error {
+	resizer, ok := d.Driver.(Resizer)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	d.Lock()
+	defer d.Unlock()
+	return resizer.Resize(opts)
+}
+
+// Pause freezes the running machine
+func (d *SerialDriver) Pause() error {
+	pauser, ok := d.Driver.(Pauser)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	d.Lock()
+	defer d.Unlock()
+	return pauser.Pause()
+}
+
+// Resume continues a paused or saved machine
+func (d *SerialDriver) Resume() error {
+	pauser, ok := d.Driver.(Pauser)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	d.Lock()
+	defer d.Unlock()
+	return pauser.Resume()
+}
+
+// Suspend saves the state of the machine to disk
+func (d *SerialDriver) Suspend() error {
+	pauser, ok := d.Driver.(Pauser)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	d.Lock()
+	defer d.Unlock()
+	return pauser.Suspend()
+}
+
+// GetKnownHostsPath returns the path of the known_hosts file of the machine
+func (d *SerialDriver) GetKnownHostsPath() string {
+	d.Lock()
+	defer d.Unlock()
+	return GetKnownHostsPath(d.Driver)
+}
+
+// GetSSHProxyJump returns the jump host through which the machine is reached
+func (d *SerialDriver) GetSSHProxyJump() ssh.ProxyJump {
+	d.Lock()
+	defer d.Unlock()
+	return GetSSHProxyJump(d.Driver)
+}
+
 func (d *SerialDriver) MarshalJSON() ([]byte, error) {
 	return json.Marshal(d.Driver)
 }
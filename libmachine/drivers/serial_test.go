@@ -294,3 +294,74 @@ func TestSerialDriverStop(t *testing.T) {
 
 	assert.Equal(t, []string{"Lock", "Stop", "Unlock"}, callRecorder.calls)
 }
+
+type MockSnapshotDriver struct {
+	MockDriver
+}
+
+func (d *MockSnapshotDriver) CreateSnapshot(name string) error {
+	d.calls.record("CreateSnapshot " + name)
+	return nil
+}
+
+func (d *MockSnapshotDriver) ListSnapshots() ([]Snapshot, error) {
+	d.calls.record("ListSnapshots")
+	return []Snapshot{{Name: "snap"}}, nil
+}
+
+func (d *MockSnapshotDriver) RestoreSnapshot(name string) error {
+	d.calls.record("RestoreSnapshot " + name)
+	return nil
+}
+
+func (d *MockSnapshotDriver) RemoveSnapshot(name string) error {
+	d.calls.record("RemoveSnapshot " + name)
+	return nil
+}
+
+func TestSerialDriverCreateSnapshot(t *testing.T) {
+	callRecorder := &CallRecorder{}
+
+	driver := newSerialDriverWithLock(&MockSnapshotDriver{MockDriver{calls: callRecorder}}, &MockLocker{calls: callRecorder})
+	driver.(Snapshotter).CreateSnapshot("snap")
+
+	assert.Equal(t, []string{"Lock", "CreateSnapshot snap", "Unlock"}, callRecorder.calls)
+}
+
+func TestSerialDriverListSnapshots(t *testing.T) {
+	callRecorder := &CallRecorder{}
+
+	driver := newSerialDriverWithLock(&MockSnapshotDriver{MockDriver{calls: callRecorder}}, &MockLocker{calls: callRecorder})
+	snapshots, _ := driver.(Snapshotter).ListSnapshots()
+
+	assert.Equal(t, []Snapshot{{Name: "snap"}}, snapshots)
+	assert.Equal(t, []string{"Lock", "ListSnapshots", "Unlock"}, callRecorder.calls)
+}
+
+func TestSerialDriverRestoreSnapshot(t *testing.T) {
+	callRecorder := &CallRecorder{}
+
+	driver := newSerialDriverWithLock(&MockSnapshotDriver{MockDriver{calls: callRecorder}}, &MockLocker{calls: callRecorder})
+	driver.(Snapshotter).RestoreSnapshot("snap")
+
+	assert.Equal(t, []string{"Lock", "RestoreSnapshot snap", "Unlock"}, callRecorder.calls)
+}
+
+func TestSerialDriverRemoveSnapshot(t *testing.T) {
+	callRecorder := &CallRecorder{}
+
+	driver := newSerialDriverWithLock(&MockSnapshotDriver{MockDriver{calls: callRecorder}}, &MockLocker{calls: callRecorder})
+	driver.(Snapshotter).RemoveSnapshot("snap")
+
+	assert.Equal(t, []string{"Lock", "RemoveSnapshot snap", "Unlock"}, callRecorder.calls)
+}
+
+func TestSerialDriverSnapshotNotSupported(t *testing.T) {
+	callRecorder := &CallRecorder{}
+
+	driver := newSerialDriverWithLock(&MockDriver{calls: callRecorder}, &MockLocker{calls: callRecorder})
+	err := driver.(Snapshotter).CreateSnapshot("snap")
+
+	assert.Equal(t, ErrNotSupported, err)
+	assert.Empty(t, callRecorder.calls)
+}
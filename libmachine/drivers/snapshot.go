@@ -0,0 +1,24 @@
+package drivers
+
+// Snapshot is a saved point-in-time copy of a machine.
+type Snapshot struct {
+	Name    string
+	ID      string
+	Current bool
+}
+
+// Snapshotter is implemented by drivers which are able to take and restore
+// snapshots of a machine.
+type Snapshotter interface {
+	// CreateSnapshot takes a snapshot of the machine
+	CreateSnapshot(name string) error
+
+	// ListSnapshots returns the snapshots of the machine
+	ListSnapshots() ([]Snapshot, error)
+
+	// RestoreSnapshot reverts the machine to the given snapshot
+	RestoreSnapshot(name string) error
+
+	// RemoveSnapshot deletes the given snapshot
+	RemoveSnapshot(name string) error
+}
@@ -2,12 +2,17 @@ package drivers
 
 import (
 	"fmt"
+	"net"
+	"path/filepath"
 
 	"github.com/classmarkets/docker-machine/libmachine/log"
 	"github.com/classmarkets/docker-machine/libmachine/mcnutils"
 	"github.com/classmarkets/docker-machine/libmachine/ssh"
 )
 
+// sshClientPool holds the clients shared by the commands run on machines
+var sshClientPool = ssh.NewPool()
+
 func GetSSHClientFromDriver(d Driver) (ssh.Client, error) {
 	address, err := d.GetSSHHostname()
 	if err != nil {
@@ -19,6 +24,87 @@ func GetSSHClientFromDriver(d Driver) (ssh.Client, error) {
 		return nil, err
 	}
 
+	client, err := ssh.NewClient(d.GetSSHUsername(), address, port, GetSSHAuth(d))
+	return client, err
+
+}
+
+// DialThroughSSH connects to addr as seen from the machine, through a
+// connection of its own to the machine, e.g. to reach a daemon which only
+// listens on the loopback interface.
+func DialThroughSSH(d Driver, network, addr string) (net.Conn, error) {
+	address, err := d.GetSSHHostname()
+	if err != nil {
+		return nil, err
+	}
+
+	port, err := d.GetSSHPort()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := ssh.NewNativeClient(d.GetSSHUsername(), address, port, GetSSHAuth(d))
+	if err != nil {
+		return nil, err
+	}
+
+	return client.(*ssh.NativeClient).DialMachine(network, addr)
+}
+
+// GetSharedSSHClientFromDriver returns a client whose commands share one
+// connection to the machine with the other commands run through the
+// driver, e.g. while provisioning. The connection stays open until
+// CloseSSHClients is called.
+func GetSharedSSHClientFromDriver(d Driver) (ssh.Client, error) {
+	address, err := d.GetSSHHostname()
+	if err != nil {
+		return nil, err
+	}
+
+	port, err := d.GetSSHPort()
+	if err != nil {
+		return nil, err
+	}
+
+	username := d.GetSSHUsername()
+	target := fmt.Sprintf("%s@%s:%d", username, address, port)
+
+	return sshClientPool.Get(d.GetMachineName(), target, func() (ssh.Client, error) {
+		auth := GetSSHAuth(d)
+		auth.ControlPath = GetSSHControlPath(d)
+
+		return ssh.NewClient(username, address, port, auth)
+	})
+}
+
+// CloseSSHClient closes the connection shared by the commands run on the
+// machine, which doesn't survive the machine being stopped or restarted.
+func CloseSSHClient(d Driver) error {
+	return sshClientPool.CloseClient(d.GetMachineName())
+}
+
+// CloseSSHClients closes the connections shared by the commands run on
+// machines.
+func CloseSSHClients() error {
+	return sshClientPool.Close()
+}
+
+// GetSSHControlPath returns the path of the socket through which the shared
+// connection to the machine is multiplexed. It lives next to the known_hosts
+// file in the machine directory, connections aren't shared if the driver
+// doesn't pin host keys.
+func GetSSHControlPath(d Driver) string {
+	knownHostsPath := GetKnownHostsPath(d)
+	if knownHostsPath == "" {
+		return ""
+	}
+
+	return filepath.Join(filepath.Dir(knownHostsPath), "ssh.sock")
+}
+
+// GetSSHAuth returns the credentials, pinned host key and jump host used to
+// connect to the machine.
+func GetSSHAuth(d Driver) *ssh.Auth {
 	var auth *ssh.Auth
 	if d.GetSSHKeyPath() == "" {
 		auth = &ssh.Auth{}
@@ -28,13 +114,50 @@ func GetSSHClientFromDriver(d Driver) (ssh.Client, error) {
 		}
 	}
 
-	client, err := ssh.NewClient(d.GetSSHUsername(), address, port, auth)
-	return client, err
+	auth.KnownHostsFile = GetKnownHostsPath(d)
+	auth.HostKeyAlias = d.GetMachineName()
+	auth.ProxyJump = GetSSHProxyJump(d)
+
+	return auth
+}
+
+// KnownHostsPather is implemented by drivers which pin the host key of the
+// machine in a known_hosts file.
+type KnownHostsPather interface {
+	// GetKnownHostsPath returns the path of the known_hosts file
+	GetKnownHostsPath() string
+}
+
+// GetKnownHostsPath returns the path of the known_hosts file pinning the host
+// key of the machine, or an empty string if the driver doesn't pin it.
+func GetKnownHostsPath(d Driver) string {
+	if pather, ok := d.(KnownHostsPather); ok {
+		return pather.GetKnownHostsPath()
+	}
+
+	return ""
+}
+
+// SSHProxyJumper is implemented by drivers of machines which may be reached
+// through a jump host.
+type SSHProxyJumper interface {
+	// GetSSHProxyJump returns the jump host, its address is empty if the
+	// machine is connected to directly
+	GetSSHProxyJump() ssh.ProxyJump
+}
+
+// GetSSHProxyJump returns the jump host through which the machine is
+// reached, its address is empty if the driver doesn't use one.
+func GetSSHProxyJump(d Driver) ssh.ProxyJump {
+	if jumper, ok := d.(SSHProxyJumper); ok {
+		return jumper.GetSSHProxyJump()
+	}
 
+	return ssh.ProxyJump{}
 }
 
 func RunSSHCommandFromDriver(d Driver, command string) (string, error) {
-	client, err := GetSSHClientFromDriver(d)
+	client, err := GetSharedSSHClientFromDriver(d)
 	if err != nil {
 		return "", err
 	}
@@ -2,6 +2,13 @@ package engine
 
 const (
 	DefaultPort = 2376
+
+	// TransportTCP exposes the daemon with TLS on DefaultPort
+	TransportTCP = "tcp"
+
+	// TransportSSH only lets the machine itself connect to the daemon, the
+	// Docker client reaches it over SSH
+	TransportSSH = "ssh"
 )
 
 type Options struct {
@@ -18,4 +25,18 @@ type Options struct {
 	TLSVerify        bool `json:"TlsVerify"`
 	RegistryMirror   []string
 	InstallURL       string
+
+	// Transport is how the Docker client reaches the daemon, TransportTCP
+	// if it's empty
+	Transport string `json:",omitempty"`
+}
+
+// BindAddress returns the address the daemon listens on for TLS
+// connections.
+func (o Options) BindAddress() string {
+	if o.Transport == TransportSSH {
+		return "127.0.0.1"
+	}
+
+	return "0.0.0.0"
 }
@@ -0,0 +1,226 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/log"
+)
+
+var ErrSyntax = errors.New("Unsupported filter syntax")
+
+// Options are the conditions machines are selected with. A machine matches
+// if it matches one of the values of each condition which has values, and
+// none of the values of the conditions of Not.
+type Options struct {
+	SwarmName  []string
+	DriverName []string
+	State      []string
+	Name       []string
+	Labels     []string
+
+	// Not are the conditions given as key!=value.
+	Not *Options
+}
+
+// Parse parses filters in the form key=value or key!=value, the keys being
+// swarm, driver, state, name and label. Names are regular expressions,
+// labels are key=value or key to match the machines with the label set.
+func Parse(filters []string) (Options, error) {
+	options := Options{}
+	for _, f := range filters {
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) != 2 {
+			return options, ErrSyntax
+		}
+		key, value := strings.ToLower(kv[0]), kv[1]
+
+		target := &options
+		if strings.HasSuffix(key, "!") {
+			key = strings.TrimSuffix(key, "!")
+			if options.Not == nil {
+				options.Not = &Options{}
+			}
+			target = options.Not
+		}
+
+		switch key {
+		case "swarm":
+			target.SwarmName = append(target.SwarmName, value)
+		case "driver":
+			target.DriverName = append(target.DriverName, value)
+		case "state":
+			target.State = append(target.State, value)
+		case "name":
+			if _, err := regexp.Compile(value); err != nil {
+				return options, fmt.Errorf("Invalid name filter %q: %s", value, err)
+			}
+			target.Name = append(target.Name, value)
+		case "label":
+			target.Labels = append(target.Labels, value)
+		default:
+			return options, fmt.Errorf("Unsupported filter key '%s'", key)
+		}
+	}
+	return options, nil
+}
+
+// IsEmpty returns whether there are no conditions, all the machines match.
+func (o Options) IsEmpty() bool {
+	return len(o.SwarmName) == 0 &&
+		len(o.DriverName) == 0 &&
+		len(o.State) == 0 &&
+		len(o.Name) == 0 &&
+		len(o.Labels) == 0 &&
+		(o.Not == nil || o.Not.IsEmpty())
+}
+
+// Hosts returns the hosts matching the filters.
+func Hosts(hosts []*host.Host, filters Options) []*host.Host {
+	if filters.IsEmpty() {
+		return hosts
+	}
+
+	filteredHosts := []*host.Host{}
+	swarmMasters := SwarmMasters(hosts)
+
+	for _, h := range hosts {
+		if matches(h, filters, swarmMasters) {
+			filteredHosts = append(filteredHosts, h)
+		}
+	}
+	return filteredHosts
+}
+
+// SwarmMasters returns the names of the Swarm masters by discovery.
+func SwarmMasters(hosts []*host.Host) map[string]string {
+	swarmMasters := make(map[string]string)
+	for _, h := range hosts {
+		if h.HostOptions != nil {
+			swarmOptions := h.HostOptions.SwarmOptions
+			if swarmOptions != nil && swarmOptions.Master {
+				swarmMasters[swarmOptions.Discovery] = h.Name
+			}
+		}
+	}
+	return swarmMasters
+}
+
+func matches(h *host.Host, filters Options, swarmMasters map[string]string) bool {
+	conditions := []struct {
+		values  []string
+		matcher func(*host.Host, []string, map[string]string) bool
+	}{
+		{filters.SwarmName, matchesSwarmName},
+		{filters.DriverName, matchesDriverName},
+		{filters.State, matchesState},
+		{filters.Name, matchesName},
+		{filters.Labels, matchesLabel},
+	}
+
+	for _, condition := range conditions {
+		if len(condition.values) > 0 && !condition.matcher(h, condition.values, swarmMasters) {
+			return false
+		}
+	}
+
+	if filters.Not != nil {
+		excluded := []struct {
+			values  []string
+			matcher func(*host.Host, []string, map[string]string) bool
+		}{
+			{filters.Not.SwarmName, matchesSwarmName},
+			{filters.Not.DriverName, matchesDriverName},
+			{filters.Not.State, matchesState},
+			{filters.Not.Name, matchesName},
+			{filters.Not.Labels, matchesLabel},
+		}
+
+		for _, condition := range excluded {
+			if len(condition.values) > 0 && condition.matcher(h, condition.values, swarmMasters) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func matchesSwarmName(h *host.Host, swarmNames []string, swarmMasters map[string]string) bool {
+	for _, n := range swarmNames {
+		if h.HostOptions != nil && h.HostOptions.SwarmOptions != nil {
+			if strings.EqualFold(n, swarmMasters[h.HostOptions.SwarmOptions.Discovery]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesDriverName(h *host.Host, driverNames []string, _ map[string]string) bool {
+	for _, n := range driverNames {
+		if strings.EqualFold(h.DriverName, n) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesState(h *host.Host, states []string, _ map[string]string) bool {
+	s, err := h.Driver.GetState()
+	if err != nil {
+		log.Warn(err)
+	}
+
+	for _, n := range states {
+		if strings.EqualFold(n, s.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesName(h *host.Host, names []string, _ map[string]string) bool {
+	for _, n := range names {
+		// The names are checked by Parse
+		r, err := regexp.Compile(n)
+		if err != nil {
+			continue
+		}
+		if r.MatchString(h.Driver.GetMachineName()) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesLabel(h *host.Host, labels []string, _ map[string]string) bool {
+	englabels := map[string]string{}
+
+	if h.HostOptions != nil && h.HostOptions.EngineOptions != nil {
+		for _, s := range h.HostOptions.EngineOptions.Labels {
+			kv := strings.SplitN(s, "=", 2)
+			if len(kv) == 1 {
+				kv = append(kv, "")
+			}
+			englabels[kv[0]] = kv[1]
+		}
+	}
+
+	for _, l := range labels {
+		kv := strings.SplitN(l, "=", 2)
+		val, exists := englabels[kv[0]]
+		if !exists {
+			continue
+		}
+
+		// A label without value matches the machines which have it set
+		if len(kv) == 1 || strings.EqualFold(val, kv[1]) {
+			return true
+		}
+	}
+	return false
+}
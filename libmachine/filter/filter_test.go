@@ -0,0 +1,371 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/classmarkets/docker-machine/drivers/fakedriver"
+	"github.com/classmarkets/docker-machine/libmachine/engine"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/state"
+	"github.com/classmarkets/docker-machine/libmachine/swarm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseErrorsGivenInvalidFilter(t *testing.T) {
+	_, err := Parse([]string{"foo=bar"})
+	assert.EqualError(t, err, "Unsupported filter key 'foo'")
+}
+
+func TestParseSwarm(t *testing.T) {
+	actual, _ := Parse([]string{"swarm=foo"})
+	assert.Equal(t, actual, Options{SwarmName: []string{"foo"}})
+}
+
+func TestParseDriver(t *testing.T) {
+	actual, _ := Parse([]string{"driver=bar"})
+	assert.Equal(t, actual, Options{DriverName: []string{"bar"}})
+}
+
+func TestParseState(t *testing.T) {
+	actual, _ := Parse([]string{"state=Running"})
+	assert.Equal(t, actual, Options{State: []string{"Running"}})
+}
+
+func TestParseName(t *testing.T) {
+	actual, _ := Parse([]string{"name=dev"})
+	assert.Equal(t, actual, Options{Name: []string{"dev"}})
+}
+
+func TestParseLabel(t *testing.T) {
+	actual, err := Parse([]string{"label=com.example.foo=bar"})
+	assert.EqualValues(t, actual, Options{Labels: []string{"com.example.foo=bar"}})
+	assert.Nil(t, err, "returned err value must be Nil")
+}
+
+func TestParseAll(t *testing.T) {
+	actual, _ := Parse([]string{"swarm=foo", "driver=bar", "state=Stopped", "name=dev"})
+	assert.Equal(t, actual, Options{SwarmName: []string{"foo"}, DriverName: []string{"bar"}, State: []string{"Stopped"}, Name: []string{"dev"}})
+}
+
+func TestParseAllCase(t *testing.T) {
+	actual, err := Parse([]string{"sWarM=foo", "DrIver=bar", "StaTe=Stopped", "NAMe=dev", "LABEL=com=foo"})
+	assert.Equal(t, actual, Options{SwarmName: []string{"foo"}, DriverName: []string{"bar"}, State: []string{"Stopped"}, Name: []string{"dev"}, Labels: []string{"com=foo"}})
+	assert.Nil(t, err, "err should be nil")
+}
+
+func TestParseDuplicates(t *testing.T) {
+	actual, _ := Parse([]string{"swarm=foo", "driver=bar", "name=mark", "swarm=baz", "driver=qux", "state=Running", "state=Starting", "name=time"})
+	assert.Equal(t, actual, Options{SwarmName: []string{"foo", "baz"}, DriverName: []string{"bar", "qux"}, State: []string{"Running", "Starting"}, Name: []string{"mark", "time"}})
+}
+
+func TestParseValueWithEqual(t *testing.T) {
+	actual, _ := Parse([]string{"driver=bar=baz"})
+	assert.Equal(t, actual, Options{DriverName: []string{"bar=baz"}})
+}
+
+func TestHostsReturnsFiltersValuesCaseInsensitive(t *testing.T) {
+	opts := Options{
+		SwarmName:  []string{"fOo"},
+		DriverName: []string{"ViRtUaLboX"},
+		State:      []string{"StOPpeD"},
+		Labels:     []string{"com.EXAMPLE.app=FOO"},
+	}
+	hosts := []*host.Host{}
+	actual := Hosts(hosts, opts)
+	assert.EqualValues(t, actual, hosts)
+}
+func TestHostsReturnsSameGivenNoFilters(t *testing.T) {
+	opts := Options{}
+	hosts := []*host.Host{
+		{
+			Name:       "testhost",
+			DriverName: "fakedriver",
+		},
+	}
+	actual := Hosts(hosts, opts)
+	assert.EqualValues(t, actual, hosts)
+}
+
+func TestHostsReturnSetLabel(t *testing.T) {
+	opts := Options{
+		Labels: []string{"com.class.foo=bar"},
+	}
+	hosts := []*host.Host{
+		{
+			Name:       "testhost",
+			DriverName: "fakedriver",
+			HostOptions: &host.Options{
+				EngineOptions: &engine.Options{
+					Labels: []string{"com.class.foo=bar"},
+				},
+			},
+		},
+	}
+	actual := Hosts(hosts, opts)
+	assert.EqualValues(t, actual, hosts)
+}
+
+func TestHostsReturnsEmptyGivenEmptyHosts(t *testing.T) {
+	opts := Options{
+		SwarmName: []string{"foo"},
+	}
+	hosts := []*host.Host{}
+	assert.Empty(t, Hosts(hosts, opts))
+}
+
+func TestHostsReturnsEmptyGivenNonMatchingFilters(t *testing.T) {
+	opts := Options{
+		SwarmName: []string{"foo"},
+	}
+	hosts := []*host.Host{
+		{
+			Name:       "testhost",
+			DriverName: "fakedriver",
+		},
+	}
+	assert.Empty(t, Hosts(hosts, opts))
+}
+
+func TestHostsBySwarmName(t *testing.T) {
+	opts := Options{
+		SwarmName: []string{"master"},
+	}
+	master :=
+		&host.Host{
+			Name: "master",
+			HostOptions: &host.Options{
+				SwarmOptions: &swarm.Options{Master: true, Discovery: "foo"},
+			},
+		}
+	node1 :=
+		&host.Host{
+			Name: "node1",
+			HostOptions: &host.Options{
+				SwarmOptions: &swarm.Options{Master: false, Discovery: "foo"},
+			},
+		}
+	othermaster :=
+		&host.Host{
+			Name: "othermaster",
+			HostOptions: &host.Options{
+				SwarmOptions: &swarm.Options{Master: true, Discovery: "bar"},
+			},
+		}
+	hosts := []*host.Host{master, node1, othermaster}
+	expected := []*host.Host{master, node1}
+
+	assert.EqualValues(t, Hosts(hosts, opts), expected)
+}
+
+func TestHostsByDriverName(t *testing.T) {
+	opts := Options{
+		DriverName: []string{"fakedriver"},
+	}
+	node1 :=
+		&host.Host{
+			Name:       "node1",
+			DriverName: "fakedriver",
+		}
+	node2 :=
+		&host.Host{
+			Name:       "node2",
+			DriverName: "virtualbox",
+		}
+	node3 :=
+		&host.Host{
+			Name:       "node3",
+			DriverName: "fakedriver",
+		}
+	hosts := []*host.Host{node1, node2, node3}
+	expected := []*host.Host{node1, node3}
+
+	assert.EqualValues(t, Hosts(hosts, opts), expected)
+}
+
+func TestHostsByState(t *testing.T) {
+	opts := Options{
+		State: []string{"Paused", "Saved", "Stopped"},
+	}
+	node1 :=
+		&host.Host{
+			Name:       "node1",
+			DriverName: "fakedriver",
+			Driver:     &fakedriver.Driver{MockState: state.Paused},
+		}
+	node2 :=
+		&host.Host{
+			Name:       "node2",
+			DriverName: "virtualbox",
+			Driver:     &fakedriver.Driver{MockState: state.Stopped},
+		}
+	node3 :=
+		&host.Host{
+			Name:       "node3",
+			DriverName: "fakedriver",
+			Driver:     &fakedriver.Driver{MockState: state.Running},
+		}
+	hosts := []*host.Host{node1, node2, node3}
+	expected := []*host.Host{node1, node2}
+
+	assert.EqualValues(t, Hosts(hosts, opts), expected)
+}
+
+func TestHostsByName(t *testing.T) {
+	opts := Options{
+		Name: []string{"fire", "ice", "earth", "a.?r"},
+	}
+	node1 :=
+		&host.Host{
+			Name:       "fire",
+			DriverName: "fakedriver",
+			Driver:     &fakedriver.Driver{MockState: state.Paused, MockName: "fire"},
+		}
+	node2 :=
+		&host.Host{
+			Name:       "ice",
+			DriverName: "adriver",
+			Driver:     &fakedriver.Driver{MockState: state.Paused, MockName: "ice"},
+		}
+	node3 :=
+		&host.Host{
+			Name:       "air",
+			DriverName: "nodriver",
+			Driver:     &fakedriver.Driver{MockState: state.Paused, MockName: "air"},
+		}
+	node4 :=
+		&host.Host{
+			Name:       "water",
+			DriverName: "falsedriver",
+			Driver:     &fakedriver.Driver{MockState: state.Paused, MockName: "water"},
+		}
+	hosts := []*host.Host{node1, node2, node3, node4}
+	expected := []*host.Host{node1, node2, node3}
+
+	assert.EqualValues(t, Hosts(hosts, opts), expected)
+}
+
+func TestHostsMultiFlags(t *testing.T) {
+	opts := Options{
+		SwarmName:  []string{},
+		DriverName: []string{"fakedriver", "virtualbox"},
+	}
+	node1 :=
+		&host.Host{
+			Name:       "node1",
+			DriverName: "fakedriver",
+		}
+	node2 :=
+		&host.Host{
+			Name:       "node2",
+			DriverName: "virtualbox",
+		}
+	node3 :=
+		&host.Host{
+			Name:       "node3",
+			DriverName: "softlayer",
+		}
+	hosts := []*host.Host{node1, node2, node3}
+	expected := []*host.Host{node1, node2}
+
+	assert.EqualValues(t, Hosts(hosts, opts), expected)
+}
+
+func TestHostsDifferentFlagsProduceAND(t *testing.T) {
+	opts := Options{
+		DriverName: []string{"virtualbox"},
+		State:      []string{"Running"},
+	}
+
+	hosts := []*host.Host{
+		{
+			Name:       "node1",
+			DriverName: "fakedriver",
+			Driver:     &fakedriver.Driver{MockState: state.Paused},
+		},
+		{
+			Name:       "node2",
+			DriverName: "virtualbox",
+			Driver:     &fakedriver.Driver{MockState: state.Stopped},
+		},
+		{
+			Name:       "node3",
+			DriverName: "fakedriver",
+			Driver:     &fakedriver.Driver{MockState: state.Running},
+		},
+	}
+
+	assert.Empty(t, Hosts(hosts, opts))
+}
+
+func TestParseNegation(t *testing.T) {
+	actual, err := Parse([]string{"driver=virtualbox", "state!=Running", "label!=env=prod"})
+	assert.NoError(t, err)
+	assert.Equal(t, Options{DriverName: []string{"virtualbox"}, Not: &Options{State: []string{"Running"}, Labels: []string{"env=prod"}}}, actual)
+}
+
+func TestParseInvalidName(t *testing.T) {
+	_, err := Parse([]string{"name=("})
+	assert.Error(t, err)
+}
+
+func TestHostsByLabelKey(t *testing.T) {
+	opts := Options{
+		Labels: []string{"env"},
+	}
+	node1 :=
+		&host.Host{
+			Name: "node1",
+			HostOptions: &host.Options{
+				EngineOptions: &engine.Options{Labels: []string{"env=prod"}},
+			},
+		}
+	node2 :=
+		&host.Host{
+			Name: "node2",
+			HostOptions: &host.Options{
+				EngineOptions: &engine.Options{Labels: []string{"team=a"}},
+			},
+		}
+	node3 :=
+		&host.Host{
+			Name: "node3",
+		}
+	hosts := []*host.Host{node1, node2, node3}
+	expected := []*host.Host{node1}
+
+	assert.EqualValues(t, Hosts(hosts, opts), expected)
+}
+
+func TestHostsNegation(t *testing.T) {
+	opts, err := Parse([]string{"driver=fakedriver", "name!=^test", "state!=Stopped"})
+	assert.NoError(t, err)
+
+	node1 :=
+		&host.Host{
+			Name:       "node1",
+			DriverName: "fakedriver",
+			Driver:     &fakedriver.Driver{MockState: state.Running, MockName: "node1"},
+		}
+	node2 :=
+		&host.Host{
+			Name:       "test2",
+			DriverName: "fakedriver",
+			Driver:     &fakedriver.Driver{MockState: state.Running, MockName: "test2"},
+		}
+	node3 :=
+		&host.Host{
+			Name:       "node3",
+			DriverName: "fakedriver",
+			Driver:     &fakedriver.Driver{MockState: state.Stopped, MockName: "node3"},
+		}
+	node4 :=
+		&host.Host{
+			Name:       "node4",
+			DriverName: "virtualbox",
+			Driver:     &fakedriver.Driver{MockState: state.Running, MockName: "node4"},
+		}
+	hosts := []*host.Host{node1, node2, node3, node4}
+	expected := []*host.Host{node1}
+
+	assert.EqualValues(t, Hosts(hosts, opts), expected)
+}
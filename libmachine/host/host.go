@@ -1,7 +1,9 @@
 package host
 
 import (
+	"net"
 	"regexp"
+	"time"
 
 	"github.com/classmarkets/docker-machine/libmachine/auth"
 	"github.com/classmarkets/docker-machine/libmachine/cert"
@@ -25,6 +27,13 @@ var (
 	stdSSHClientCreator  SSHClientCreator = &StandardSSHClientCreator{}
 )
 
+// requiredStates lists the states a machine has to be in to be moved to
+// another state. Transitions which aren't listed are always allowed.
+var requiredStates = map[state.State][]state.State{
+	state.Paused: {state.Running},
+	state.Saved:  {state.Running, state.Paused},
+}
+
 type SSHClientCreator interface {
 	CreateSSHClient(d drivers.Driver) (ssh.Client, error)
 }
@@ -44,6 +53,10 @@ type Host struct {
 	HostOptions   *Options
 	Name          string
 	RawDriver     []byte `json:"-"`
+
+	// Created is when the machine was created, it's unknown for the
+	// machines created before it was recorded.
+	Created time.Time
 }
 
 type Options struct {
@@ -53,6 +66,14 @@ type Options struct {
 	EngineOptions *engine.Options
 	SwarmOptions  *swarm.Options
 	AuthOptions   *auth.Options
+
+	// DependsOn are the machines to start before this one, and to stop
+	// after it, when they're started or stopped together.
+	DependsOn []string
+	// StartPriority orders the machines started together which don't
+	// depend on each other, the higher priorities are started first and
+	// stopped last.
+	StartPriority int
 }
 
 type Metadata struct {
@@ -84,22 +105,32 @@ func (creator *StandardSSHClientCreator) CreateSSHClient(d drivers.Driver) (ssh.
 		return &ssh.ExternalClient{}, err
 	}
 
-	auth := &ssh.Auth{}
-	if d.GetSSHKeyPath() != "" {
-		auth.Keys = []string{d.GetSSHKeyPath()}
-	}
-
-	return ssh.NewClient(d.GetSSHUsername(), addr, port, auth)
+	return ssh.NewClient(d.GetSSHUsername(), addr, port, drivers.GetSSHAuth(d))
 }
 
 func (h *Host) runActionForState(action func() error, desiredState state.State) error {
-	if drivers.MachineInState(h.Driver, desiredState)() {
+	currentState, err := h.Driver.GetState()
+	if err != nil {
+		log.Debugf("Error getting machine state: %s", err)
+	}
+
+	if err == nil && currentState == desiredState {
 		return mcnerror.ErrHostAlreadyInState{
 			Name:  h.Name,
 			State: desiredState,
 		}
 	}
 
+	if allowed, ok := requiredStates[desiredState]; err == nil && ok && !stateIn(currentState, allowed) {
+		return mcnerror.ErrInvalidHostState{
+			Name:         h.Name,
+			State:        currentState,
+			DesiredState: desiredState,
+		}
+	}
+
+	h.closeSSHClient()
+
 	if err := action(); err != nil {
 		return err
 	}
@@ -107,6 +138,24 @@ func (h *Host) runActionForState(action func() error, desiredState state.State)
 	return mcnutils.WaitFor(drivers.MachineInState(h.Driver, desiredState))
 }
 
+// closeSSHClient closes the connection shared by the provisioning commands
+// before the machine changes state, rather than letting it go stale.
+func (h *Host) closeSSHClient() {
+	if err := drivers.CloseSSHClient(h.Driver); err != nil {
+		log.Debugf("Error closing SSH connection: %s", err)
+	}
+}
+
+func stateIn(s state.State, states []state.State) bool {
+	for _, candidate := range states {
+		if s == candidate {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (h *Host) WaitForDocker() error {
 	provisioner, err := provision.DetectProvisioner(h.Driver)
 	if err != nil {
@@ -154,6 +203,7 @@ func (h *Host) Restart() error {
 			return err
 		}
 	} else if drivers.MachineInState(h.Driver, state.Running)() {
+		h.closeSSHClient()
 		if err := h.Driver.Restart(); err != nil {
 			return err
 		}
@@ -165,17 +215,138 @@ func (h *Host) Restart() error {
 	return h.WaitForDocker()
 }
 
-func (h *Host) DockerVersion() (string, error) {
-	url, err := h.Driver.GetURL()
+// Pause freezes the machine without stopping it.
+func (h *Host) Pause() error {
+	pauser, err := h.pauser()
 	if err != nil {
-		return "", err
+		return err
+	}
+
+	log.Infof("Pausing %q...", h.Name)
+	if err := h.runActionForState(pauser.Pause, state.Paused); err != nil {
+		return err
+	}
+
+	log.Infof("Machine %q was paused.", h.Name)
+	return nil
+}
+
+// Resume continues a paused or suspended machine.
+func (h *Host) Resume() error {
+	pauser, err := h.pauser()
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Resuming %q...", h.Name)
+	if err := h.runActionForState(pauser.Resume, state.Running); err != nil {
+		return err
+	}
+
+	log.Infof("Machine %q was resumed.", h.Name)
+	return nil
+}
+
+// Suspend saves the state of the machine to disk and stops it.
+func (h *Host) Suspend() error {
+	pauser, err := h.pauser()
+	if err != nil {
+		return err
 	}
 
-	dockerHost := &mcndockerclient.RemoteDocker{
+	log.Infof("Suspending %q...", h.Name)
+	if err := h.runActionForState(pauser.Suspend, state.Saved); err != nil {
+		return err
+	}
+
+	log.Infof("Machine %q was suspended.", h.Name)
+	return nil
+}
+
+func (h *Host) pauser() (drivers.Pauser, error) {
+	if err := drivers.RequireCapability(h.Driver, h.DriverName, drivers.CapabilityPause); err != nil {
+		return nil, err
+	}
+
+	pauser, ok := h.Driver.(drivers.Pauser)
+	if !ok {
+		return nil, drivers.ErrNotSupported
+	}
+
+	return pauser, nil
+}
+
+// Resize changes the resources of the machine. A running machine is stopped
+// first and started again once it was resized.
+func (h *Host) Resize(opts drivers.ResizeOptions) error {
+	resizer, ok := h.Driver.(drivers.Resizer)
+	if !ok {
+		return drivers.ErrNotSupported
+	}
+
+	wasRunning := drivers.MachineInState(h.Driver, state.Running)()
+	if wasRunning {
+		if err := h.Stop(); err != nil {
+			return err
+		}
+	}
+
+	log.Infof("Resizing %q...", h.Name)
+	if err := resizer.Resize(opts); err != nil {
+		return err
+	}
+
+	if h.HostOptions != nil {
+		if opts.Memory != 0 {
+			h.HostOptions.Memory = opts.Memory
+		}
+		if opts.DiskSize != 0 {
+			h.HostOptions.Disk = opts.DiskSize
+		}
+	}
+
+	log.Infof("Machine %q was resized.", h.Name)
+
+	if wasRunning {
+		return h.Start()
+	}
+
+	return nil
+}
+
+// DockerHost returns the Docker host of the machine at url. A daemon only
+// listening on the loopback interface of the machine, with the ssh
+// transport, is reached over SSH.
+func (h *Host) DockerHost(url string) mcndockerclient.DockerHost {
+	dockerHost := mcndockerclient.RemoteDocker{
 		HostURL:    url,
 		AuthOption: h.AuthOptions(),
 	}
-	dockerVersion, err := mcndockerclient.DockerVersion(dockerHost)
+
+	if h.HostOptions == nil || h.HostOptions.EngineOptions == nil || h.HostOptions.EngineOptions.Transport != engine.TransportSSH {
+		return &dockerHost
+	}
+
+	return &mcndockerclient.TunneledDocker{
+		RemoteDocker: dockerHost,
+		Tunnel: func(network, addr string) (net.Conn, error) {
+			_, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+
+			return drivers.DialThroughSSH(h.Driver, network, net.JoinHostPort("127.0.0.1", port))
+		},
+	}
+}
+
+func (h *Host) DockerVersion() (string, error) {
+	url, err := h.Driver.GetURL()
+	if err != nil {
+		return "", err
+	}
+
+	dockerVersion, err := mcndockerclient.DockerVersion(h.DockerHost(url))
 	if err != nil {
 		return "", err
 	}
@@ -5,6 +5,9 @@ import (
 
 	"github.com/classmarkets/docker-machine/drivers/fakedriver"
 	_ "github.com/classmarkets/docker-machine/drivers/none"
+	"github.com/classmarkets/docker-machine/libmachine/drivers"
+	"github.com/classmarkets/docker-machine/libmachine/engine"
+	"github.com/classmarkets/docker-machine/libmachine/mcndockerclient"
 	"github.com/classmarkets/docker-machine/libmachine/provision"
 	"github.com/classmarkets/docker-machine/libmachine/state"
 )
@@ -39,6 +42,28 @@ func TestValidateHostnameInvalid(t *testing.T) {
 	}
 }
 
+func TestDockerHost(t *testing.T) {
+	host := &Host{
+		Driver:      &fakedriver.Driver{},
+		HostOptions: &Options{EngineOptions: &engine.Options{}},
+	}
+
+	dockerHost := host.DockerHost("tcp://1.2.3.4:2376")
+	if _, ok := dockerHost.(mcndockerclient.Dialer); ok {
+		t.Fatal("Expected the daemon to be reached at its URL")
+	}
+	if url, _ := dockerHost.URL(); url != "tcp://1.2.3.4:2376" {
+		t.Fatalf("Unexpected URL %q", url)
+	}
+
+	host.HostOptions.EngineOptions.Transport = engine.TransportSSH
+
+	dockerHost = host.DockerHost("tcp://1.2.3.4:2376")
+	if _, ok := dockerHost.(mcndockerclient.Dialer); !ok {
+		t.Fatal("Expected the daemon to be reached through SSH")
+	}
+}
+
 func TestStart(t *testing.T) {
 	defer provision.SetDetector(&provision.StandardDetector{})
 	provision.SetDetector(&provision.FakeDetector{
@@ -55,3 +80,95 @@ func TestStart(t *testing.T) {
 		t.Fatalf("Expected no error but got one: %s", err)
 	}
 }
+
+func TestResize(t *testing.T) {
+	driver := &fakedriver.Driver{
+		MockState: state.Stopped,
+	}
+	host := &Host{
+		Driver:      driver,
+		HostOptions: &Options{},
+	}
+
+	opts := drivers.ResizeOptions{CPU: 2, Memory: 4096, DiskSize: 40000}
+	if err := host.Resize(opts); err != nil {
+		t.Fatalf("Expected no error but got one: %s", err)
+	}
+
+	if driver.MockResize != opts {
+		t.Fatalf("Expected driver to be resized to %v but got %v", opts, driver.MockResize)
+	}
+
+	if host.HostOptions.Memory != 4096 || host.HostOptions.Disk != 40000 {
+		t.Fatalf("Expected host options to be updated but got %+v", host.HostOptions)
+	}
+}
+
+func TestPause(t *testing.T) {
+	driver := &fakedriver.Driver{
+		MockState: state.Running,
+	}
+	host := &Host{
+		Driver: driver,
+	}
+
+	if err := host.Pause(); err != nil {
+		t.Fatalf("Expected no error but got one: %s", err)
+	}
+
+	if driver.MockState != state.Paused {
+		t.Fatalf("Expected machine to be paused but it is %s", driver.MockState)
+	}
+}
+
+func TestPauseStopped(t *testing.T) {
+	host := &Host{
+		Name: "dev",
+		Driver: &fakedriver.Driver{
+			MockState: state.Stopped,
+		},
+	}
+
+	err := host.Pause()
+
+	expected := `Machine "dev" can't be paused while it is stopped.`
+	if err == nil || err.Error() != expected {
+		t.Fatalf("Expected error %q but got %v", expected, err)
+	}
+}
+
+func TestResume(t *testing.T) {
+	for _, s := range []state.State{state.Paused, state.Saved} {
+		driver := &fakedriver.Driver{
+			MockState: s,
+		}
+		host := &Host{
+			Driver: driver,
+		}
+
+		if err := host.Resume(); err != nil {
+			t.Fatalf("Expected no error but got one: %s", err)
+		}
+
+		if driver.MockState != state.Running {
+			t.Fatalf("Expected machine to be running but it is %s", driver.MockState)
+		}
+	}
+}
+
+func TestSuspend(t *testing.T) {
+	driver := &fakedriver.Driver{
+		MockState: state.Paused,
+	}
+	host := &Host{
+		Driver: driver,
+	}
+
+	if err := host.Suspend(); err != nil {
+		t.Fatalf("Expected no error but got one: %s", err)
+	}
+
+	if driver.MockState != state.Saved {
+		t.Fatalf("Expected machine to be saved but it is %s", driver.MockState)
+	}
+}
@@ -3,6 +3,7 @@ package libmachine
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"io"
 
@@ -59,11 +60,18 @@ func (api *Client) NewHost(driverName string, rawDriver []byte) (*host.Host, err
 		return nil, err
 	}
 
+	// VirtualBox doesn't support machines being created concurrently, as
+	// those of a batch are
+	var d drivers.Driver = driver
+	if driverName == "virtualbox" {
+		d = drivers.NewSerialDriver(driver)
+	}
+
 	return &host.Host{
 		ConfigVersion: version.ConfigVersion,
-		Name:          driver.GetMachineName(),
-		Driver:        driver,
-		DriverName:    driver.DriverName(),
+		Name:          d.GetMachineName(),
+		Driver:        d,
+		DriverName:    d.DriverName(),
 		HostOptions: &host.Options{
 			AuthOptions: &auth.Options{
 				CertDir:          api.certsDir,
@@ -128,6 +136,8 @@ func (api *Client) Create(h *host.Host) error {
 		}
 	}
 
+	h.Created = time.Now()
+
 	if err := api.Save(h); err != nil {
 		return fmt.Errorf("Error saving host to store before attempting creation: %s", err)
 	}
@@ -184,5 +194,9 @@ func (api *Client) performCreate(h *host.Host) error {
 }
 
 func (api *Client) Close() error {
+	if err := drivers.CloseSSHClients(); err != nil {
+		log.Debugf("Error closing SSH connections: %s", err)
+	}
+
 	return api.clientDriverFactory.Close()
 }
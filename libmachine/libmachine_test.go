@@ -0,0 +1,143 @@
+package libmachine
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/classmarkets/docker-machine/drivers/fakedriver"
+	"github.com/classmarkets/docker-machine/libmachine/auth"
+	"github.com/classmarkets/docker-machine/libmachine/cert"
+	"github.com/classmarkets/docker-machine/libmachine/engine"
+	"github.com/classmarkets/docker-machine/libmachine/host"
+	"github.com/classmarkets/docker-machine/libmachine/provision"
+	"github.com/classmarkets/docker-machine/libmachine/ssh"
+	"github.com/classmarkets/docker-machine/libmachine/ssh/sshtest"
+	"github.com/classmarkets/docker-machine/libmachine/state"
+	"github.com/classmarkets/docker-machine/libmachine/swarm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cryptossh "golang.org/x/crypto/ssh"
+)
+
+// sshDriver is reached over SSH at the address of a test server.
+type sshDriver struct {
+	*fakedriver.Driver
+	URL     string
+	SSHHost string
+	SSHPort int
+	KeyPath string
+}
+
+func (d *sshDriver) DriverName() string              { return "ssh-test" }
+func (d *sshDriver) GetURL() (string, error)         { return d.URL, nil }
+func (d *sshDriver) GetSSHHostname() (string, error) { return d.SSHHost, nil }
+func (d *sshDriver) GetSSHPort() (int, error)        { return d.SSHPort, nil }
+func (d *sshDriver) GetSSHKeyPath() string           { return d.KeyPath }
+func (d *sshDriver) GetSSHUsername() string          { return "docker" }
+
+// listenDaemon stands in for a daemon provisioned with the certificates of
+// the client, it completes the TLS handshakes.
+func listenDaemon(t *testing.T, api *Client) net.Listener {
+	serverCertPath := filepath.Join(api.certsDir, "server.pem")
+	serverKeyPath := filepath.Join(api.certsDir, "server-key.pem")
+
+	require.NoError(t, cert.GenerateCert(&cert.Options{
+		Hosts:     []string{"localhost"},
+		CertFile:  serverCertPath,
+		KeyFile:   serverKeyPath,
+		CAFile:    filepath.Join(api.certsDir, "ca.pem"),
+		CAKeyFile: filepath.Join(api.certsDir, "ca-key.pem"),
+		Org:       "test",
+		Bits:      2048,
+	}))
+
+	serverCert, err := tls.LoadX509KeyPair(serverCertPath, serverKeyPath)
+	require.NoError(t, err)
+	caCert, err := ioutil.ReadFile(filepath.Join(api.certsDir, "ca.pem"))
+	require.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(caCert)
+
+	daemon, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	})
+	require.NoError(t, err)
+
+	go func() {
+		for {
+			conn, err := daemon.Accept()
+			if err != nil {
+				return
+			}
+			conn.(*tls.Conn).Handshake()
+			conn.Close()
+		}
+	}()
+
+	return daemon
+}
+
+func TestCreateWithSSHTransport(t *testing.T) {
+	defer provision.SetDetector(&provision.StandardDetector{})
+	provision.SetDetector(&provision.FakeDetector{Provisioner: &provision.FakeProvisioner{}})
+
+	tmpDir, err := ioutil.TempDir("", "machine-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	certsDir := filepath.Join(tmpDir, "certs")
+	api := NewClient(tmpDir, certsDir)
+
+	authOptions := &auth.Options{
+		CertDir:          certsDir,
+		CaCertPath:       filepath.Join(certsDir, "ca.pem"),
+		CaPrivateKeyPath: filepath.Join(certsDir, "ca-key.pem"),
+		ClientCertPath:   filepath.Join(certsDir, "cert.pem"),
+		ClientKeyPath:    filepath.Join(certsDir, "key.pem"),
+	}
+	require.NoError(t, cert.BootstrapCertificates(authOptions))
+
+	// The daemon only listens on the loopback interface of the machine,
+	// nothing listens at the address of the machine
+	daemon := listenDaemon(t, api)
+	defer daemon.Close()
+	_, daemonPort, _ := net.SplitHostPort(daemon.Addr().String())
+
+	keyPath := filepath.Join(tmpDir, "id_rsa")
+	require.NoError(t, ssh.GenerateSSHKey(keyPath))
+	publicKey, err := ioutil.ReadFile(keyPath + ".pub")
+	require.NoError(t, err)
+	authorizedKey, _, _, _, err := cryptossh.ParseAuthorizedKey(publicKey)
+	require.NoError(t, err)
+
+	sshServer, err := sshtest.NewServer("", authorizedKey)
+	require.NoError(t, err)
+	defer sshServer.Close()
+	sshHost, sshPort := sshServer.HostPort()
+
+	h := &host.Host{
+		Name:       "dev",
+		DriverName: "ssh-test",
+		Driver: &sshDriver{
+			Driver:  &fakedriver.Driver{MockState: state.Running, MockIP: "192.0.2.1", MockName: "dev"},
+			URL:     "tcp://192.0.2.1:" + daemonPort,
+			SSHHost: sshHost,
+			SSHPort: sshPort,
+			KeyPath: keyPath,
+		},
+		HostOptions: &host.Options{
+			AuthOptions:   authOptions,
+			EngineOptions: &engine.Options{Transport: engine.TransportSSH},
+			SwarmOptions:  &swarm.Options{},
+		},
+	}
+
+	assert.NoError(t, api.Create(h))
+}
@@ -2,6 +2,7 @@ package mcndockerclient
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/classmarkets/docker-machine/libmachine/cert"
 	"github.com/samalba/dockerclient"
@@ -19,7 +20,23 @@ func DockerClient(dockerHost DockerHost) (*dockerclient.DockerClient, error) {
 		return nil, fmt.Errorf("Unable to read TLS config: %s", err)
 	}
 
-	return dockerclient.NewDockerClient(url, tlsConfig)
+	dialer, tunneled := dockerHost.(Dialer)
+	if tunneled {
+		// The server certificate is valid for localhost, which the daemon
+		// is reached as through the tunnel
+		tlsConfig.ServerName = "localhost"
+	}
+
+	client, err := dockerclient.NewDockerClient(url, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if tunneled {
+		client.HTTPClient.Transport.(*http.Transport).Dial = dialer.DialDocker
+	}
+
+	return client, nil
 }
 
 // CreateContainer creates a docker container.
@@ -2,6 +2,7 @@ package mcndockerclient
 
 import (
 	"fmt"
+	"net"
 
 	"github.com/classmarkets/docker-machine/libmachine/auth"
 )
@@ -21,6 +22,14 @@ type DockerHost interface {
 	AuthOptionser
 }
 
+// Dialer is implemented by Docker hosts whose daemon isn't reached at its
+// URL but through a tunnel, e.g. over SSH.
+type Dialer interface {
+	// DialDocker connects to the daemon at addr, the server certificate is
+	// checked for localhost
+	DialDocker(network, addr string) (net.Conn, error)
+}
+
 type RemoteDocker struct {
 	HostURL    string
 	AuthOption *auth.Options
@@ -39,3 +48,15 @@ func (rd *RemoteDocker) URL() (string, error) {
 func (rd *RemoteDocker) AuthOptions() *auth.Options {
 	return rd.AuthOption
 }
+
+// TunneledDocker is a Docker host whose daemon only listens on the loopback
+// interface of the machine, it's reached through Tunnel.
+type TunneledDocker struct {
+	RemoteDocker
+	Tunnel func(network, addr string) (net.Conn, error)
+}
+
+// DialDocker connects to the daemon through the tunnel
+func (td *TunneledDocker) DialDocker(network, addr string) (net.Conn, error) {
+	return td.Tunnel(network, addr)
+}
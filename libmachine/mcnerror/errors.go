@@ -44,3 +44,13 @@ type ErrHostAlreadyInState struct {
 func (e ErrHostAlreadyInState) Error() string {
 	return fmt.Sprintf("Machine %q is already %s.", e.Name, strings.ToLower(e.State.String()))
 }
+
+type ErrInvalidHostState struct {
+	Name         string
+	State        state.State
+	DesiredState state.State
+}
+
+func (e ErrInvalidHostState) Error() string {
+	return fmt.Sprintf("Machine %q can't be %s while it is %s.", e.Name, strings.ToLower(e.DesiredState.String()), strings.ToLower(e.State.String()))
+}
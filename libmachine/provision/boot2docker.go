@@ -149,7 +149,7 @@ EXTRA_ARGS='
 {{ end }}
 '
 CACERT={{.AuthOptions.CaCertRemotePath}}
-DOCKER_HOST='-H tcp://0.0.0.0:{{.DockerPort}}'
+DOCKER_HOST='-H tcp://{{.EngineOptions.BindAddress}}:{{.DockerPort}}'
 DOCKER_STORAGE={{.EngineOptions.StorageDriver}}
 DOCKER_TLS=auto
 SERVERKEY={{.AuthOptions.ServerKeyRemotePath}}
@@ -221,7 +221,8 @@ func (provisioner *Boot2DockerProvisioner) Provision(swarmOptions swarm.Options,
 	)
 
 	defer func() {
-		if err == nil {
+		// With the ssh transport the daemon doesn't listen on the network.
+		if err == nil && engineOptions.Transport != engine.TransportSSH {
 			provisioner.AttemptIPContact(engine.DefaultPort)
 		}
 	}()
@@ -72,6 +72,10 @@ func (provisioner *COSProvisioner) Provision(swarmOptions swarm.Options, authOpt
 		return err
 	}
 
+	if engineOptions.Transport == engine.TransportSSH {
+		return nil
+	}
+
 	cmd := fmt.Sprintf("sudo iptables -w -A INPUT -p tcp --dport %d -j ACCEPT", // TODO: source IP
 		engine.DefaultPort,
 	)
@@ -98,7 +98,7 @@ func (provisioner *GenericProvisioner) GenerateDockerOptions(dockerPort int) (*D
 
 	engineConfigTmpl := `
 DOCKER_OPTS='
--H tcp://0.0.0.0:{{.DockerPort}}
+-H tcp://{{.EngineOptions.BindAddress}}:{{.DockerPort}}
 -H unix:///var/run/docker.sock
 {{ if ne .EngineOptions.StorageDriver "" }}
 --storage-driver {{.EngineOptions.StorageDriver}}
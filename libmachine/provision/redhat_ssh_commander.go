@@ -13,7 +13,7 @@ type RedHatSSHCommander struct {
 }
 
 func (sshCmder RedHatSSHCommander) SSHCommand(args string) (string, error) {
-	client, err := drivers.GetSSHClientFromDriver(sshCmder.Driver)
+	client, err := drivers.GetSharedSSHClientFromDriver(sshCmder.Driver)
 	if err != nil {
 		return "", err
 	}
@@ -27,8 +27,10 @@ func (sshCmder RedHatSSHCommander) SSHCommand(args string) (string, error) {
 	var output string
 	switch c := client.(type) {
 	case *ssh.ExternalClient:
-		c.BaseArgs = append(c.BaseArgs, "-tt")
-		output, err = c.Output(args)
+		// The client is shared, so "-tt" is only added to a copy
+		ttyClient := *c
+		ttyClient.BaseArgs = append(append([]string{}, c.BaseArgs...), "-tt")
+		output, err = ttyClient.Output(args)
 	case *ssh.NativeClient:
 		output, err = c.OutputWithPty(args)
 	}
@@ -159,7 +159,7 @@ func (provisioner *SUSEProvisioner) Provision(swarmOptions swarm.Options, authOp
 	}
 
 	// Is yast2 firewall installed?
-	if _, installed := provisioner.SSHCommand("rpm -q yast2-firewall"); installed == nil {
+	if _, installed := provisioner.SSHCommand("rpm -q yast2-firewall"); installed == nil && engineOptions.Transport != engine.TransportSSH {
 		// Open the firewall port required by docker
 		if _, err := provisioner.SSHCommand("sudo -E /sbin/yast2 firewall services add ipprotocol=tcp tcpport=2376 zone=EXT"); err != nil {
 			return err
@@ -0,0 +1,24 @@
+package shell
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// shellNames maps the executables of the shells to the names env knows
+// them by.
+var shellNames = map[string]string{
+	"nu":   "nushell",
+	"pwsh": "powershell",
+}
+
+// nameFromPath returns the name of the shell at path.
+func nameFromPath(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), ".exe")
+
+	if shellName, ok := shellNames[name]; ok {
+		return shellName
+	}
+
+	return name
+}
@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 )
 
 var (
@@ -22,5 +21,5 @@ func Detect() (string, error) {
 		return "", ErrUnknownShell
 	}
 
-	return filepath.Base(shell), nil
+	return nameFromPath(shell), nil
 }
@@ -26,3 +26,23 @@ func TestDetectFish(t *testing.T) {
 	assert.Equal(t, "fish", shell)
 	assert.NoError(t, err)
 }
+
+func TestDetectNushell(t *testing.T) {
+	defer func(shell string) { os.Setenv("SHELL", shell) }(os.Getenv("SHELL"))
+	os.Setenv("SHELL", "/usr/bin/nu")
+
+	shell, err := Detect()
+
+	assert.Equal(t, "nushell", shell)
+	assert.NoError(t, err)
+}
+
+func TestDetectXonsh(t *testing.T) {
+	defer func(shell string) { os.Setenv("SHELL", shell) }(os.Getenv("SHELL"))
+	os.Setenv("SHELL", "/usr/local/bin/xonsh")
+
+	shell, err := Detect()
+
+	assert.Equal(t, "xonsh", shell)
+	assert.NoError(t, err)
+}
@@ -3,7 +3,6 @@ package shell
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 	"syscall"
 	"unsafe"
@@ -80,5 +79,5 @@ func Detect() (string, error) {
 		return "fish", nil
 	}
 
-	return filepath.Base(shell), nil
+	return nameFromPath(shell), nil
 }
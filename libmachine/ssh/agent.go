@@ -0,0 +1,110 @@
+package ssh
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/classmarkets/docker-machine/libmachine/log"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+var (
+	ErrNoAgent = errors.New("No ssh-agent to forward, SSH_AUTH_SOCK is not set")
+
+	agentLock    sync.Mutex
+	agentClients = map[string]agent.Agent{}
+)
+
+// AgentForwarder is implemented by clients which can forward the local
+// ssh-agent to the machine.
+type AgentForwarder interface {
+	// ForwardAgent makes the following shells forward the agent
+	ForwardAgent() error
+}
+
+// publicKeysAuthMethod returns an auth method signing with the keys, then
+// with those of the local ssh-agent which aren't among them, or nil if there
+// is none. They have to be offered by a single method, since the client
+// only tries the first publickey one.
+func publicKeysAuthMethod(signers []ssh.Signer) ssh.AuthMethod {
+	sshAgent := localAgent()
+	if sshAgent == nil {
+		if len(signers) == 0 {
+			return nil
+		}
+		return ssh.PublicKeys(signers...)
+	}
+
+	return ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+		agentSigners, err := sshAgent.Signers()
+		if err != nil {
+			log.Debugf("Unable to get the keys of ssh-agent: %s", err)
+			return signers, nil
+		}
+
+		all := append([]ssh.Signer{}, signers...)
+		for _, agentSigner := range agentSigners {
+			if !hasSigner(all, agentSigner) {
+				all = append(all, agentSigner)
+			}
+		}
+		return all, nil
+	})
+}
+
+func hasSigner(signers []ssh.Signer, signer ssh.Signer) bool {
+	key := signer.PublicKey().Marshal()
+	for _, s := range signers {
+		if bytes.Equal(s.PublicKey().Marshal(), key) {
+			return true
+		}
+	}
+	return false
+}
+
+// localAgent connects to the agent listening on SSH_AUTH_SOCK. The
+// connection is shared by all the clients, since the agent has to be
+// reachable for as long as they sign.
+func localAgent() agent.Agent {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return nil
+	}
+
+	agentLock.Lock()
+	defer agentLock.Unlock()
+
+	if sshAgent, ok := agentClients[socket]; ok {
+		return sshAgent
+	}
+
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		log.Debugf("Unable to connect to ssh-agent at %s: %s", socket, err)
+		return nil
+	}
+
+	sshAgent := agent.NewClient(conn)
+	agentClients[socket] = sshAgent
+
+	return sshAgent
+}
+
+// forwardAgent serves the agent forwarding requests of the session with the
+// local ssh-agent.
+func forwardAgent(client *ssh.Client, session *ssh.Session) error {
+	socket := os.Getenv("SSH_AUTH_SOCK")
+	if socket == "" {
+		return ErrNoAgent
+	}
+
+	if err := agent.ForwardToRemote(client, socket); err != nil {
+		return err
+	}
+
+	return agent.RequestAgentForwarding(session)
+}
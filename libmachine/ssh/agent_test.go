@@ -0,0 +1,119 @@
+package ssh
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/classmarkets/docker-machine/libmachine/ssh/sshtest"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func serveTestAgent(t *testing.T, keys ...interface{}) (string, func()) {
+	tmpDir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	socket := filepath.Join(tmpDir, "agent.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyring := agent.NewKeyring()
+	for _, key := range keys {
+		if err := keyring.Add(agent.AddedKey{PrivateKey: key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go agent.ServeAgent(keyring, conn)
+		}
+	}()
+
+	return socket, func() {
+		listener.Close()
+		os.RemoveAll(tmpDir)
+	}
+}
+
+func TestNewNativeConfigWithAgent(t *testing.T) {
+	defer os.Setenv("SSH_AUTH_SOCK", os.Getenv("SSH_AUTH_SOCK"))
+
+	os.Setenv("SSH_AUTH_SOCK", "")
+	config, err := NewNativeConfig("docker", &Auth{Passwords: []string{"tcuser"}})
+	assert.NoError(t, err)
+	assert.Len(t, config.Auth, 1)
+
+	socket, stop := serveTestAgent(t)
+	defer stop()
+
+	os.Setenv("SSH_AUTH_SOCK", socket)
+	config, err = NewNativeConfig("docker", &Auth{Passwords: []string{"tcuser"}})
+	assert.NoError(t, err)
+	assert.Len(t, config.Auth, 2)
+}
+
+func TestNewNativeConfigWithAgentOnlyKey(t *testing.T) {
+	defer os.Setenv("SSH_AUTH_SOCK", os.Getenv("SSH_AUTH_SOCK"))
+
+	tmpDir, err := ioutil.TempDir("", "machine-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	// The key file is refused by the server, only the agent's is accepted
+	keyPath := filepath.Join(tmpDir, "id_rsa")
+	assert.NoError(t, GenerateSSHKey(keyPath))
+
+	agentKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	agentSigner, err := ssh.NewSignerFromKey(agentKey)
+	assert.NoError(t, err)
+
+	server, err := sshtest.NewServer("", agentSigner.PublicKey())
+	assert.NoError(t, err)
+	defer server.Close()
+
+	socket, stop := serveTestAgent(t, agentKey)
+	defer stop()
+
+	os.Setenv("SSH_AUTH_SOCK", socket)
+	config, err := NewNativeConfig("docker", &Auth{Keys: []string{keyPath}})
+	assert.NoError(t, err)
+	assert.Len(t, config.Auth, 1)
+
+	client, err := ssh.Dial("tcp", server.Addr, &config)
+	assert.NoError(t, err)
+	if client != nil {
+		client.Close()
+	}
+}
+
+func TestForwardAgent(t *testing.T) {
+	defer os.Setenv("SSH_AUTH_SOCK", os.Getenv("SSH_AUTH_SOCK"))
+
+	os.Setenv("SSH_AUTH_SOCK", "")
+	external := &ExternalClient{BaseArgs: []string{"docker@localhost"}}
+	assert.Equal(t, ErrNoAgent, external.ForwardAgent())
+	assert.Equal(t, ErrNoAgent, (&NativeClient{}).ForwardAgent())
+
+	os.Setenv("SSH_AUTH_SOCK", "/tmp/agent.sock")
+	assert.NoError(t, external.ForwardAgent())
+	assert.Equal(t, []string{"docker@localhost", "-A"}, external.BaseArgs)
+
+	native := &NativeClient{}
+	assert.NoError(t, native.ForwardAgent())
+	assert.True(t, native.forwardAgent)
+}
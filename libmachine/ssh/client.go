@@ -10,6 +10,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/docker/docker/pkg/term"
 	"github.com/classmarkets/docker-machine/libmachine/log"
@@ -39,6 +40,9 @@ type ExternalClient struct {
 	BaseArgs   []string
 	BinaryPath string
 	cmd        *exec.Cmd
+
+	controlPath string
+	destination string
 }
 
 type NativeClient struct {
@@ -47,17 +51,55 @@ type NativeClient struct {
 	Port        int
 	openSession *ssh.Session
 	openClient  *ssh.Client
+
+	// jumpAuth is set if the machine is reached through a jump host
+	jumpAuth *Auth
+
+	forwardAgent bool
+
+	// shared is set if the commands share one connection, which is kept
+	// open in sharedConn until the client is closed
+	shared     bool
+	sharedConn *ssh.Client
+	sharedLock sync.Mutex
 }
 
 type Auth struct {
 	Passwords []string
 	Keys      []string
+
+	// KnownHostsFile pins the host key of the machine, host keys aren't
+	// checked if it's empty.
+	KnownHostsFile string
+
+	// HostKeyAlias is the name the host key is pinned under, instead of
+	// the host and port which may change.
+	HostKeyAlias string
+
+	// ProxyJump is the jump host the machine is reached through, the
+	// machine is connected to directly if its address is empty.
+	ProxyJump ProxyJump
+
+	// ControlPath makes the commands run by a client share one connection,
+	// which stays open until the client is closed. The external client
+	// multiplexes through a ControlMaster socket at this path, the native
+	// client keeps its connection in memory. Every command opens its own
+	// connection if it's empty.
+	ControlPath string
 }
 
 type ClientType string
 
 const (
 	maxDialAttempts = 10
+
+	// controlPersist is how long an idle ControlMaster outlives the last
+	// command, in case the client isn't closed
+	controlPersist = "60s"
+
+	// maxControlPathLength leaves room below the limit of unix socket paths
+	// for the suffix ssh appends while creating the socket
+	maxControlPathLength = 80
 )
 
 const (
@@ -70,13 +112,9 @@ var (
 		"-F", "/dev/null",
 		"-o", "ConnectionAttempts=3", // retry 3 times if SSH connection fails
 		"-o", "ConnectTimeout=10", // timeout after 10 seconds
-		"-o", "ControlMaster=no", // disable ssh multiplexing
-		"-o", "ControlPath=none",
 		"-o", "LogLevel=quiet", // suppress "Warning: Permanently added '[localhost]:2022' (ECDSA) to the list of known hosts."
 		"-o", "PasswordAuthentication=no",
 		"-o", "ServerAliveInterval=60", // prevents connection to be dropped if command takes too long
-		"-o", "StrictHostKeyChecking=no",
-		"-o", "UserKnownHostsFile=/dev/null",
 	}
 	defaultClientType = External
 )
@@ -93,6 +131,12 @@ func SetDefaultClient(clientType ClientType) {
 	}
 }
 
+// GetDefaultClient returns the type of client created by NewClient when the
+// ssh binary is available.
+func GetDefaultClient() ClientType {
+	return defaultClientType
+}
+
 func NewClient(user string, host string, port int, auth *Auth) (Client, error) {
 	sshBinaryPath, err := exec.LookPath("ssh")
 	if err != nil {
@@ -121,16 +165,27 @@ func NewNativeClient(user, host string, port int, auth *Auth) (Client, error) {
 		return nil, fmt.Errorf("Error getting config for native Go SSH: %s", err)
 	}
 
-	return &NativeClient{
+	client := &NativeClient{
 		Config:   config,
 		Hostname: host,
 		Port:     port,
-	}, nil
+		shared:   auth.ControlPath != "",
+	}
+
+	if auth.ProxyJump.Address != "" {
+		if _, _, _, err := ParseProxyJump(auth.ProxyJump.Address); err != nil {
+			return nil, err
+		}
+		client.jumpAuth = auth
+	}
+
+	return client, nil
 }
 
 func NewNativeConfig(user string, auth *Auth) (ssh.ClientConfig, error) {
 	var (
 		authMethods []ssh.AuthMethod
+		signers     []ssh.Signer
 	)
 
 	for _, k := range auth.Keys {
@@ -144,22 +199,44 @@ func NewNativeConfig(user string, auth *Auth) (ssh.ClientConfig, error) {
 			return ssh.ClientConfig{}, err
 		}
 
-		authMethods = append(authMethods, ssh.PublicKeys(privateKey))
+		signers = append(signers, privateKey)
+	}
+
+	// Along with the keys which are only held by ssh-agent, e.g. on a
+	// hardware token
+	if publicKeys := publicKeysAuthMethod(signers); publicKeys != nil {
+		authMethods = append(authMethods, publicKeys)
 	}
 
 	for _, p := range auth.Passwords {
 		authMethods = append(authMethods, ssh.Password(p))
 	}
 
-	return ssh.ClientConfig{
+	config := ssh.ClientConfig{
 		User:            user,
 		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-	}, nil
+		HostKeyCallback: hostKeyCallback(auth.KnownHostsFile, auth.HostKeyAlias),
+	}
+
+	if auth.KnownHostsFile != "" && auth.HostKeyAlias != "" {
+		config.HostKeyAlgorithms = PinnedKeyTypes(auth.KnownHostsFile, auth.HostKeyAlias)
+	}
+
+	return config, nil
+}
+
+func (client *NativeClient) dial() (*ssh.Client, error) {
+	addr := net.JoinHostPort(client.Hostname, strconv.Itoa(client.Port))
+
+	if client.jumpAuth != nil {
+		return dialThroughJumpHost(client.jumpAuth, addr, &client.Config)
+	}
+
+	return ssh.Dial("tcp", addr, &client.Config)
 }
 
 func (client *NativeClient) dialSuccess() bool {
-	conn, err := ssh.Dial("tcp", net.JoinHostPort(client.Hostname, strconv.Itoa(client.Port)), &client.Config)
+	conn, err := client.dial()
 	if err != nil {
 		log.Debugf("Error dialing TCP: %s", err)
 		return false
@@ -169,11 +246,15 @@ func (client *NativeClient) dialSuccess() bool {
 }
 
 func (client *NativeClient) session(command string) (*ssh.Client, *ssh.Session, error) {
+	if client.shared {
+		return client.sharedSession()
+	}
+
 	if err := mcnutils.WaitFor(client.dialSuccess); err != nil {
 		return nil, nil, fmt.Errorf("Error attempting SSH client dial: %s", err)
 	}
 
-	conn, err := ssh.Dial("tcp", net.JoinHostPort(client.Hostname, strconv.Itoa(client.Port)), &client.Config)
+	conn, err := client.dial()
 	if err != nil {
 		return nil, nil, fmt.Errorf("Mysterious error dialing TCP for SSH (we already succeeded at least once) : %s", err)
 	}
@@ -182,12 +263,75 @@ func (client *NativeClient) session(command string) (*ssh.Client, *ssh.Session,
 	return conn, session, err
 }
 
+// sharedSession opens a session on the connection shared by the commands,
+// reconnecting if the connection was lost, e.g. because the machine
+// restarted.
+func (client *NativeClient) sharedSession() (*ssh.Client, *ssh.Session, error) {
+	client.sharedLock.Lock()
+	defer client.sharedLock.Unlock()
+
+	if client.sharedConn != nil {
+		session, err := client.sharedConn.NewSession()
+		if err == nil {
+			return client.sharedConn, session, nil
+		}
+
+		log.Debugf("Lost shared SSH connection, reconnecting: %s", err)
+		closeConn(client.sharedConn)
+		client.sharedConn = nil
+	}
+
+	var conn *ssh.Client
+	if err := mcnutils.WaitFor(func() bool {
+		var err error
+		if conn, err = client.dial(); err != nil {
+			log.Debugf("Error dialing TCP: %s", err)
+			return false
+		}
+		return true
+	}); err != nil {
+		return nil, nil, fmt.Errorf("Error attempting SSH client dial: %s", err)
+	}
+
+	session, err := conn.NewSession()
+	if err != nil {
+		closeConn(conn)
+		return nil, nil, err
+	}
+
+	client.sharedConn = conn
+
+	return conn, session, nil
+}
+
+// release closes the connection of a command, unless it's shared.
+func (client *NativeClient) release(conn *ssh.Client) {
+	if !client.shared {
+		closeConn(conn)
+	}
+}
+
+// Close closes the connection shared by the commands.
+func (client *NativeClient) Close() error {
+	client.sharedLock.Lock()
+	defer client.sharedLock.Unlock()
+
+	if client.sharedConn == nil {
+		return nil
+	}
+
+	err := client.sharedConn.Close()
+	client.sharedConn = nil
+
+	return err
+}
+
 func (client *NativeClient) Output(command string) (string, error) {
 	conn, session, err := client.session(command)
 	if err != nil {
 		return "", nil
 	}
-	defer closeConn(conn)
+	defer client.release(conn)
 	defer session.Close()
 
 	output, err := session.CombinedOutput(command)
@@ -200,7 +344,7 @@ func (client *NativeClient) OutputWithPty(command string) (string, error) {
 	if err != nil {
 		return "", nil
 	}
-	defer closeConn(conn)
+	defer client.release(conn)
 	defer session.Close()
 
 	fd := int(os.Stdout.Fd())
@@ -258,9 +402,11 @@ func (client *NativeClient) Wait() error {
 
 	_ = client.openSession.Close()
 
-	err = client.openClient.Close()
-	if err != nil {
-		return err
+	if !client.shared {
+		err = client.openClient.Close()
+		if err != nil {
+			return err
+		}
 	}
 
 	client.openSession = nil
@@ -268,11 +414,21 @@ func (client *NativeClient) Wait() error {
 	return nil
 }
 
+// ForwardAgent makes the following shells forward the local ssh-agent
+func (client *NativeClient) ForwardAgent() error {
+	if os.Getenv("SSH_AUTH_SOCK") == "" {
+		return ErrNoAgent
+	}
+
+	client.forwardAgent = true
+	return nil
+}
+
 func (client *NativeClient) Shell(args ...string) error {
 	var (
 		termWidth, termHeight int
 	)
-	conn, err := ssh.Dial("tcp", net.JoinHostPort(client.Hostname, strconv.Itoa(client.Port)), &client.Config)
+	conn, err := client.dial()
 	if err != nil {
 		return err
 	}
@@ -285,6 +441,12 @@ func (client *NativeClient) Shell(args ...string) error {
 
 	defer session.Close()
 
+	if client.forwardAgent {
+		if err := forwardAgent(conn, session); err != nil {
+			return fmt.Errorf("Error forwarding ssh-agent: %s", err)
+		}
+	}
+
 	session.Stdout = os.Stdout
 	session.Stderr = os.Stderr
 	session.Stdin = os.Stdin
@@ -334,10 +496,20 @@ func (client *NativeClient) Shell(args ...string) error {
 
 func NewExternalClient(sshBinaryPath, user, host string, port int, auth *Auth) (*ExternalClient, error) {
 	client := &ExternalClient{
-		BinaryPath: sshBinaryPath,
+		BinaryPath:  sshBinaryPath,
+		controlPath: controlPath(auth.ControlPath),
+		destination: fmt.Sprintf("%s@%s", user, host),
 	}
 
-	args := append(baseSSHArgs, fmt.Sprintf("%s@%s", user, host))
+	jumpArgs, err := ProxyJumpArgs(sshBinaryPath, auth.ProxyJump)
+	if err != nil {
+		return nil, err
+	}
+
+	args := append(baseSSHArgs, multiplexArgs(client.controlPath)...)
+	args = append(args, HostKeyArgs(auth.KnownHostsFile, auth.HostKeyAlias)...)
+	args = append(args, jumpArgs...)
+	args = append(args, client.destination)
 
 	// If no identities are explicitly provided, also look at the identities
 	// offered by ssh-agent
@@ -378,6 +550,73 @@ func NewExternalClient(sshBinaryPath, user, host string, port int, auth *Auth) (
 	return client, nil
 }
 
+// ForwardAgent makes the following shells forward the local ssh-agent
+func (client *ExternalClient) ForwardAgent() error {
+	if os.Getenv("SSH_AUTH_SOCK") == "" {
+		return ErrNoAgent
+	}
+
+	client.BaseArgs = append(client.BaseArgs, "-A")
+	return nil
+}
+
+// controlPath returns the ControlMaster socket path, or an empty string if
+// the connections can't be multiplexed.
+func controlPath(path string) string {
+	if path == "" {
+		return ""
+	}
+
+	if runtime.GOOS == "windows" {
+		log.Debug("SSH connections can't be multiplexed on Windows")
+		return ""
+	}
+
+	if len(path) > maxControlPathLength {
+		log.Debugf("Not multiplexing SSH connections, the socket path %s is too long", path)
+		return ""
+	}
+
+	return path
+}
+
+// multiplexArgs returns the options making ssh share a connection through
+// the ControlMaster socket, or disabling multiplexing if there's no socket.
+func multiplexArgs(controlPath string) []string {
+	if controlPath == "" {
+		return []string{
+			"-o", "ControlMaster=no", // disable ssh multiplexing
+			"-o", "ControlPath=none",
+		}
+	}
+
+	return []string{
+		"-o", "ControlMaster=auto",
+		"-o", fmt.Sprintf("ControlPath=%s", controlPath),
+		"-o", fmt.Sprintf("ControlPersist=%s", controlPersist),
+	}
+}
+
+// Close stops the ControlMaster sharing the connection, if it's running.
+func (client *ExternalClient) Close() error {
+	if client.controlPath == "" {
+		return nil
+	}
+
+	if _, err := os.Stat(client.controlPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	cmd := getSSHCmd(client.BinaryPath, "-o", fmt.Sprintf("ControlPath=%s", client.controlPath), "-O", "exit", client.destination)
+	log.Debug(cmd)
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("Error stopping SSH ControlMaster: %s: %s", err, output)
+	}
+
+	return nil
+}
+
 func getSSHCmd(binaryPath string, args ...string) *exec.Cmd {
 	return exec.Command(binaryPath, args...)
 }
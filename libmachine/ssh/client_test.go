@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -104,3 +105,32 @@ func TestNewExternalClient(t *testing.T) {
 		}
 	}
 }
+
+func TestMultiplexArgs(t *testing.T) {
+	assert.Equal(t, []string{"-o", "ControlMaster=no", "-o", "ControlPath=none"}, multiplexArgs(""))
+	assert.Equal(t, []string{
+		"-o", "ControlMaster=auto",
+		"-o", "ControlPath=/machines/dev/ssh.sock",
+		"-o", "ControlPersist=60s",
+	}, multiplexArgs("/machines/dev/ssh.sock"))
+}
+
+func TestNewExternalClientControlPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SSH connections aren't multiplexed on Windows")
+	}
+
+	client, err := NewExternalClient("ssh", "docker", "localhost", 22, &Auth{ControlPath: "/machines/dev/ssh.sock"})
+	assert.NoError(t, err)
+	assert.Contains(t, client.BaseArgs, "ControlPath=/machines/dev/ssh.sock")
+	assert.NotContains(t, client.BaseArgs, "ControlMaster=no")
+
+	client, err = NewExternalClient("ssh", "docker", "localhost", 22, &Auth{ControlPath: "/" + strings.Repeat("a", maxControlPathLength)})
+	assert.NoError(t, err)
+	assert.Contains(t, client.BaseArgs, "ControlPath=none")
+
+	// Nothing to stop if the ControlMaster isn't running
+	client, err = NewExternalClient("ssh", "docker", "localhost", 22, &Auth{ControlPath: "/machines/dev/ssh.sock"})
+	assert.NoError(t, err)
+	assert.NoError(t, client.Close())
+}
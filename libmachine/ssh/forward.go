@@ -0,0 +1,315 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/classmarkets/docker-machine/libmachine/log"
+	"golang.org/x/crypto/ssh"
+)
+
+const defaultForwardHost = "localhost"
+
+// Endpoint is one end of a forward, a TCP address or the path of a unix
+// socket.
+type Endpoint struct {
+	Network string
+	Address string
+}
+
+func (e Endpoint) String() string {
+	return e.Address
+}
+
+// Forward forwards the connections to Listen to Dial. The connections are
+// accepted locally and dialed on the machine, like ssh -L, or accepted on the
+// machine and dialed locally if Remote is set, like ssh -R.
+type Forward struct {
+	Remote bool
+	Listen Endpoint
+	Dial   Endpoint
+}
+
+func (f Forward) String() string {
+	if f.Remote {
+		return fmt.Sprintf("%s on the machine to %s", f.Listen, f.Dial)
+	}
+
+	return fmt.Sprintf("%s to %s on the machine", f.Listen, f.Dial)
+}
+
+// ParseForward parses a forward given like ssh's -L and -R, with unix socket
+// paths in place of host and port:
+//
+//	[bind_address:]port:host:hostport
+//	[bind_address:]port:remote_socket
+//	local_socket:host:hostport
+//	local_socket:remote_socket
+//
+// port:hostport is short for port:localhost:hostport.
+func ParseForward(spec string, remote bool) (Forward, error) {
+	parts, err := splitForward(spec)
+	if err != nil {
+		return Forward{}, err
+	}
+
+	forward := Forward{Remote: remote}
+
+	switch len(parts) {
+	case 2:
+		forward.Listen, err = parseEndpoint(defaultForwardHost, parts[0])
+		if err == nil {
+			forward.Dial, err = parseEndpoint(defaultForwardHost, parts[1])
+		}
+	case 3:
+		if _, portErr := strconv.Atoi(parts[1]); portErr == nil {
+			forward.Listen, err = parseEndpoint(parts[0], parts[1])
+			if err == nil {
+				forward.Dial, err = parseEndpoint(defaultForwardHost, parts[2])
+			}
+		} else {
+			forward.Listen, err = parseEndpoint(defaultForwardHost, parts[0])
+			if err == nil {
+				forward.Dial, err = parseEndpoint(parts[1], parts[2])
+			}
+		}
+	case 4:
+		forward.Listen, err = parseEndpoint(parts[0], parts[1])
+		if err == nil {
+			forward.Dial, err = parseEndpoint(parts[2], parts[3])
+		}
+	default:
+		err = errors.New("expected [bind_address:]port:host:hostport")
+	}
+
+	if err != nil {
+		return Forward{}, fmt.Errorf("Invalid forward %q: %s", spec, err)
+	}
+
+	return forward, nil
+}
+
+// splitForward splits a forward at the colons which aren't in brackets,
+// which enclose IPv6 addresses.
+func splitForward(spec string) ([]string, error) {
+	parts := []string{}
+	start := 0
+	bracketed := false
+
+	for i, c := range spec {
+		switch c {
+		case '[':
+			bracketed = true
+		case ']':
+			bracketed = false
+		case ':':
+			if !bracketed {
+				parts = append(parts, spec[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	if bracketed {
+		return nil, fmt.Errorf("Invalid forward %q: missing ]", spec)
+	}
+
+	return append(parts, spec[start:]), nil
+}
+
+// parseEndpoint parses a port, with host the address it's on, or the path of
+// a unix socket.
+func parseEndpoint(host, portOrPath string) (Endpoint, error) {
+	if strings.Contains(portOrPath, "/") {
+		return Endpoint{
+			Network: "unix",
+			Address: portOrPath,
+		}, nil
+	}
+
+	port, err := strconv.Atoi(portOrPath)
+	if err != nil || port < 0 || port > 65535 {
+		return Endpoint{}, fmt.Errorf("invalid port %q", portOrPath)
+	}
+
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if host == "" || host == "*" {
+		host = "0.0.0.0"
+	}
+
+	return Endpoint{
+		Network: "tcp",
+		Address: net.JoinHostPort(host, strconv.Itoa(port)),
+	}, nil
+}
+
+// Tunnel forwards connections over its own connection to a machine.
+type Tunnel struct {
+	conn      *ssh.Client
+	listeners []net.Listener
+	closeOnce sync.Once
+}
+
+// OpenTunnel connects to the machine and starts forwarding.
+func (client *NativeClient) OpenTunnel(forwards []Forward) (*Tunnel, error) {
+	conn, err := client.dial()
+	if err != nil {
+		return nil, fmt.Errorf("Error dialing TCP: %s", err)
+	}
+
+	tunnel := &Tunnel{
+		conn: conn,
+	}
+
+	for _, forward := range forwards {
+		if err := tunnel.forward(forward); err != nil {
+			tunnel.Close()
+			return nil, err
+		}
+	}
+
+	return tunnel, nil
+}
+
+func (t *Tunnel) forward(forward Forward) error {
+	var (
+		listener net.Listener
+		err      error
+		dial     func(network, address string) (net.Conn, error)
+	)
+
+	if forward.Remote {
+		listener, err = t.conn.Listen(forward.Listen.Network, forward.Listen.Address)
+		dial = net.Dial
+	} else {
+		listener, err = net.Listen(forward.Listen.Network, forward.Listen.Address)
+		dial = t.conn.Dial
+	}
+	if err != nil {
+		return fmt.Errorf("Error listening on %s: %s", forward.Listen, err)
+	}
+
+	t.listeners = append(t.listeners, listener)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+
+				target, err := dial(forward.Dial.Network, forward.Dial.Address)
+				if err != nil {
+					log.Warnf("Error forwarding to %s: %s", forward.Dial, err)
+					return
+				}
+				defer target.Close()
+
+				proxy(conn, target)
+			}()
+		}
+	}()
+
+	return nil
+}
+
+// proxy copies between two connections until both directions are done. When
+// one side stops sending, the write half of the other is closed, so the peer
+// sees the end of the stream but can still answer.
+func proxy(a, b net.Conn) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		copyHalf(a, b)
+	}()
+
+	go func() {
+		defer wg.Done()
+		copyHalf(b, a)
+	}()
+
+	wg.Wait()
+}
+
+// closeWriter is implemented by the TCP, unix socket and SSH channel
+// connections.
+type closeWriter interface {
+	CloseWrite() error
+}
+
+// copyHalf copies from src to dst, then closes the write half of dst. Both
+// connections are closed if the copy fails or dst can't be half closed,
+// which stops the copy in the other direction.
+func copyHalf(dst, src net.Conn) {
+	_, err := io.Copy(dst, src)
+
+	if cw, ok := dst.(closeWriter); ok && err == nil {
+		if cw.CloseWrite() == nil {
+			return
+		}
+	}
+
+	dst.Close()
+	src.Close()
+}
+
+// Wait waits until the connection to the machine is lost or the tunnel is
+// closed.
+func (t *Tunnel) Wait() error {
+	return t.conn.Wait()
+}
+
+// Close stops forwarding and closes the connection to the machine.
+func (t *Tunnel) Close() error {
+	var err error
+
+	t.closeOnce.Do(func() {
+		for _, listener := range t.listeners {
+			listener.Close()
+		}
+
+		err = t.conn.Close()
+	})
+
+	return err
+}
+
+// DialMachine connects to addr as seen from the machine, like a single
+// forward. The connection to the machine is closed with the returned one.
+func (client *NativeClient) DialMachine(network, addr string) (net.Conn, error) {
+	conn, err := client.dial()
+	if err != nil {
+		return nil, fmt.Errorf("Error dialing TCP: %s", err)
+	}
+
+	target, err := conn.Dial(network, addr)
+	if err != nil {
+		closeConn(conn)
+		return nil, fmt.Errorf("Error dialing %s on the machine: %s", addr, err)
+	}
+
+	return &machineConn{Conn: target, client: conn}, nil
+}
+
+// machineConn is a connection dialed on the machine, which owns the
+// connection to the machine.
+type machineConn struct {
+	net.Conn
+	client *ssh.Client
+}
+
+func (c *machineConn) Close() error {
+	err := c.Conn.Close()
+	closeConn(c.client)
+	return err
+}
@@ -0,0 +1,113 @@
+package ssh
+
+import (
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseForward(t *testing.T) {
+	tcp := func(address string) Endpoint { return Endpoint{"tcp", address} }
+	unix := func(address string) Endpoint { return Endpoint{"unix", address} }
+
+	cases := []struct {
+		spec     string
+		listen   Endpoint
+		dial     Endpoint
+		hasError bool
+	}{
+		{"8080:80", tcp("localhost:8080"), tcp("localhost:80"), false},
+		{"8080:db:5432", tcp("localhost:8080"), tcp("db:5432"), false},
+		{"127.0.0.1:8080:db:5432", tcp("127.0.0.1:8080"), tcp("db:5432"), false},
+		{"*:8080:db:5432", tcp("0.0.0.0:8080"), tcp("db:5432"), false},
+		{"[::1]:8080:[fe80::1]:80", tcp("[::1]:8080"), tcp("[fe80::1]:80"), false},
+		{"2375:/var/run/docker.sock", tcp("localhost:2375"), unix("/var/run/docker.sock"), false},
+		{"127.0.0.1:2375:/var/run/docker.sock", tcp("127.0.0.1:2375"), unix("/var/run/docker.sock"), false},
+		{"/tmp/docker.sock:/var/run/docker.sock", unix("/tmp/docker.sock"), unix("/var/run/docker.sock"), false},
+		{"/tmp/db.sock:db:5432", unix("/tmp/db.sock"), tcp("db:5432"), false},
+		{"8080", Endpoint{}, Endpoint{}, true},
+		{"http:80", Endpoint{}, Endpoint{}, true},
+		{"8080:70000", Endpoint{}, Endpoint{}, true},
+		{"[::1:8080:80", Endpoint{}, Endpoint{}, true},
+		{"a:b:c:d:e", Endpoint{}, Endpoint{}, true},
+	}
+
+	for _, c := range cases {
+		forward, err := ParseForward(c.spec, true)
+		assert.Equal(t, c.hasError, err != nil, c.spec)
+		assert.Equal(t, c.listen, forward.Listen, c.spec)
+		assert.Equal(t, c.dial, forward.Dial, c.spec)
+		assert.Equal(t, !c.hasError, forward.Remote, c.spec)
+	}
+}
+
+func TestProxy(t *testing.T) {
+	a, b := net.Pipe()
+	c, d := net.Pipe()
+
+	go proxy(b, c)
+
+	go func() {
+		a.Write([]byte("ping"))
+	}()
+
+	buf := make([]byte, 4)
+	_, err := d.Read(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+
+	a.Close()
+	d.Close()
+}
+
+// tcpPair returns the two ends of a TCP connection.
+func tcpPair(t *testing.T) (*net.TCPConn, *net.TCPConn) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	client, err := net.Dial("tcp", listener.Addr().String())
+	assert.NoError(t, err)
+
+	server, err := listener.Accept()
+	assert.NoError(t, err)
+
+	return client.(*net.TCPConn), server.(*net.TCPConn)
+}
+
+func TestProxyHalfClose(t *testing.T) {
+	a, b := tcpPair(t)
+	c, d := tcpPair(t)
+	defer a.Close()
+	defer d.Close()
+
+	done := make(chan struct{})
+	go func() {
+		proxy(b, c)
+		b.Close()
+		c.Close()
+		close(done)
+	}()
+
+	// The client sends its request and closes its write half, the server
+	// reads it to the end before answering.
+	_, err := a.Write([]byte("ping"))
+	assert.NoError(t, err)
+	assert.NoError(t, a.CloseWrite())
+
+	request, err := ioutil.ReadAll(d)
+	assert.NoError(t, err)
+	assert.Equal(t, "ping", string(request))
+
+	_, err = d.Write([]byte("pong"))
+	assert.NoError(t, err)
+	assert.NoError(t, d.CloseWrite())
+
+	response, err := ioutil.ReadAll(a)
+	assert.NoError(t, err)
+	assert.Equal(t, "pong", string(response))
+
+	<-done
+}
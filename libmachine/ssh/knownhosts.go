@@ -0,0 +1,320 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/classmarkets/docker-machine/libmachine/log"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+var errHostKeyScanned = errors.New("host key scanned")
+
+// ErrHostKeyMismatch is returned when a machine presents a host key which
+// differs from the one pinned in its known_hosts file.
+type ErrHostKeyMismatch struct {
+	Alias          string
+	KnownHostsFile string
+}
+
+func (e ErrHostKeyMismatch) Error() string {
+	return fmt.Sprintf("The host key of %q does not match the key pinned in %s. Someone could be eavesdropping on the connection. If the machine was re-keyed, run \"docker-machine ssh-keyscan --reset %s\"", e.Alias, e.KnownHostsFile, e.Alias)
+}
+
+// HostKeyArgs returns the options making an external ssh binary check the
+// host key against a known_hosts file. Keys of hosts which aren't known yet
+// are added to the file, changed keys are rejected. Host key checking is
+// disabled if knownHostsFile is empty.
+func HostKeyArgs(knownHostsFile, alias string) []string {
+	return hostKeyArgs(knownHostsFile, alias, supportsAcceptNew())
+}
+
+// hostKeyArgs returns the options of HostKeyArgs. Without acceptNew, which
+// older ssh binaries reject, keys are only checked strictly once pinned,
+// and ssh pins the key of a host it doesn't know yet.
+func hostKeyArgs(knownHostsFile, alias string, acceptNew bool) []string {
+	if knownHostsFile == "" {
+		return []string{
+			"-o", "StrictHostKeyChecking=no",
+			"-o", "UserKnownHostsFile=/dev/null",
+		}
+	}
+
+	checking := "accept-new"
+	if !acceptNew {
+		checking = "yes"
+		if !hostKeyPinned(knownHostsFile, alias) {
+			checking = "no"
+		}
+	}
+
+	args := []string{
+		"-o", fmt.Sprintf("StrictHostKeyChecking=%s", checking),
+		"-o", fmt.Sprintf("UserKnownHostsFile=%s", quoteOptionValue(knownHostsFile)),
+	}
+
+	if alias != "" {
+		args = append(args, "-o", fmt.Sprintf("HostKeyAlias=%s", alias))
+	}
+
+	return args
+}
+
+// hostKeyPinned tells if a host key is pinned for the alias, or any key if
+// there's no alias.
+func hostKeyPinned(knownHostsFile, alias string) bool {
+	if alias == "" {
+		_, err := os.Stat(knownHostsFile)
+		return err == nil
+	}
+
+	return PinnedKeyTypes(knownHostsFile, alias) != nil
+}
+
+var (
+	acceptNewOnce      sync.Once
+	acceptNewSupported bool
+
+	// sshVersion returns what the ssh binary prints with -V
+	sshVersion = func() string {
+		output, err := exec.Command("ssh", "-V").CombinedOutput()
+		if err != nil {
+			log.Debugf("Error getting the version of ssh: %s", err)
+		}
+		return string(output)
+	}
+
+	opensshVersionPattern = regexp.MustCompile(`OpenSSH_(?:for_Windows_)?(\d+)\.(\d+)`)
+)
+
+// supportsAcceptNew tells if the ssh binary supports
+// StrictHostKeyChecking=accept-new, its version is only checked once.
+func supportsAcceptNew() bool {
+	acceptNewOnce.Do(func() {
+		acceptNewSupported = opensshSupportsAcceptNew(sshVersion())
+	})
+
+	return acceptNewSupported
+}
+
+// opensshSupportsAcceptNew tells from the output of ssh -V if it's at least
+// OpenSSH 7.6, which introduced StrictHostKeyChecking=accept-new.
+func opensshSupportsAcceptNew(version string) bool {
+	matches := opensshVersionPattern.FindStringSubmatch(version)
+	if matches == nil {
+		return false
+	}
+
+	major, _ := strconv.Atoi(matches[1])
+	minor, _ := strconv.Atoi(matches[2])
+
+	return major > 7 || (major == 7 && minor >= 6)
+}
+
+// quoteOptionValue quotes the value of an ssh option containing whitespace,
+// which ssh would split the value on.
+func quoteOptionValue(value string) string {
+	if strings.ContainsAny(value, " \t") {
+		return `"` + value + `"`
+	}
+
+	return value
+}
+
+// VerifyHostKey checks a host key against the keys pinned in a known_hosts
+// file. The key is pinned if the host isn't known yet (trust on first use).
+// The alias is either a name like the one given to ssh's HostKeyAlias, or
+// a host:port address.
+func VerifyHostKey(knownHostsFile, alias string, key ssh.PublicKey) error {
+	_, err := os.Stat(knownHostsFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err == nil {
+		check, err := knownhosts.New(knownHostsFile)
+		if err != nil {
+			return err
+		}
+
+		// The remote address is ignored since keys are pinned by alias
+		err = check(checkAddress(alias), &net.TCPAddr{IP: net.IPv4zero}, key)
+		if err == nil {
+			return nil
+		}
+
+		keyErr, ok := err.(*knownhosts.KeyError)
+		if !ok {
+			return err
+		}
+
+		if len(keyErr.Want) > 0 {
+			return ErrHostKeyMismatch{
+				Alias:          alias,
+				KnownHostsFile: knownHostsFile,
+			}
+		}
+	}
+
+	log.Debugf("Pinning %s host key of %q in %s", key.Type(), alias, knownHostsFile)
+
+	return PinHostKey(knownHostsFile, alias, key)
+}
+
+// PinHostKey appends a host key to a known_hosts file.
+func PinHostKey(knownHostsFile, alias string, key ssh.PublicKey) error {
+	f, err := os.OpenFile(knownHostsFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintln(f, knownhosts.Line([]string{alias}, key))
+	return err
+}
+
+// ReplaceHostKey replaces the host keys pinned for an alias with key. The
+// file is replaced at once, so that the previous keys stay pinned if it
+// can't be written.
+func ReplaceHostKey(knownHostsFile, alias string, key ssh.PublicKey) error {
+	content, err := ioutil.ReadFile(knownHostsFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	entry := knownhosts.Normalize(alias)
+	lines := []string{}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if line == "" || isPinnedFor(line, entry) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, knownhosts.Line([]string{alias}, key))
+
+	tmp, err := ioutil.TempFile(filepath.Dir(knownHostsFile), filepath.Base(knownHostsFile)+".")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString(strings.Join(lines, "\n") + "\n")
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), knownHostsFile)
+}
+
+// isPinnedFor returns whether a known_hosts line pins a key for the
+// normalized entry.
+func isPinnedFor(line, entry string) bool {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "@") {
+		return false
+	}
+
+	for _, host := range strings.Split(fields[0], ",") {
+		if host == entry {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PinnedKeyTypes returns the types of the host keys pinned for an alias, so
+// that the same type of key is negotiated on later connections.
+func PinnedKeyTypes(knownHostsFile, alias string) []string {
+	content, err := ioutil.ReadFile(knownHostsFile)
+	if err != nil {
+		return nil
+	}
+
+	entry := knownhosts.Normalize(alias)
+	keyTypes := []string{}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if isPinnedFor(line, entry) {
+			keyTypes = append(keyTypes, strings.Fields(line)[1])
+		}
+	}
+
+	if len(keyTypes) == 0 {
+		return nil
+	}
+
+	return keyTypes
+}
+
+// Fingerprint returns the SHA256 fingerprint of a key as printed by
+// ssh-keygen.
+func Fingerprint(key ssh.PublicKey) string {
+	return ssh.FingerprintSHA256(key)
+}
+
+// ScanHostKey connects to an SSH server and returns its host key without
+// authenticating. The key types to negotiate can be limited, e.g. to the
+// types returned by PinnedKeyTypes.
+func ScanHostKey(host string, port int, keyTypes []string) (ssh.PublicKey, error) {
+	var hostKey ssh.PublicKey
+
+	config := &ssh.ClientConfig{
+		HostKeyCallback: func(_ string, _ net.Addr, key ssh.PublicKey) error {
+			hostKey = key
+			return errHostKeyScanned
+		},
+		HostKeyAlgorithms: keyTypes,
+		Timeout:           10 * time.Second,
+	}
+
+	conn, err := ssh.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(port)), config)
+	if err == nil {
+		closeConn(conn)
+	}
+
+	if hostKey == nil {
+		return nil, fmt.Errorf("Unable to get the host key of %s:%d: %s", host, port, err)
+	}
+
+	return hostKey, nil
+}
+
+func hostKeyCallback(knownHostsFile, alias string) ssh.HostKeyCallback {
+	if knownHostsFile == "" {
+		return ssh.InsecureIgnoreHostKey()
+	}
+
+	return func(hostname string, _ net.Addr, key ssh.PublicKey) error {
+		if alias == "" {
+			return VerifyHostKey(knownHostsFile, hostname, key)
+		}
+
+		return VerifyHostKey(knownHostsFile, alias, key)
+	}
+}
+
+// checkAddress turns an alias, which may lack a port, into the host:port
+// form expected by knownhosts.
+func checkAddress(alias string) string {
+	if _, _, err := net.SplitHostPort(alias); err == nil {
+		return alias
+	}
+
+	return net.JoinHostPort(alias, "22")
+}
@@ -0,0 +1,161 @@
+package ssh
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestHostKey(t *testing.T) ssh.PublicKey {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicKey, err := ssh.NewPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return publicKey
+}
+
+func TestVerifyHostKey(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	knownHostsFile := filepath.Join(tmpDir, "known_hosts")
+	key := newTestHostKey(t)
+
+	// Trusted on first use
+	assert.NoError(t, VerifyHostKey(knownHostsFile, "dev", key))
+	assert.NoError(t, VerifyHostKey(knownHostsFile, "dev", key))
+
+	content, err := ioutil.ReadFile(knownHostsFile)
+	assert.NoError(t, err)
+	assert.Regexp(t, `^dev ecdsa-sha2-nistp256 \S+\n$`, string(content))
+
+	err = VerifyHostKey(knownHostsFile, "dev", newTestHostKey(t))
+	assert.Equal(t, ErrHostKeyMismatch{Alias: "dev", KnownHostsFile: knownHostsFile}, err)
+
+	// Other aliases aren't affected by the pinned key
+	assert.NoError(t, VerifyHostKey(knownHostsFile, "10.0.0.1:2222", newTestHostKey(t)))
+}
+
+func TestReplaceHostKey(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	knownHostsFile := filepath.Join(tmpDir, "known_hosts")
+	jumpHostKey := newTestHostKey(t)
+
+	assert.NoError(t, ReplaceHostKey(knownHostsFile, "dev", newTestHostKey(t)))
+	assert.NoError(t, PinHostKey(knownHostsFile, "bastion:2222", jumpHostKey))
+
+	key := newTestHostKey(t)
+	assert.NoError(t, ReplaceHostKey(knownHostsFile, "dev", key))
+
+	assert.NoError(t, VerifyHostKey(knownHostsFile, "dev", key))
+	assert.NoError(t, VerifyHostKey(knownHostsFile, "bastion:2222", jumpHostKey))
+	assert.Equal(t, []string{"ecdsa-sha2-nistp256"}, PinnedKeyTypes(knownHostsFile, "dev"))
+
+	files, err := ioutil.ReadDir(tmpDir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestHostKeyArgs(t *testing.T) {
+	assert.Equal(t, []string{
+		"-o", "StrictHostKeyChecking=no",
+		"-o", "UserKnownHostsFile=/dev/null",
+	}, HostKeyArgs("", ""))
+
+	assert.Equal(t, []string{
+		"-o", "StrictHostKeyChecking=accept-new",
+		"-o", "UserKnownHostsFile=/store/machines/dev/known_hosts",
+		"-o", "HostKeyAlias=dev",
+	}, hostKeyArgs("/store/machines/dev/known_hosts", "dev", true))
+
+	assert.Equal(t, []string{
+		"-o", "StrictHostKeyChecking=accept-new",
+		"-o", `UserKnownHostsFile="/Users/John Doe/.docker/machine/machines/dev/known_hosts"`,
+		"-o", "HostKeyAlias=dev",
+	}, hostKeyArgs("/Users/John Doe/.docker/machine/machines/dev/known_hosts", "dev", true))
+}
+
+func TestHostKeyArgsWithoutAcceptNew(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "machine-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	knownHostsFile := filepath.Join(tmpDir, "known_hosts")
+
+	// ssh pins the key of a host it doesn't know yet
+	assert.Equal(t, []string{
+		"-o", "StrictHostKeyChecking=no",
+		"-o", "UserKnownHostsFile=" + knownHostsFile,
+		"-o", "HostKeyAlias=dev",
+	}, hostKeyArgs(knownHostsFile, "dev", false))
+
+	assert.NoError(t, PinHostKey(knownHostsFile, "dev", newTestHostKey(t)))
+
+	assert.Equal(t, []string{
+		"-o", "StrictHostKeyChecking=yes",
+		"-o", "UserKnownHostsFile=" + knownHostsFile,
+		"-o", "HostKeyAlias=dev",
+	}, hostKeyArgs(knownHostsFile, "dev", false))
+
+	assert.Equal(t, "StrictHostKeyChecking=no", hostKeyArgs(knownHostsFile, "prod", false)[1])
+	assert.Equal(t, "StrictHostKeyChecking=yes", hostKeyArgs(knownHostsFile, "", false)[1])
+}
+
+func TestOpensshSupportsAcceptNew(t *testing.T) {
+	var tests = []struct {
+		version  string
+		expected bool
+	}{
+		{"OpenSSH_7.2p2 Ubuntu-4ubuntu2.10, OpenSSL 1.0.2g  1 Mar 2016", false},
+		{"OpenSSH_7.4p1, LibreSSL 2.5.0", false},
+		{"OpenSSH_7.6p1 Ubuntu-4ubuntu0.7, OpenSSL 1.0.2n  7 Dec 2017", true},
+		{"OpenSSH_for_Windows_7.7p1, LibreSSL 2.6.5", true},
+		{"OpenSSH_9.2p1 Debian-2+deb12u3, OpenSSL 3.0.13 30 Jan 2024", true},
+		{"ssh: command not found", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, opensshSupportsAcceptNew(test.version), test.version)
+	}
+}
+
+func TestPinnedKeyTypes(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	knownHostsFile := filepath.Join(tmpDir, "known_hosts")
+
+	assert.Nil(t, PinnedKeyTypes(knownHostsFile, "dev"))
+
+	assert.NoError(t, PinHostKey(knownHostsFile, "dev", newTestHostKey(t)))
+	assert.NoError(t, PinHostKey(knownHostsFile, "10.0.0.1:2222", newTestHostKey(t)))
+
+	assert.Equal(t, []string{"ecdsa-sha2-nistp256"}, PinnedKeyTypes(knownHostsFile, "dev"))
+	assert.Equal(t, []string{"ecdsa-sha2-nistp256"}, PinnedKeyTypes(knownHostsFile, "10.0.0.1:2222"))
+	assert.Nil(t, PinnedKeyTypes(knownHostsFile, "prod"))
+}
@@ -0,0 +1,83 @@
+package ssh
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Pipe runs a command on its own connection to the machine. The returned
+// stream reads the output of the command and writes to its input, closing
+// it closes the input and waits for the command to exit.
+func (client *NativeClient) Pipe(command string) (io.ReadWriteCloser, error) {
+	conn, err := client.dial()
+	if err != nil {
+		return nil, fmt.Errorf("Error dialing TCP: %s", err)
+	}
+
+	session, err := conn.NewSession()
+	if err != nil {
+		closeConn(conn)
+		return nil, err
+	}
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		closeConn(conn)
+		return nil, err
+	}
+
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		closeConn(conn)
+		return nil, err
+	}
+
+	stderr := &bytes.Buffer{}
+	session.Stderr = stderr
+
+	if err := session.Start(command); err != nil {
+		session.Close()
+		closeConn(conn)
+		return nil, err
+	}
+
+	return &commandPipe{
+		Reader:      stdout,
+		WriteCloser: stdin,
+		session:     session,
+		conn:        conn,
+		command:     command,
+		stderr:      stderr,
+	}, nil
+}
+
+type commandPipe struct {
+	io.Reader
+	io.WriteCloser
+	session *ssh.Session
+	conn    *ssh.Client
+	command string
+	stderr  *bytes.Buffer
+}
+
+func (p *commandPipe) Close() error {
+	defer closeConn(p.conn)
+	defer p.session.Close()
+
+	p.WriteCloser.Close()
+
+	if err := p.session.Wait(); err != nil {
+		if output := strings.TrimSpace(p.stderr.String()); output != "" {
+			return fmt.Errorf("Error running %q: %s: %s", p.command, err, output)
+		}
+		return fmt.Errorf("Error running %q: %s", p.command, err)
+	}
+
+	return nil
+}
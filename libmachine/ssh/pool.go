@@ -0,0 +1,101 @@
+package ssh
+
+import (
+	"io"
+	"sync"
+
+	"github.com/classmarkets/docker-machine/libmachine/log"
+)
+
+// Pool keeps one client per machine, so that the many commands run while
+// provisioning share a connection instead of each doing an SSH handshake.
+type Pool struct {
+	lock    sync.Mutex
+	clients map[string]pooledClient
+}
+
+type pooledClient struct {
+	client Client
+	target string
+}
+
+func NewPool() *Pool {
+	return &Pool{
+		clients: map[string]pooledClient{},
+	}
+}
+
+// Get returns the client pooled for the machine, newClient creates it if
+// there is none yet. The target identifies where the client connects to, a
+// client pooled for another target, e.g. the former IP of the machine, is
+// closed and replaced.
+func (p *Pool) Get(machineName, target string, newClient func() (Client, error)) (Client, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if pooled, ok := p.clients[machineName]; ok {
+		if pooled.target == target {
+			return pooled.client, nil
+		}
+
+		p.closeClient(machineName)
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	p.clients[machineName] = pooledClient{
+		client: client,
+		target: target,
+	}
+
+	return client, nil
+}
+
+// CloseClient closes the connection shared by the commands run on the
+// machine, e.g. before it's stopped.
+func (p *Pool) CloseClient(machineName string) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.closeClient(machineName)
+}
+
+// Close closes the connections shared by the pooled clients and empties
+// the pool.
+func (p *Pool) Close() error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	var lastErr error
+	for machineName := range p.clients {
+		if err := p.closeClient(machineName); err != nil {
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+func (p *Pool) closeClient(machineName string) error {
+	pooled, ok := p.clients[machineName]
+	if !ok {
+		return nil
+	}
+
+	delete(p.clients, machineName)
+
+	closer, ok := pooled.client.(io.Closer)
+	if !ok {
+		return nil
+	}
+
+	if err := closer.Close(); err != nil {
+		log.Debugf("Error closing SSH connection to %s: %s", machineName, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package ssh
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type closingClient struct {
+	Client
+	closed bool
+}
+
+func (c *closingClient) Close() error {
+	c.closed = true
+	return nil
+}
+
+func newClosingClient() (Client, error) {
+	return &closingClient{}, nil
+}
+
+func TestPoolGet(t *testing.T) {
+	pool := NewPool()
+	created := 0
+	newClient := func() (Client, error) {
+		created++
+		return &closingClient{}, nil
+	}
+
+	first, err := pool.Get("dev", "docker@10.0.0.2:22", newClient)
+	assert.NoError(t, err)
+
+	second, err := pool.Get("dev", "docker@10.0.0.2:22", newClient)
+	assert.NoError(t, err)
+	assert.True(t, first == second)
+
+	_, err = pool.Get("prod", "docker@10.0.0.3:22", newClient)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, created)
+}
+
+func TestPoolGetNewTarget(t *testing.T) {
+	pool := NewPool()
+
+	first, _ := pool.Get("dev", "docker@10.0.0.2:22", newClosingClient)
+	second, _ := pool.Get("dev", "docker@10.0.0.4:22", newClosingClient)
+
+	assert.False(t, first == second)
+	assert.True(t, first.(*closingClient).closed)
+	assert.False(t, second.(*closingClient).closed)
+}
+
+func TestPoolCloseClient(t *testing.T) {
+	pool := NewPool()
+
+	dev, _ := pool.Get("dev", "docker@10.0.0.2:22", newClosingClient)
+	prod, _ := pool.Get("prod", "docker@10.0.0.3:22", newClosingClient)
+
+	assert.NoError(t, pool.CloseClient("dev"))
+	assert.NoError(t, pool.CloseClient("unknown"))
+	assert.True(t, dev.(*closingClient).closed)
+	assert.False(t, prod.(*closingClient).closed)
+}
+
+func TestPoolGetError(t *testing.T) {
+	pool := NewPool()
+	errDial := errors.New("dial failed")
+
+	_, err := pool.Get("dev", "docker@10.0.0.2:22", func() (Client, error) {
+		return nil, errDial
+	})
+	assert.Equal(t, errDial, err)
+
+	client, err := pool.Get("dev", "docker@10.0.0.2:22", newClosingClient)
+	assert.NoError(t, err)
+	assert.NotNil(t, client)
+}
+
+func TestPoolClose(t *testing.T) {
+	pool := NewPool()
+	client, _ := pool.Get("dev", "docker@10.0.0.2:22", newClosingClient)
+
+	assert.NoError(t, pool.Close())
+	assert.True(t, client.(*closingClient).closed)
+
+	reopened, _ := pool.Get("dev", "docker@10.0.0.2:22", newClosingClient)
+	assert.False(t, reopened == client)
+}
+
+func TestClientsAreClosers(t *testing.T) {
+	assert.Implements(t, (*io.Closer)(nil), &NativeClient{})
+	assert.Implements(t, (*io.Closer)(nil), &ExternalClient{})
+}
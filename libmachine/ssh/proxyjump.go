@@ -0,0 +1,161 @@
+package ssh
+
+import (
+	"fmt"
+	"net"
+	"os/user"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ProxyJump describes the jump host through which a machine on a private
+// network is reached.
+type ProxyJump struct {
+	// Address is the jump host as [user@]host[:port]
+	Address string
+
+	// KeyPath is the private key to authenticate with on the jump host,
+	// the keys of the machine are used if it's empty
+	KeyPath string
+}
+
+// ParseProxyJump splits the address of a jump host into its user, host and
+// port. The user is empty if the address doesn't contain one.
+func ParseProxyJump(address string) (string, string, int, error) {
+	username := ""
+	if parts := strings.SplitN(address, "@", 2); len(parts) == 2 {
+		username, address = parts[0], parts[1]
+	}
+
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		host, portStr = strings.Trim(address, "[]"), "22"
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 {
+		return "", "", 0, fmt.Errorf("Invalid port in jump host %q", address)
+	}
+
+	if host == "" || strings.ContainsAny(host, " /") {
+		return "", "", 0, fmt.Errorf("Invalid jump host %q, expected [user@]host[:port]", address)
+	}
+
+	return username, host, port, nil
+}
+
+// ProxyJumpArgs returns the options making an external ssh binary connect
+// through the jump host.
+func ProxyJumpArgs(sshBinaryPath string, p ProxyJump) ([]string, error) {
+	if p.Address == "" {
+		return nil, nil
+	}
+
+	username, host, port, err := ParseProxyJump(p.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.KeyPath == "" {
+		return []string{"-o", fmt.Sprintf("ProxyJump=%s", p.Address)}, nil
+	}
+
+	// ProxyJump can't be given a key, so ssh is spawned explicitly
+	destination := host
+	if username != "" {
+		destination = fmt.Sprintf("%s@%s", username, host)
+	}
+
+	return []string{
+		"-o", fmt.Sprintf("ProxyCommand=%s -i %s -o IdentitiesOnly=yes -p %d -W %%h:%%p %s", proxyCommandWord(sshBinaryPath), proxyCommandWord(p.KeyPath), port, destination),
+	}, nil
+}
+
+var safeProxyCommandWord = regexp.MustCompile(`^[A-Za-z0-9_@+=:,./-]+$`)
+
+// proxyCommandWord quotes a word of a ProxyCommand for the shell ssh runs
+// it with, and escapes the % which ssh expands as a token.
+func proxyCommandWord(word string) string {
+	if !safeProxyCommandWord.MatchString(word) {
+		word = "'" + strings.Replace(word, "'", `'\''`, -1) + "'"
+	}
+	return strings.Replace(word, "%", "%%", -1)
+}
+
+// DialJumpHost connects to the jump host of auth. The host key of the jump
+// host is pinned in the known_hosts file of the machine.
+func DialJumpHost(auth *Auth) (*ssh.Client, error) {
+	username, host, port, err := ParseProxyJump(auth.ProxyJump.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	if username == "" {
+		username = localUsername()
+	}
+
+	jumpAuth := &Auth{
+		Keys:           auth.Keys,
+		KnownHostsFile: auth.KnownHostsFile,
+	}
+	if auth.ProxyJump.KeyPath != "" {
+		jumpAuth.Keys = []string{auth.ProxyJump.KeyPath}
+	}
+
+	config, err := NewNativeConfig(username, jumpAuth)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting config for jump host: %s", err)
+	}
+
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+
+	client, err := ssh.Dial("tcp", address, &config)
+	if err != nil {
+		return nil, fmt.Errorf("Error dialing jump host %s: %s", address, err)
+	}
+
+	return client, nil
+}
+
+// dialThroughJumpHost opens an SSH connection to addr tunneled through the
+// jump host. The connection to the jump host is closed with the returned
+// client.
+func dialThroughJumpHost(auth *Auth, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	jumpClient, err := DialJumpHost(auth)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := jumpClient.Dial("tcp", addr)
+	if err != nil {
+		closeConn(jumpClient)
+		return nil, fmt.Errorf("Error dialing %s through jump host: %s", addr, err)
+	}
+
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		closeConn(jumpClient)
+		return nil, err
+	}
+
+	client := ssh.NewClient(c, chans, reqs)
+
+	go func() {
+		client.Wait()
+		closeConn(jumpClient)
+	}()
+
+	return client, nil
+}
+
+func localUsername() string {
+	u, err := user.Current()
+	if err != nil {
+		return "root"
+	}
+
+	return u.Username
+}
@@ -0,0 +1,83 @@
+package ssh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProxyJump(t *testing.T) {
+	cases := []struct {
+		address  string
+		user     string
+		host     string
+		port     int
+		hasError bool
+	}{
+		{"bastion", "", "bastion", 22, false},
+		{"jump@bastion", "jump", "bastion", 22, false},
+		{"jump@bastion:2222", "jump", "bastion", 2222, false},
+		{"10.0.0.1:2222", "", "10.0.0.1", 2222, false},
+		{"[fe80::1]:2222", "", "fe80::1", 2222, false},
+		{"jump@bastion:port", "", "", 0, true},
+		{"jump@:2222", "", "", 0, true},
+		{"", "", "", 0, true},
+	}
+
+	for _, c := range cases {
+		user, host, port, err := ParseProxyJump(c.address)
+		assert.Equal(t, c.user, user, c.address)
+		assert.Equal(t, c.host, host, c.address)
+		assert.Equal(t, c.port, port, c.address)
+		assert.Equal(t, c.hasError, err != nil, c.address)
+	}
+}
+
+func TestProxyJumpArgs(t *testing.T) {
+	args, err := ProxyJumpArgs("ssh", ProxyJump{})
+	assert.NoError(t, err)
+	assert.Empty(t, args)
+
+	args, err = ProxyJumpArgs("ssh", ProxyJump{Address: "jump@bastion"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"-o", "ProxyJump=jump@bastion"}, args)
+
+	args, err = ProxyJumpArgs("/usr/bin/ssh", ProxyJump{Address: "bastion", KeyPath: "/keys/bastion"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"-o", "ProxyCommand=/usr/bin/ssh -i /keys/bastion -o IdentitiesOnly=yes -p 22 -W %h:%p bastion"}, args)
+
+	args, err = ProxyJumpArgs("/opt/my ssh/ssh", ProxyJump{Address: "bastion", KeyPath: "/keys/100%/it's"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"-o", `ProxyCommand='/opt/my ssh/ssh' -i '/keys/100%%/it'\''s' -o IdentitiesOnly=yes -p 22 -W %h:%p bastion`}, args)
+
+	_, err = ProxyJumpArgs("ssh", ProxyJump{Address: "bastion:0"})
+	assert.Error(t, err)
+}
+
+func TestNewExternalClientWithProxyJump(t *testing.T) {
+	client, err := NewExternalClient("ssh", "docker", "10.0.0.2", 22, &Auth{
+		ProxyJump: ProxyJump{Address: "jump@bastion:2222"},
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, client.BaseArgs, "ProxyJump=jump@bastion:2222")
+
+	_, err = NewExternalClient("ssh", "docker", "10.0.0.2", 22, &Auth{
+		ProxyJump: ProxyJump{Address: "jump@bastion:port"},
+	})
+	assert.Error(t, err)
+}
+
+func TestNewNativeClientWithProxyJump(t *testing.T) {
+	auth := &Auth{ProxyJump: ProxyJump{Address: "jump@bastion"}}
+
+	client, err := NewNativeClient("docker", "10.0.0.2", 22, auth)
+	assert.NoError(t, err)
+	assert.Equal(t, auth, client.(*NativeClient).jumpAuth)
+
+	client, err = NewNativeClient("docker", "10.0.0.2", 22, &Auth{})
+	assert.NoError(t, err)
+	assert.Nil(t, client.(*NativeClient).jumpAuth)
+
+	_, err = NewNativeClient("docker", "10.0.0.2", 22, &Auth{ProxyJump: ProxyJump{Address: "jump@:22"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,183 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/classmarkets/docker-machine/libmachine/log"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrSFTPUnavailable is returned when the SSH server of a machine doesn't
+// offer the SFTP subsystem.
+var ErrSFTPUnavailable = errors.New("The SSH server does not support SFTP")
+
+// SFTPClient transfers files over its own connection to a machine.
+type SFTPClient struct {
+	*sftp.Client
+
+	session *ssh.Session
+	conn    *ssh.Client
+}
+
+// NewSFTPClient opens a connection to the machine and starts an SFTP
+// session on it.
+func (client *NativeClient) NewSFTPClient() (*SFTPClient, error) {
+	conn, err := client.dial()
+	if err != nil {
+		return nil, fmt.Errorf("Error dialing TCP for SFTP: %s", err)
+	}
+
+	session, err := conn.NewSession()
+	if err != nil {
+		closeConn(conn)
+		return nil, err
+	}
+
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		log.Debugf("Error requesting SFTP subsystem: %s", err)
+		session.Close()
+		closeConn(conn)
+		return nil, ErrSFTPUnavailable
+	}
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		closeConn(conn)
+		return nil, err
+	}
+
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		closeConn(conn)
+		return nil, err
+	}
+
+	sftpClient, err := sftp.NewClientPipe(stdout, stdin)
+	if err != nil {
+		session.Close()
+		closeConn(conn)
+		return nil, err
+	}
+
+	return &SFTPClient{
+		Client:  sftpClient,
+		session: session,
+		conn:    conn,
+	}, nil
+}
+
+// Close ends the SFTP session and closes its connection.
+func (c *SFTPClient) Close() error {
+	err := c.Client.Close()
+	c.session.Close()
+	closeConn(c.conn)
+	return err
+}
+
+// CommandReader runs a command on the connection of the SFTP session and
+// returns its output. Closing the reader waits for the command to exit.
+func (c *SFTPClient) CommandReader(command string) (io.ReadCloser, error) {
+	session, err := c.conn.NewSession()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	if err := session.Start(command); err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	return &commandReader{
+		Reader:  stdout,
+		session: session,
+		command: command,
+	}, nil
+}
+
+// CommandWriter runs a command on the connection of the SFTP session, which
+// reads its input from the returned writer. Closing the writer waits for
+// the command to exit.
+func (c *SFTPClient) CommandWriter(command string) (io.WriteCloser, error) {
+	session, err := c.conn.NewSession()
+	if err != nil {
+		return nil, err
+	}
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	if err := session.Start(command); err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	return &commandWriter{
+		WriteCloser: stdin,
+		session:     session,
+		command:     command,
+	}, nil
+}
+
+type commandReader struct {
+	io.Reader
+	session *ssh.Session
+	command string
+	eof     bool
+}
+
+func (r *commandReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err == io.EOF {
+		r.eof = true
+	}
+	return n, err
+}
+
+func (r *commandReader) Close() error {
+	defer r.session.Close()
+
+	// The command is abandoned if its output wasn't read until the end
+	if !r.eof {
+		return nil
+	}
+
+	if err := r.session.Wait(); err != nil {
+		return fmt.Errorf("Error running %q: %s", r.command, err)
+	}
+
+	return nil
+}
+
+type commandWriter struct {
+	io.WriteCloser
+	session *ssh.Session
+	command string
+}
+
+func (w *commandWriter) Close() error {
+	defer w.session.Close()
+
+	if err := w.WriteCloser.Close(); err != nil {
+		return err
+	}
+
+	if err := w.session.Wait(); err != nil {
+		return fmt.Errorf("Error running %q: %s", w.command, err)
+	}
+
+	return nil
+}
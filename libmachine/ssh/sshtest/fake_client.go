@@ -10,6 +10,7 @@ type CmdResult struct {
 type FakeClient struct {
 	ActivatedShell []string
 	Outputs        map[string]CmdResult
+	ForwardedAgent bool
 }
 
 func (fsc *FakeClient) Output(command string) (string, error) {
@@ -22,6 +23,11 @@ func (fsc *FakeClient) Shell(args ...string) error {
 	return nil
 }
 
+func (fsc *FakeClient) ForwardAgent() error {
+	fsc.ForwardedAgent = true
+	return nil
+}
+
 func (fsc *FakeClient) Start(command string) (io.ReadCloser, io.ReadCloser, error) {
 	return nil, nil, nil
 }